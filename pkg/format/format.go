@@ -0,0 +1,86 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package format is the registry of the output formats of the goyang
+// command.  A format is added by calling Register, normally from the init
+// function of the package implementing the format, so importing that package
+// (e.g., with a blank import) is enough to make the format available:
+//
+//	import _ "example.com/myformat"
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+// A Formatter describes an output format.
+type Formatter struct {
+	// Name is the name of the format as given to --format.
+	Name string
+	// Help is a one line description of the format.
+	Help string
+	// Flags are the options specific to this format, if any.  They are
+	// only accepted on the command line following --format.
+	Flags *getopt.Set
+	// Format is called once with the Entry trees of the modules to
+	// display, writing its output to w.
+	Format func(w io.Writer, entries []*yang.Entry)
+}
+
+var (
+	mu         sync.Mutex
+	formatters = map[string]*Formatter{}
+)
+
+// Register makes f available under the name f.Name.  Register panics if f
+// has no name or Format function, or if a format with the same name has
+// already been registered.
+func Register(f *Formatter) {
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case f == nil || f.Name == "":
+		panic("format: Register called without a format name")
+	case f.Format == nil:
+		panic(fmt.Sprintf("format: Register of %s called without a Format function", f.Name))
+	case formatters[f.Name] != nil:
+		panic(fmt.Sprintf("format: Register called twice for %s", f.Name))
+	}
+	formatters[f.Name] = f
+}
+
+// Lookup returns the format registered as name, or nil if there is none.
+func Lookup(name string) *Formatter {
+	mu.Lock()
+	defer mu.Unlock()
+	return formatters[name]
+}
+
+// Names returns the sorted names of all registered formats.
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func nop(io.Writer, []*yang.Entry) {}
+
+func TestRegister(t *testing.T) {
+	defer func(saved map[string]*Formatter) { formatters = saved }(formatters)
+	formatters = map[string]*Formatter{}
+
+	b := &Formatter{Name: "bravo", Format: nop}
+	a := &Formatter{Name: "alpha", Help: "the first", Format: nop}
+	Register(b)
+	Register(a)
+
+	if got := Lookup("alpha"); got != a {
+		t.Errorf("Lookup(alpha): got %v, want %v", got, a)
+	}
+	if got := Lookup("charlie"); got != nil {
+		t.Errorf("Lookup(charlie): got %v, want nil", got)
+	}
+	if diff := cmp.Diff([]string{"alpha", "bravo"}, Names()); diff != "" {
+		t.Errorf("Names (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		desc      string
+		in        *Formatter
+		wantPanic string
+	}{{
+		desc:      "duplicate name",
+		in:        &Formatter{Name: "alpha", Format: nop},
+		wantPanic: "called twice for alpha",
+	}, {
+		desc:      "no name",
+		in:        &Formatter{Format: nop},
+		wantPanic: "without a format name",
+	}, {
+		desc:      "no format function",
+		in:        &Formatter{Name: "delta"},
+		wantPanic: "without a Format function",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if s, _ := r.(string); !strings.Contains(s, tt.wantPanic) {
+					t.Errorf("Register: got panic %v, want panic containing %q", r, tt.wantPanic)
+				}
+			}()
+			Register(tt.in)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The defaults format writes the configuration that is in effect when none
+// has been set, as computed by Entry.DefaultConfig, as a single RFC7951 JSON
+// document covering all the modules.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "defaults",
+		Format: doDefaults,
+		Help:   "display the default configuration as RFC7951 JSON",
+	})
+}
+
+func doDefaults(w io.Writer, entries []*yang.Entry) {
+	config := map[string]interface{}{}
+	for _, e := range entries {
+		for k, v := range e.DefaultConfig() {
+			config[k] = v
+		}
+	}
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop(1)
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
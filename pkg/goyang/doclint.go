@@ -0,0 +1,135 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The doc-lint format reports the nodes of the modules that have no
+// description statement, one per line with the location of the node:
+//
+//	example.yang:12:5: leaf /example/config/name has no description
+//
+// The kinds of nodes checked are given by --doc-lint_kinds, which defaults to
+// containers, lists, leaves and leaf-lists.  Only configuration is checked
+// unless --doc-lint_all is given, which also checks state data, operations and
+// notifications; the rpc, action and notification kinds only apply with
+// --doc-lint_all.  A statement in a grouping that is used several times is
+// reported once, and the implicit case of a choice's shorthand case statement
+// is never reported.  goyang exits with status 1 if any node is reported, so
+// the format can be used to enforce descriptions in CI.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var (
+	docLintKinds = []string{"container", "list", "leaf", "leaf-list"}
+	docLintAll   bool
+)
+
+// docLintKnownKinds are the kinds of node that may be given to
+// --doc-lint_kinds.
+var docLintKnownKinds = map[string]bool{
+	"container":    true,
+	"list":         true,
+	"leaf":         true,
+	"leaf-list":    true,
+	"choice":       true,
+	"case":         true,
+	"anydata":      true,
+	"anyxml":       true,
+	"rpc":          true,
+	"action":       true,
+	"notification": true,
+}
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "doc-lint",
+		Format: doDocLint,
+		Help:   "report the nodes without a description",
+		Flags:  flags,
+	})
+	flags.ListVarLong(&docLintKinds, "doc-lint_kinds", 0, "comma separated kinds of node to check, defaults to container,list,leaf,leaf-list", "KIND[,KIND...]")
+	flags.BoolVarLong(&docLintAll, "doc-lint_all", 0, "also check state data, operations and notifications")
+}
+
+func doDocLint(w io.Writer, entries []*yang.Entry) {
+	kinds := map[string]bool{}
+	for _, k := range docLintKinds {
+		if !docLintKnownKinds[k] {
+			var known []string
+			for k := range docLintKnownKinds {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			fmt.Fprintf(os.Stderr, "doc-lint: unknown kind %q, want one of %s\n", k, strings.Join(known, ", "))
+			stop(1)
+		}
+		kinds[k] = true
+	}
+	reported := map[yang.Node]bool{}
+	n := 0
+	for _, e := range entries {
+		n += writeDocLint(w, kinds, reported, e)
+	}
+	if n > 0 {
+		stop(1)
+	}
+}
+
+// writeDocLint writes a line to w for each node beneath e of one of kinds
+// that has no description and is not in reported, adding it to reported.  It
+// returns the number of lines written.
+func writeDocLint(w io.Writer, kinds map[string]bool, reported map[yang.Node]bool, e *yang.Entry) int {
+	n := 0
+	for _, c := range sortedChildren(e) {
+		if !docLintAll && (c.ReadOnly() || c.RPC != nil || c.Kind == yang.NotificationEntry) {
+			continue
+		}
+		if c.Node != nil && c.Description == "" && !reported[c.Node] {
+			kind := c.Node.Kind()
+			if c.IsLeafList() {
+				// A leaf-list entry is built from an equivalent
+				// Leaf node.
+				kind = "leaf-list"
+			}
+			// The implicit case of a shorthand case statement is
+			// derived from the statement of its only child.
+			implicit := c.IsCase() && c.Node.Statement() != nil && c.Node.Statement().Keyword != "case"
+			if kinds[kind] && !implicit {
+				reported[c.Node] = true
+				fmt.Fprintf(w, "%s: %s %s has no description\n", yang.Source(c.Node), kind, c.Path())
+				n++
+			}
+		}
+		n += writeDocLint(w, kinds, reported, c)
+		if c.RPC != nil {
+			for _, op := range []*yang.Entry{c.RPC.Input, c.RPC.Output} {
+				if op != nil {
+					n += writeDocLint(w, kinds, reported, op)
+				}
+			}
+		}
+	}
+	return n
+}
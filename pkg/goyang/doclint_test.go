@@ -0,0 +1,71 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestWriteDocLint(t *testing.T) {
+	ms := yang.NewModules()
+	if err := ms.Parse(`module m {
+  prefix "m";
+  namespace "urn:m";
+
+  container c {
+    description "documented";
+    leaf l { type string; }
+    leaf-list ll { type string; }
+    list li {
+      key "k";
+      leaf k { type string; }
+    }
+  }
+}`, "m.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := yang.ToEntry(ms.Modules["m"])
+
+	for _, tt := range []struct {
+		kinds []string
+		want  string
+	}{{
+		kinds: []string{"leaf"},
+		want: "m.yang:7:5: leaf /m/c/l has no description\n" +
+			"m.yang:11:7: leaf /m/c/li/k has no description\n",
+	}, {
+		kinds: []string{"leaf-list"},
+		want:  "m.yang:8:5: leaf-list /m/c/ll has no description\n",
+	}, {
+		kinds: []string{"container", "list"},
+		want:  "m.yang:9:5: list /m/c/li has no description\n",
+	}} {
+		kinds := map[string]bool{}
+		for _, k := range tt.kinds {
+			kinds[k] = true
+		}
+		var buf bytes.Buffer
+		writeDocLint(&buf, kinds, map[yang.Node]bool{}, e)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("writeDocLint with kinds %v: got:\n%s\nwant:\n%s", tt.kinds, got, tt.want)
+		}
+	}
+}
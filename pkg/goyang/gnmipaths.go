@@ -0,0 +1,112 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The gnmi-paths format writes the paths of the leaves and leaf-lists of the
+// data trees of the modules, one per line, as tab separated columns for use
+// in building gNMI subscriptions:
+//
+//	path	origin	config	key
+//	/interfaces/interface[name=*]/name	openconfig	config	true
+//	/interfaces/interface[name=*]/state/counters/in-octets	openconfig	state	false
+//
+// The first line names the columns.  Paths are gNMI paths without prefixes,
+// with a wildcard for each key of each list, and with choice and case nodes
+// omitted.  The config column is either config or state, and the key column
+// is true for the key leaves of lists.  Operations and notifications are not
+// part of the data tree and are omitted.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var (
+	gnmiPathsOrigin = "openconfig"
+	gnmiPathsFilter string
+)
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "gnmi-paths",
+		Format: doGNMIPaths,
+		Help:   "display the data tree leaf paths annotated for gNMI",
+		Flags:  flags,
+	})
+	flags.StringVarLong(&gnmiPathsOrigin, "gnmi-paths_origin", 0, "the gNMI origin of the paths", "ORIGIN")
+	flags.StringVarLong(&gnmiPathsFilter, "gnmi-paths_filter", 0, "only display config or state paths", "config|state")
+}
+
+func doGNMIPaths(w io.Writer, entries []*yang.Entry) {
+	switch gnmiPathsFilter {
+	case "", "config", "state":
+	default:
+		fmt.Fprintf(os.Stderr, "gnmi-paths: invalid filter %q, want config or state\n", gnmiPathsFilter)
+		stop(1)
+	}
+	fmt.Fprintln(w, "path\torigin\tconfig\tkey")
+	for _, e := range entries {
+		writeGNMIPaths(w, "", e)
+	}
+}
+
+// writeGNMIPaths writes a line for each leaf and leaf-list beneath e to w.
+// path is the gNMI path of e.
+func writeGNMIPaths(w io.Writer, path string, e *yang.Entry) {
+	for _, c := range sortedChildren(e) {
+		switch {
+		case c.RPC != nil, c.Kind == yang.NotificationEntry:
+		case c.IsChoice(), c.IsCase():
+			writeGNMIPaths(w, path, c)
+		case c.IsLeaf(), c.IsLeafList():
+			class := "config"
+			if c.ReadOnly() {
+				class = "state"
+			}
+			if gnmiPathsFilter != "" && gnmiPathsFilter != class {
+				continue
+			}
+			fmt.Fprintf(w, "%s/%s\t%s\t%s\t%t\n", path, c.Name, gnmiPathsOrigin, class, isListKey(c))
+		default:
+			elem := c.Name
+			if c.IsList() {
+				for _, k := range strings.Fields(c.Key) {
+					elem += "[" + k + "=*]"
+				}
+			}
+			writeGNMIPaths(w, path+"/"+elem, c)
+		}
+	}
+}
+
+// isListKey reports whether e is a key leaf of its parent list.
+func isListKey(e *yang.Entry) bool {
+	if e.Parent == nil || !e.Parent.IsList() {
+		return false
+	}
+	for _, k := range strings.Fields(e.Parent.Key) {
+		if k == e.Name {
+			return true
+		}
+	}
+	return false
+}
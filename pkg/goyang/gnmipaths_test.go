@@ -0,0 +1,73 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGNMIPaths(t *testing.T) {
+	entries := testEntries(t, "example.yang")
+	defer func(origin, filter string) {
+		gnmiPathsOrigin, gnmiPathsFilter = origin, filter
+	}(gnmiPathsOrigin, gnmiPathsFilter)
+
+	for _, tt := range []struct {
+		origin string
+		filter string
+		want   string
+	}{{
+		origin: "openconfig",
+		want: "path\torigin\tconfig\tkey\n" +
+			"/system/dns\topenconfig\tconfig\tfalse\n" +
+			"/system/hostname\topenconfig\tconfig\tfalse\n" +
+			"/system/primary\topenconfig\tconfig\tfalse\n" +
+			"/system/server[name=*]/mode\topenconfig\tconfig\tfalse\n" +
+			"/system/server[name=*]/name\topenconfig\tconfig\ttrue\n" +
+			"/system/server[name=*]/peer[address=*]/address\topenconfig\tconfig\ttrue\n" +
+			"/system/server[name=*]/peer[address=*]/port\topenconfig\tconfig\tfalse\n" +
+			"/system/server[name=*]/weight\topenconfig\tconfig\tfalse\n" +
+			"/system/state/uptime\topenconfig\tstate\tfalse\n" +
+			"/system/tcp-port\topenconfig\tconfig\tfalse\n" +
+			"/system/udp-port\topenconfig\tconfig\tfalse\n",
+	}, {
+		origin: "ex",
+		filter: "state",
+		want: "path\torigin\tconfig\tkey\n" +
+			"/system/state/uptime\tex\tstate\tfalse\n",
+	}, {
+		origin: "ex",
+		filter: "config",
+		want: "path\torigin\tconfig\tkey\n" +
+			"/system/dns\tex\tconfig\tfalse\n" +
+			"/system/hostname\tex\tconfig\tfalse\n" +
+			"/system/primary\tex\tconfig\tfalse\n" +
+			"/system/server[name=*]/mode\tex\tconfig\tfalse\n" +
+			"/system/server[name=*]/name\tex\tconfig\ttrue\n" +
+			"/system/server[name=*]/peer[address=*]/address\tex\tconfig\ttrue\n" +
+			"/system/server[name=*]/peer[address=*]/port\tex\tconfig\tfalse\n" +
+			"/system/server[name=*]/weight\tex\tconfig\tfalse\n" +
+			"/system/tcp-port\tex\tconfig\tfalse\n" +
+			"/system/udp-port\tex\tconfig\tfalse\n",
+	}} {
+		gnmiPathsOrigin, gnmiPathsFilter = tt.origin, tt.filter
+		var buf bytes.Buffer
+		doGNMIPaths(&buf, entries)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("doGNMIPaths with origin %q and filter %q: got:\n%s\nwant:\n%s", tt.origin, tt.filter, got, tt.want)
+		}
+	}
+}
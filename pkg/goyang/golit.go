@@ -0,0 +1,309 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The golit format writes the Entry trees of the modules as a Go source file
+// declaring them as composite literals, e.g., to hold golden schemas in tests:
+//
+//	var Entries = []*yang.Entry{
+//		&yang.Entry{
+//			Name: "example",
+//			Kind: yang.DirectoryEntry,
+//			Dir: map[string]*yang.Entry{
+//				"name": &yang.Entry{
+//					Name: "name",
+//					Kind: yang.LeafEntry,
+//					Type: &yang.YangType{Name: "string", Kind: yang.Ystring},
+//				},
+//			},
+//		},
+//	}
+//
+// The Parent of each Entry, which would make the literals cyclic, is set by
+// an init function of the file.  The statements the Entries and types were
+// derived from, their extensions, augments, deviations and the identities
+// are not written.  An enumeration or bits type is written as a call of a
+// function literal that builds it with Set.
+
+import (
+	"bytes"
+	"fmt"
+	gofmt "go/format"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var (
+	golitPackage = "schema"
+	golitVar     = "Entries"
+)
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "golit",
+		Format: doGoLit,
+		Help:   "display the Entry trees as Go composite literals",
+		Flags:  flags,
+	})
+	flags.StringVarLong(&golitPackage, "golit_package", 0, "package of the Go file, defaults to schema", "PACKAGE")
+	flags.StringVarLong(&golitVar, "golit_var", 0, "variable holding the Entry trees, defaults to Entries", "NAME")
+}
+
+// golitTypeKinds are the names of the TypeKind constants.
+var golitTypeKinds = map[yang.TypeKind]string{
+	yang.Ynone:               "Ynone",
+	yang.Yint8:               "Yint8",
+	yang.Yint16:              "Yint16",
+	yang.Yint32:              "Yint32",
+	yang.Yint64:              "Yint64",
+	yang.Yuint8:              "Yuint8",
+	yang.Yuint16:             "Yuint16",
+	yang.Yuint32:             "Yuint32",
+	yang.Yuint64:             "Yuint64",
+	yang.Ybinary:             "Ybinary",
+	yang.Ybits:               "Ybits",
+	yang.Ybool:               "Ybool",
+	yang.Ydecimal64:          "Ydecimal64",
+	yang.Yempty:              "Yempty",
+	yang.Yenum:               "Yenum",
+	yang.Yidentityref:        "Yidentityref",
+	yang.YinstanceIdentifier: "YinstanceIdentifier",
+	yang.Yleafref:            "Yleafref",
+	yang.Ystring:             "Ystring",
+	yang.Yunion:              "Yunion",
+}
+
+// golitTriStates are the names of the TriState constants.
+var golitTriStates = map[yang.TriState]string{
+	yang.TSUnset: "TSUnset",
+	yang.TSTrue:  "TSTrue",
+	yang.TSFalse: "TSFalse",
+}
+
+func doGoLit(w io.Writer, entries []*yang.Entry) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by goyang --format golit. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", golitPackage)
+	fmt.Fprintf(&b, "import %q\n\n", "github.com/openconfig/goyang/pkg/yang")
+	fmt.Fprintf(&b, "// %s are the Entry trees of the modules.\n", golitVar)
+	fmt.Fprintf(&b, "var %s = []*yang.Entry{\n", golitVar)
+	for _, e := range entries {
+		writeEntryLit(&b, e)
+		b.WriteString(",\n")
+	}
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, `func init() {
+	var setParents func(e *yang.Entry)
+	setParents = func(e *yang.Entry) {
+		for _, c := range e.Dir {
+			c.Parent = e
+			setParents(c)
+		}
+		if e.RPC != nil {
+			for _, c := range []*yang.Entry{e.RPC.Input, e.RPC.Output} {
+				if c != nil {
+					c.Parent = e
+					setParents(c)
+				}
+			}
+		}
+	}
+	for _, e := range %s {
+		setParents(e)
+	}
+}
+`, golitVar)
+
+	src, err := gofmt.Source(b.Bytes())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "golit: %v\n", err)
+		stop(1)
+	}
+	w.Write(src)
+}
+
+// writeEntryLit writes the literal of e, without a trailing comma, to b.
+func writeEntryLit(b *bytes.Buffer, e *yang.Entry) {
+	b.WriteString("&yang.Entry{\n")
+	fmt.Fprintf(b, "Name: %q,\n", e.Name)
+	if e.Description != "" {
+		fmt.Fprintf(b, "Description: %q,\n", e.Description)
+	}
+	if len(e.Default) > 0 {
+		fmt.Fprintf(b, "Default: %s,\n", stringsLit(e.Default))
+	}
+	if e.Units != "" {
+		fmt.Fprintf(b, "Units: %q,\n", e.Units)
+	}
+	fmt.Fprintf(b, "Kind: yang.%sEntry,\n", yang.EntryKindToName[e.Kind])
+	if e.Config != yang.TSUnset {
+		fmt.Fprintf(b, "Config: yang.%s,\n", golitTriStates[e.Config])
+	}
+	if e.Prefix != nil {
+		fmt.Fprintf(b, "Prefix: &yang.Value{Name: %q},\n", e.Prefix.Name)
+	}
+	if e.Mandatory != yang.TSUnset {
+		fmt.Fprintf(b, "Mandatory: yang.%s,\n", golitTriStates[e.Mandatory])
+	}
+	if len(e.Dir) > 0 {
+		b.WriteString("Dir: map[string]*yang.Entry{\n")
+		for _, c := range sortedChildren(e) {
+			fmt.Fprintf(b, "%q: ", c.Name)
+			writeEntryLit(b, c)
+			b.WriteString(",\n")
+		}
+		b.WriteString("},\n")
+	}
+	if e.Key != "" {
+		fmt.Fprintf(b, "Key: %q,\n", e.Key)
+	}
+	if e.Type != nil {
+		b.WriteString("Type: ")
+		writeTypeLit(b, e.Type)
+		b.WriteString(",\n")
+	}
+	if la := e.ListAttr; la != nil {
+		fmt.Fprintf(b, "ListAttr: &yang.ListAttr{MinElements: %d, MaxElements: %d", la.MinElements, la.MaxElements)
+		if la.OrderedBy != nil {
+			fmt.Fprintf(b, ", OrderedBy: &yang.Value{Name: %q}", la.OrderedBy.Name)
+		}
+		b.WriteString("},\n")
+	}
+	if e.RPC != nil {
+		b.WriteString("RPC: &yang.RPCEntry{\n")
+		if e.RPC.Input != nil {
+			b.WriteString("Input: ")
+			writeEntryLit(b, e.RPC.Input)
+			b.WriteString(",\n")
+		}
+		if e.RPC.Output != nil {
+			b.WriteString("Output: ")
+			writeEntryLit(b, e.RPC.Output)
+			b.WriteString(",\n")
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+}
+
+// writeTypeLit writes the literal of t, without a trailing comma, to b.
+func writeTypeLit(b *bytes.Buffer, t *yang.YangType) {
+	b.WriteString("&yang.YangType{\n")
+	fmt.Fprintf(b, "Name: %q,\n", t.Name)
+	fmt.Fprintf(b, "Kind: yang.%s,\n", golitTypeKinds[t.Kind])
+	if t.Bit != nil {
+		fmt.Fprintf(b, "Bit: %s,\n", enumLit("NewBitfield", t.Bit))
+	}
+	if t.Enum != nil {
+		fmt.Fprintf(b, "Enum: %s,\n", enumLit("NewEnumType", t.Enum))
+	}
+	if t.Units != "" {
+		fmt.Fprintf(b, "Units: %q,\n", t.Units)
+	}
+	if t.HasDefault {
+		fmt.Fprintf(b, "Default: %q,\nHasDefault: true,\n", t.Default)
+	}
+	if t.FractionDigits != 0 {
+		fmt.Fprintf(b, "FractionDigits: %d,\n", t.FractionDigits)
+	}
+	if len(t.Length) > 0 {
+		fmt.Fprintf(b, "Length: %s,\n", rangeLit(t.Length))
+	}
+	if t.OptionalInstance {
+		b.WriteString("OptionalInstance: true,\n")
+	}
+	if t.Path != "" {
+		fmt.Fprintf(b, "Path: %q,\n", t.Path)
+	}
+	if len(t.Pattern) > 0 {
+		b.WriteString("Pattern: []*yang.YangPattern{\n")
+		for _, p := range t.Pattern {
+			fmt.Fprintf(b, "{Expr: %q", p.Expr)
+			if p.InvertMatch {
+				b.WriteString(", InvertMatch: true")
+			}
+			if p.ErrorMessage != "" {
+				fmt.Fprintf(b, ", ErrorMessage: %q", p.ErrorMessage)
+			}
+			if p.ErrorAppTag != "" {
+				fmt.Fprintf(b, ", ErrorAppTag: %q", p.ErrorAppTag)
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("},\n")
+	}
+	if len(t.POSIXPattern) > 0 {
+		fmt.Fprintf(b, "POSIXPattern: %s,\n", stringsLit(t.POSIXPattern))
+	}
+	if len(t.Range) > 0 {
+		fmt.Fprintf(b, "Range: %s,\n", rangeLit(t.Range))
+	}
+	if len(t.Type) > 0 {
+		b.WriteString("Type: []*yang.YangType{\n")
+		for _, ut := range t.Type {
+			writeTypeLit(b, ut)
+			b.WriteString(",\n")
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+}
+
+// stringsLit returns the literal of the []string ss.
+func stringsLit(ss []string) string {
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return "[]string{" + strings.Join(q, ", ") + "}"
+}
+
+// rangeLit returns the literal of the YangRange r.
+func rangeLit(r yang.YangRange) string {
+	number := func(n yang.Number) string {
+		s := fmt.Sprintf("yang.Number{Value: %d", n.Value)
+		if n.FractionDigits != 0 {
+			s += fmt.Sprintf(", FractionDigits: %d", n.FractionDigits)
+		}
+		if n.Negative {
+			s += ", Negative: true"
+		}
+		return s + "}"
+	}
+	var rs []string
+	for _, yr := range r {
+		rs = append(rs, fmt.Sprintf("{Min: %s, Max: %s}", number(yr.Min), number(yr.Max)))
+	}
+	return "yang.YangRange{" + strings.Join(rs, ", ") + "}"
+}
+
+// enumLit returns a call of a function literal that returns e, an
+// enumeration or bitfield created by the yang function newFunc.
+func enumLit(newFunc string, e *yang.EnumType) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "func() *yang.EnumType {\ne := yang.%s()\n", newFunc)
+	for _, v := range e.Values() {
+		fmt.Fprintf(&b, "e.Set(%q, %d)\n", e.Name(v), v)
+	}
+	b.WriteString("return e\n}()")
+	return b.String()
+}
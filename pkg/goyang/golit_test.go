@@ -0,0 +1,55 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestGoLit(t *testing.T) {
+	var buf bytes.Buffer
+	doGoLit(&buf, testEntries(t, "example.yang"))
+	src := buf.Bytes()
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		t.Fatalf("golit output is not valid Go: %v\n%s", err, src)
+	}
+	if !bytes.Equal(formatted, src) {
+		t.Errorf("golit output is not gofmt-clean:\n%s", src)
+	}
+
+	// The output must also type check against the yang package.
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "schema.go", src, 0)
+	if err != nil {
+		t.Fatalf("cannot parse golit output: %v", err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(golitPackage, fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("golit output does not build: %v", err)
+	}
+	if pkg.Scope().Lookup(golitVar) == nil {
+		t.Errorf("golit output does not declare %s", golitVar)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2015 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goyang implements the goyang command.  The goyang program calls
+// Run, and programs that need additional output formats do the same after
+// importing the packages that register those formats with the
+// github.com/openconfig/goyang/pkg/format package:
+//
+//	import _ "example.com/myformat"
+//
+//	func main() {
+//		goyang.Run(os.Args)
+//	}
+//
+// The built-in formats are registered by this package.
+package goyang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/indent"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+// exitIfError writes errs to standard error and exits with an exit status of 1.
+// If errs is empty then exitIfError does nothing and simply returns.
+func exitIfError(errs []error) {
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		stop(1)
+	}
+}
+
+var stop = os.Exit
+
+// Run runs the goyang command with the command line arguments args, the
+// first of which is the program name.  The output is written to standard
+// output and errors to standard error.  Run exits the program with a non-zero
+// status if any errors were found, otherwise it returns.
+func Run(args []string) {
+	var formatName string
+	flags := getopt.New()
+	formats := format.Names()
+
+	var traceP string
+	var help bool
+	var paths []string
+	var ignoreSubmoduleCircularDependencies bool
+	var urlCache string
+	var urlTimeout time.Duration
+	var strict bool
+	var continueOnError bool
+	flags.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
+	flags.StringVarLong(&formatName, "format", 'f', "format to display: "+strings.Join(formats, ", "), "FORMAT")
+	flags.StringVarLong(&traceP, "trace", 't', "write trace into to TRACEFILE", "TRACEFILE")
+	flags.BoolVarLong(&help, "help", 'h', "display help")
+	flags.BoolVarLong(&ignoreSubmoduleCircularDependencies, "ignore-circdep", 'g', "ignore circular dependencies between submodules")
+	flags.StringVarLong(&urlCache, "url-cache", 0, "cache modules fetched from http(s) search paths in DIR", "DIR")
+	flags.DurationVarLong(&urlTimeout, "url-timeout", 0, "timeout for fetching a module from an http(s) search path", "DURATION")
+	flags.BoolVarLong(&strict, "strict", 0, "reject extension statements not defined by a loaded extension")
+	flags.BoolVarLong(&continueOnError, "continue-on-error", 0, "display the modules without errors even if other modules have errors")
+	flags.SetParameters("[FORMAT OPTIONS] [SOURCE] [...]")
+
+	if err := flags.Getopt(args, func(o getopt.Option) bool {
+		if o.Name() == "--format" {
+			f := format.Lookup(formatName)
+			if f == nil {
+				fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", formatName, strings.Join(formats, ", "))
+				stop(1)
+			}
+			if f.Flags != nil {
+				f.Flags.VisitAll(func(o getopt.Option) {
+					flags.AddOption(o)
+				})
+			}
+		}
+		return true
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flags.PrintUsage(os.Stderr)
+		stop(1)
+	}
+
+	if traceP != "" {
+		fp, err := os.Create(traceP)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		trace.Start(fp)
+		stop = func(c int) { trace.Stop(); os.Exit(c) }
+		defer func() { trace.Stop() }()
+	}
+
+	if help {
+		flags.PrintUsage(os.Stderr)
+		fmt.Fprintf(os.Stderr, `
+SOURCE may be a module name or a .yang file.
+
+Formats:
+`)
+		for _, fn := range formats {
+			f := format.Lookup(fn)
+			fmt.Fprintf(os.Stderr, "    %s - %s\n", f.Name, f.Help)
+			if f.Flags != nil {
+				f.Flags.PrintOptions(indent.NewWriter(os.Stderr, "   "))
+			}
+			fmt.Fprintln(os.Stderr)
+		}
+		stop(0)
+	}
+
+	ms := yang.NewModules()
+	ms.ParseOptions.IgnoreSubmoduleCircularDependencies = ignoreSubmoduleCircularDependencies
+	ms.ParseOptions.URLCacheDir = urlCache
+	ms.ParseOptions.URLTimeout = urlTimeout
+	ms.ParseOptions.Strict = strict
+	ms.ParseOptions.ContinueOnError = continueOnError
+
+	for _, path := range paths {
+		if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+			// URLs cannot be scanned for modules, use them as is.
+			ms.AddPath(path)
+			continue
+		}
+		expanded, err := yang.PathsWithModules(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		ms.AddPath(expanded...)
+	}
+
+	if formatName == "" {
+		formatName = "tree"
+	}
+	if format.Lookup(formatName) == nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", formatName, strings.Join(formats, ", "))
+		stop(1)
+
+	}
+
+	files := flags.Args()
+
+	if len(files) == 0 {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err == nil {
+			err = ms.Parse(string(data), "<STDIN>")
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			stop(1)
+		}
+	}
+
+	// Errors reading files are reported but do not prevent the remaining
+	// files from being processed.  They do cause a non-zero exit status.
+	var readErrs []error
+	for _, name := range files {
+		if err := ms.Read(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			readErrs = append(readErrs, err)
+			continue
+		}
+	}
+
+	// Process the read files, exiting if any errors were found.  With
+	// --continue-on-error the errors are reported, the modules without
+	// errors are displayed and the exit status is non-zero.
+	processErrs := ms.Process()
+	if continueOnError {
+		for _, err := range processErrs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	} else {
+		exitIfError(processErrs)
+	}
+	failed := ms.ModuleErrors()
+
+	// Warnings do not stop the output from being produced.
+	for _, w := range ms.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", w)
+	}
+
+	// Keep track of the top level modules we read in.
+	// Those are the only modules we want to print below.
+	mods := map[string]*yang.Module{}
+	var names []string
+
+	for _, m := range ms.Modules {
+		if mods[m.Name] == nil && failed[m.Name] == nil {
+			mods[m.Name] = m
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	entries := make([]*yang.Entry, len(names))
+	for x, n := range names {
+		entries[x] = yang.ToEntry(mods[n])
+	}
+
+	format.Lookup(formatName).Format(os.Stdout, entries)
+	if len(readErrs) > 0 || len(processErrs) > 0 {
+		stop(1)
+	}
+}
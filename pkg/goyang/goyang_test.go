@@ -0,0 +1,68 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// testEntries returns the Entry trees of the modules read from the named
+// files in testdata, as Run passes them to a format.
+func testEntries(t *testing.T, files ...string) []*yang.Entry {
+	t.Helper()
+	ms := yang.NewModules()
+	ms.AddPath("testdata")
+	for _, f := range files {
+		if err := ms.Read(filepath.Join("testdata", f)); err != nil {
+			t.Fatalf("cannot read %s: %v", f, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	mods := map[string]*yang.Module{}
+	var names []string
+	for _, m := range ms.Modules {
+		if mods[m.Name] == nil {
+			mods[m.Name] = m
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	var entries []*yang.Entry
+	for _, n := range names {
+		entries = append(entries, yang.ToEntry(mods[n]))
+	}
+	return entries
+}
+
+// checkGolden reports an error if got differs from the contents of the named
+// file in testdata.
+func checkGolden(t *testing.T, got, file string) {
+	t.Helper()
+	want, err := ioutil.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("cannot read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), got); diff != "" {
+		t.Errorf("output differs from testdata/%s (-want, +got):\n%s", file, diff)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// This file translates Entry trees into JSON Schema objects describing their
+// data as encoded by RFC7951.  Only the subset of JSON Schema that is also
+// understood by OpenAPI 3.0 is used so the schemas can be embedded in the
+// output of formatters such as openapi.
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// A jsonSchema is a JSON Schema object.  Maps are used rather than structures
+// so encoding/json writes the members sorted and omits those not set.
+type jsonSchema map[string]interface{}
+
+// entrySchema returns the schema for the value of e.
+func entrySchema(e *yang.Entry) jsonSchema {
+	var s jsonSchema
+	switch {
+	case e.IsList():
+		s = jsonSchema{"type": "array", "items": objectSchema(e)}
+		addListBounds(s, e.ListAttr)
+	case e.IsLeafList():
+		s = jsonSchema{"type": "array", "items": typeSchema(e.Type)}
+		addListBounds(s, e.ListAttr)
+	case e.IsLeaf():
+		s = typeSchema(e.Type)
+	case e.Kind == yang.AnyDataEntry || e.Kind == yang.AnyXMLEntry:
+		// Any value is allowed.
+		s = jsonSchema{}
+	default:
+		s = objectSchema(e)
+	}
+	if e.Description != "" {
+		s["description"] = e.Description
+	}
+	return s
+}
+
+// objectSchema returns the schema for the JSON object holding the children of
+// the directory e.
+func objectSchema(e *yang.Entry) jsonSchema {
+	props := jsonSchema{}
+	var required []string
+	addProperties(props, &required, e, true)
+	s := jsonSchema{"type": "object"}
+	if len(props) > 0 {
+		s["properties"] = props
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		s["required"] = required
+	}
+	return s
+}
+
+// addProperties adds the schemas of the data children of e to props.  Choice
+// and case nodes do not appear in the data so their children are added in
+// their place.  The names of mandatory children, and list keys, are appended
+// to required if direct is true, i.e., they are not part of a choice.
+func addProperties(props jsonSchema, required *[]string, e *yang.Entry, direct bool) {
+	keys := map[string]bool{}
+	if e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			keys[k] = true
+		}
+	}
+	for _, c := range e.Dir {
+		switch {
+		case c.IsChoice() || c.IsCase():
+			addProperties(props, required, c, false)
+		case c.Kind == yang.NotificationEntry || c.RPC != nil || isAction(c):
+			// Not part of the data tree.
+		default:
+			name := c.JSONName()
+			props[name] = entrySchema(c)
+			if direct && (keys[c.Name] || c.Mandatory == yang.TSTrue) {
+				*required = append(*required, name)
+			}
+		}
+	}
+}
+
+// addListBounds adds the min-elements and max-elements constraints in la,
+// if any, to s.
+func addListBounds(s jsonSchema, la *yang.ListAttr) {
+	if la == nil {
+		return
+	}
+	if la.MinElements > 0 {
+		s["minItems"] = la.MinElements
+	}
+	if la.MaxElements != math.MaxUint64 {
+		s["maxItems"] = la.MaxElements
+	}
+}
+
+// typeSchema returns the schema of a value of type t.
+func typeSchema(t *yang.YangType) jsonSchema {
+	if t == nil {
+		return jsonSchema{}
+	}
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yuint8, yang.Yuint16, yang.Yuint32:
+		s := jsonSchema{"type": "integer"}
+		if min, ok := t.Range.Min(); ok {
+			s["minimum"] = min
+		}
+		if max, ok := t.Range.Max(); ok {
+			s["maximum"] = max
+		}
+		return s
+	case yang.Yint64, yang.Yuint64, yang.Ydecimal64:
+		// RFC7951 section 6.1 encodes these as strings so that they are
+		// not subject to the precision of JSON numbers.
+		return jsonSchema{"type": "string", "format": t.Kind.String()}
+	case yang.Ystring:
+		s := jsonSchema{"type": "string"}
+		if min, ok := t.Length.UintMin(); ok && min > 0 {
+			s["minLength"] = min
+		}
+		if max, ok := t.Length.UintMax(); ok && max != math.MaxUint64 {
+			s["maxLength"] = max
+		}
+		// YANG patterns are implicitly anchored and a value must
+		// conform to all of them.
+		var all []jsonSchema
+		for _, p := range t.Pattern {
+			ps := jsonSchema{"pattern": "^(?:" + p.Expr + ")$"}
+			if p.InvertMatch {
+				ps = jsonSchema{"not": ps}
+			}
+			all = append(all, ps)
+		}
+		switch {
+		case len(all) == 1 && !t.Pattern[0].InvertMatch:
+			s["pattern"] = all[0]["pattern"]
+		case len(all) > 0:
+			s["allOf"] = all
+		}
+		return s
+	case yang.Ybool:
+		return jsonSchema{"type": "boolean"}
+	case yang.Yenum:
+		s := jsonSchema{"type": "string"}
+		if t.Enum != nil {
+			s["enum"] = t.Enum.Names()
+		}
+		return s
+	case yang.Yidentityref:
+		s := jsonSchema{"type": "string"}
+		if t.IdentityBase != nil && len(t.IdentityBase.Values) > 0 {
+			var names []string
+			for _, v := range t.IdentityBase.Values {
+				names = append(names, nodeModule(v)+":"+v.Name)
+			}
+			sort.Strings(names)
+			s["enum"] = names
+		}
+		return s
+	case yang.Ybinary:
+		return jsonSchema{"type": "string", "format": "byte"}
+	case yang.Yempty:
+		// RFC7951 section 6.9 encodes the empty value as [null].
+		return jsonSchema{
+			"type":     "array",
+			"items":    jsonSchema{"nullable": true},
+			"minItems": 1,
+			"maxItems": 1,
+		}
+	case yang.Yunion:
+		var alts []jsonSchema
+		for _, ut := range t.Type {
+			alts = append(alts, typeSchema(ut))
+		}
+		return jsonSchema{"anyOf": alts}
+	}
+	// bits, leafref, instance-identifier and anything unknown are strings.
+	return jsonSchema{"type": "string"}
+}
+
+// nodeModule returns the name of the module that n is defined in.
+func nodeModule(n yang.Node) string {
+	if n == nil {
+		return ""
+	}
+	m := yang.RootNode(n)
+	if m == nil {
+		return ""
+	}
+	if m.Kind() == "submodule" && m.BelongsTo != nil {
+		return m.BelongsTo.Name
+	}
+	return m.Name
+}
+
+// isAction reports whether e is an action statement.
+func isAction(e *yang.Entry) bool {
+	return e.Node != nil && e.Node.Kind() == "action"
+}
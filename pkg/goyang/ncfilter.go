@@ -0,0 +1,177 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The nc-filter format writes a NETCONF subtree filter (RFC6241 section 6)
+// selecting the configuration of the modules, for use in a <get-config> or
+// <get> operation.  Every configuration data node is an element, with the
+// leaves and leaf-lists as empty selection nodes, so any element can be
+// deleted to narrow the selection and content can be added to a leaf to
+// make it a content match node.  Choice and case nodes do not appear in the
+// data tree and so are omitted, as are state data, operations and
+// notifications.  Whenever the namespace changes, starting with the top
+// level elements, the element declares its namespace.
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var ncFilterPath string
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "nc-filter",
+		Format: doNCFilter,
+		Help:   "display a NETCONF subtree filter selecting the configuration",
+		Flags:  flags,
+	})
+	flags.StringVarLong(&ncFilterPath, "nc-filter_path", 0, "only select the configuration at PATH, e.g., /interfaces/interface", "PATH")
+}
+
+func doNCFilter(w io.Writer, entries []*yang.Entry) {
+	var top []*yang.Entry
+	for _, e := range entries {
+		top = append(top, ncFilterChildren(e)...)
+	}
+	if ncFilterPath != "" {
+		path := ncFilterFind(top, ncFilterPath)
+		if path == nil {
+			fmt.Fprintf(os.Stderr, "nc-filter: %s is not a configuration data node\n", ncFilterPath)
+			stop(1)
+		}
+		fmt.Fprintln(w, `<filter type="subtree">`)
+		writeNCFilterPath(w, "  ", path, "")
+		fmt.Fprintln(w, `</filter>`)
+		return
+	}
+	fmt.Fprintln(w, `<filter type="subtree">`)
+	for _, e := range top {
+		writeNCFilter(w, "  ", e, "")
+	}
+	fmt.Fprintln(w, `</filter>`)
+}
+
+// ncFilterChildren returns the configuration data nodes that are children of
+// e in the data tree, looking through any choice and case nodes.  The keys of
+// a list come first, in the order of its key statement, as they are the
+// elements to fill in to select entries of the list.
+func ncFilterChildren(e *yang.Entry) []*yang.Entry {
+	var children []*yang.Entry
+	keys := map[string]bool{}
+	if e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			if c := e.Dir[k]; c != nil && !keys[k] {
+				keys[k] = true
+				children = append(children, c)
+			}
+		}
+	}
+	for _, c := range sortedChildren(e) {
+		switch {
+		case keys[c.Name], c.RPC != nil, c.Kind == yang.NotificationEntry, c.ReadOnly():
+		case c.IsChoice(), c.IsCase():
+			children = append(children, ncFilterChildren(c)...)
+		default:
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// ncFilterFind returns the nodes along path, a data path with optional
+// prefixes, starting with the one of top named by its first element.  nil
+// is returned if there is no such configuration data node.
+func ncFilterFind(top []*yang.Entry, path string) []*yang.Entry {
+	var found []*yang.Entry
+	candidates := top
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		if i := strings.Index(part, ":"); i >= 0 {
+			part = part[i+1:]
+		}
+		var next *yang.Entry
+		for _, c := range candidates {
+			if c.Name == part {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		found = append(found, next)
+		candidates = ncFilterChildren(next)
+	}
+	return found
+}
+
+// writeNCFilterPath writes the elements of the first of path and its
+// descendants along path, ending with the subtree of the last, to w.
+// parentNS is the namespace of the enclosing element.
+func writeNCFilterPath(w io.Writer, indent string, path []*yang.Entry, parentNS string) {
+	e := path[0]
+	if len(path) == 1 {
+		writeNCFilter(w, indent, e, parentNS)
+		return
+	}
+	ns := ncFilterNamespace(e)
+	fmt.Fprintf(w, "%s<%s%s>\n", indent, e.Name, ncFilterXMLNS(ns, parentNS))
+	writeNCFilterPath(w, indent+"  ", path[1:], ns)
+	fmt.Fprintf(w, "%s</%s>\n", indent, e.Name)
+}
+
+// writeNCFilter writes the element of e, and those of its configuration
+// descendants, to w.  parentNS is the namespace of the enclosing element.
+func writeNCFilter(w io.Writer, indent string, e *yang.Entry, parentNS string) {
+	ns := ncFilterNamespace(e)
+	children := ncFilterChildren(e)
+	if len(children) == 0 {
+		fmt.Fprintf(w, "%s<%s%s/>\n", indent, e.Name, ncFilterXMLNS(ns, parentNS))
+		return
+	}
+	fmt.Fprintf(w, "%s<%s%s>\n", indent, e.Name, ncFilterXMLNS(ns, parentNS))
+	for _, c := range children {
+		writeNCFilter(w, indent+"  ", c, ns)
+	}
+	fmt.Fprintf(w, "%s</%s>\n", indent, e.Name)
+}
+
+// ncFilterNamespace returns the namespace of e.
+func ncFilterNamespace(e *yang.Entry) string {
+	if ns := e.Namespace(); ns != nil {
+		return ns.Name
+	}
+	return ""
+}
+
+// ncFilterXMLNS returns the xmlns attribute, with a leading space, declaring
+// ns if it differs from parentNS, otherwise the empty string.
+func ncFilterXMLNS(ns, parentNS string) string {
+	if ns == parentNS {
+		return ""
+	}
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(ns))
+	return ` xmlns="` + b.String() + `"`
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNCFilter(t *testing.T) {
+	entries := testEntries(t, "example.yang")
+	defer func(path string) { ncFilterPath = path }(ncFilterPath)
+
+	for _, tt := range []struct {
+		path string
+		want string
+	}{{
+		want: `<filter type="subtree">
+  <system xmlns="urn:example">
+    <dns/>
+    <hostname/>
+    <primary/>
+    <server>
+      <name/>
+      <mode/>
+      <peer>
+        <address/>
+        <port/>
+      </peer>
+      <weight/>
+    </server>
+    <tcp-port/>
+    <udp-port/>
+  </system>
+</filter>
+`,
+	}, {
+		path: "/ex:system/server/peer",
+		want: `<filter type="subtree">
+  <system xmlns="urn:example">
+    <server>
+      <peer>
+        <address/>
+        <port/>
+      </peer>
+    </server>
+  </system>
+</filter>
+`,
+	}, {
+		path: "/system/hostname",
+		want: `<filter type="subtree">
+  <system xmlns="urn:example">
+    <hostname/>
+  </system>
+</filter>
+`,
+	}} {
+		ncFilterPath = tt.path
+		var buf bytes.Buffer
+		doNCFilter(&buf, entries)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("doNCFilter with path %q: got:\n%s\nwant:\n%s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"io"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// The none format is used to validate the input.  All processing is done
+// (and any errors are reported on standard error with an exit status of 1),
+// but nothing is written on standard output.
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "none",
+		Format: func(io.Writer, []*yang.Entry) {},
+		Help:   "only report errors, do not display the schema",
+	})
+}
@@ -0,0 +1,297 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The openapi format describes the RESTCONF (RFC8040) interface to the
+// modules as an OpenAPI 3.0 specification.  RPCs are POST operations on the
+// operations resource and actions are POST operations on the data node that
+// defines them.  Every data node is a path on the data resource supporting
+// GET and, if it is configuration, PUT.  The request and response bodies are
+// described using the JSON Schema translation in jsonschema.go.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var (
+	openapiTitle   string
+	openapiVersion string
+	openapiRoot    = "/restconf"
+)
+
+// openapiMediaType is the media type of RESTCONF JSON bodies.
+const openapiMediaType = "application/yang-data+json"
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "openapi",
+		Format: doOpenAPI,
+		Help:   "display an OpenAPI 3.0 description of the RESTCONF interface",
+		Flags:  flags,
+	})
+	flags.StringVarLong(&openapiTitle, "openapi_title", 0, "title of the API, defaults to the module names", "TITLE")
+	flags.StringVarLong(&openapiVersion, "openapi_version", 0, "version of the API, defaults to the latest module revision", "VERSION")
+	flags.StringVarLong(&openapiRoot, "openapi_root", 0, "the RESTCONF root resource, defaults to /restconf", "PATH")
+}
+
+// An openapiObject is any object in an OpenAPI specification.
+type openapiObject map[string]interface{}
+
+func doOpenAPI(w io.Writer, entries []*yang.Entry) {
+	var names []string
+	var version string
+	paths := openapiObject{}
+	root := strings.TrimSuffix(openapiRoot, "/")
+	for _, e := range entries {
+		names = append(names, e.Name)
+		if m, ok := e.Node.(*yang.Module); ok && m.Current() > version {
+			version = m.Current()
+		}
+		addOpenAPIPaths(paths, root, e)
+	}
+
+	title := openapiTitle
+	if title == "" {
+		title = strings.Join(names, ", ")
+	}
+	if openapiVersion != "" {
+		version = openapiVersion
+	}
+	if version == "" {
+		version = "unversioned"
+	}
+
+	doc := openapiObject{
+		"openapi": "3.0.3",
+		"info": openapiObject{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop(1)
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
+
+// addOpenAPIPaths adds the paths for the RPCs and data nodes of the module e
+// to paths.
+func addOpenAPIPaths(paths openapiObject, root string, e *yang.Entry) {
+	for _, c := range sortedChildren(e) {
+		switch {
+		case c.Kind == yang.NotificationEntry:
+		case c.RPC != nil:
+			paths[root+"/operations/"+qualifiedName(c)] = openapiObject{
+				"post": operationObject(c),
+			}
+		case c.IsChoice() || c.IsCase():
+			addOpenAPIPaths(paths, root, c)
+		default:
+			addDataPaths(paths, root+"/data", c, nil, map[string]bool{})
+		}
+	}
+}
+
+// addDataPaths adds the path of the data node e, whose parent's path is
+// parent, and those of its descendants to paths.  params are the path
+// parameters of the list keys in parent and used contains their names.
+func addDataPaths(paths openapiObject, parent string, e *yang.Entry, params []openapiObject, used map[string]bool) {
+	// Only the first node of a path is module qualified unless the
+	// module changes, which matches the member names used by RFC7951.
+	segment := e.JSONName()
+	if e.IsList() {
+		var values []string
+		// Copy params and used so that sibling lists do not see the
+		// parameters of each other.
+		params = append([]openapiObject(nil), params...)
+		used = copyUsed(used)
+		for _, k := range strings.Fields(e.Key) {
+			name := paramName(used, e.Name, k)
+			values = append(values, "{"+name+"}")
+			var t *yang.YangType
+			if ke := e.Dir[k]; ke != nil {
+				t = ke.Type
+			}
+			params = append(params, openapiObject{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   typeSchema(t),
+			})
+		}
+		if len(values) > 0 {
+			segment += "=" + strings.Join(values, ",")
+		}
+	}
+	path := parent + "/" + segment
+
+	body := jsonSchema{
+		"type":       "object",
+		"properties": jsonSchema{qualifiedName(e): entrySchema(e)},
+	}
+	item := openapiObject{
+		"get": openapiObject{
+			"tags":      []string{moduleName(e)},
+			"summary":   "Retrieve " + e.Path(),
+			"responses": openapiObject{"200": contentObject("OK", body)},
+		},
+	}
+	if !e.ReadOnly() {
+		item["put"] = openapiObject{
+			"tags":        []string{moduleName(e)},
+			"summary":     "Create or replace " + e.Path(),
+			"requestBody": contentObject("", body),
+			"responses": openapiObject{
+				"201": openapiObject{"description": "Created"},
+				"204": openapiObject{"description": "Replaced"},
+			},
+		}
+	}
+	if len(params) > 0 {
+		item["parameters"] = params
+	}
+	paths[path] = item
+
+	var addChildren func(*yang.Entry)
+	addChildren = func(d *yang.Entry) {
+		for _, c := range sortedChildren(d) {
+			switch {
+			case c.Kind == yang.NotificationEntry:
+			case isAction(c):
+				op := operationObject(c)
+				if len(params) > 0 {
+					op["parameters"] = params
+				}
+				paths[path+"/"+c.JSONName()] = openapiObject{"post": op}
+			case c.IsChoice() || c.IsCase():
+				addChildren(c)
+			default:
+				addDataPaths(paths, path, c, params, used)
+			}
+		}
+	}
+	addChildren(e)
+}
+
+// operationObject returns the POST operation invoking the RPC or action e.
+func operationObject(e *yang.Entry) openapiObject {
+	op := openapiObject{
+		"tags":    []string{moduleName(e)},
+		"summary": "Invoke " + e.Path(),
+	}
+	if e.Description != "" {
+		op["description"] = e.Description
+	}
+	mod := moduleName(e)
+	var in, out *yang.Entry
+	if e.RPC != nil {
+		in, out = e.RPC.Input, e.RPC.Output
+	}
+	if in != nil {
+		op["requestBody"] = contentObject("", jsonSchema{
+			"type":       "object",
+			"properties": jsonSchema{mod + ":input": objectSchema(in)},
+		})
+	}
+	if out != nil {
+		op["responses"] = openapiObject{"200": contentObject("OK", jsonSchema{
+			"type":       "object",
+			"properties": jsonSchema{mod + ":output": objectSchema(out)},
+		})}
+	} else {
+		op["responses"] = openapiObject{"204": openapiObject{"description": "No Content"}}
+	}
+	return op
+}
+
+// contentObject returns a response object, if description is set, or a
+// request body object whose content is described by schema.
+func contentObject(description string, schema jsonSchema) openapiObject {
+	o := openapiObject{
+		"content": openapiObject{
+			openapiMediaType: openapiObject{"schema": schema},
+		},
+	}
+	if description != "" {
+		o["description"] = description
+	} else {
+		o["required"] = true
+	}
+	return o
+}
+
+// sortedChildren returns the children of e sorted by name.
+func sortedChildren(e *yang.Entry) []*yang.Entry {
+	var names []string
+	for n := range e.Dir {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	children := make([]*yang.Entry, len(names))
+	for i, n := range names {
+		children[i] = e.Dir[n]
+	}
+	return children
+}
+
+// moduleName returns the name of the module that instantiates e, or the name
+// of the module e is defined in if that cannot be determined.
+func moduleName(e *yang.Entry) string {
+	if m, err := e.InstantiatingModule(); err == nil {
+		return m
+	}
+	return nodeModule(e.Node)
+}
+
+// qualifiedName returns the name of e qualified by the name of its module.
+func qualifiedName(e *yang.Entry) string {
+	return moduleName(e) + ":" + e.Name
+}
+
+// paramName returns a name for the path parameter holding the value of key
+// of list that has not already been used, and marks it as used.
+func paramName(used map[string]bool, list, key string) string {
+	name := key
+	if used[name] {
+		name = list + "-" + key
+	}
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%s-%d", list, key, i)
+	}
+	used[name] = true
+	return name
+}
+
+// copyUsed returns a copy of used.
+func copyUsed(used map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(used))
+	for k, v := range used {
+		c[k] = v
+	}
+	return c
+}
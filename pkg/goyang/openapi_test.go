@@ -0,0 +1,28 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestOpenAPI checks the OpenAPI document, and so the JSON Schema of each
+// node, generated for a module with a keyed list, a choice and a leafref.
+func TestOpenAPI(t *testing.T) {
+	var buf bytes.Buffer
+	doOpenAPI(&buf, testEntries(t, "routes.yang"))
+	checkGolden(t, buf.String(), "routes.openapi.json")
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The rpc-signatures format writes a one line signature for each RPC and
+// action of the modules, listing the parameters of its input and output:
+//
+//	ietf-system:set-current-datetime(current-datetime:date-and-time) -> ()
+//	ex:interfaces/interface/reset(delay:uint32, opts:{...}) -> (log:[string])
+//
+// An RPC is named by its module and name, and an action by its module and
+// the data path of the action, with choice and case nodes omitted.  Each
+// parameter is written as name:type, where the type is the name of the type
+// of a leaf, [type] for a leaf-list, {...} for a container, [{...}] for a
+// list, and anydata or anyxml.  The children of choices and cases are
+// parameters of the enclosing node.  The parameters of containers and lists
+// are elided beyond the depth given by --rpc-signatures_depth.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var rpcSignaturesDepth = 1
+
+func init() {
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "rpc-signatures",
+		Format: doRPCSignatures,
+		Help:   "display a one line signature for each RPC and action",
+		Flags:  flags,
+	})
+	flags.IntVarLong(&rpcSignaturesDepth, "rpc-signatures_depth", 0, "display the parameters of containers and lists to depth N", "N")
+}
+
+func doRPCSignatures(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		writeRPCSignatures(w, e.Name+":", e)
+	}
+}
+
+// writeRPCSignatures writes the signatures of the RPCs and actions beneath e
+// to w.  prefix is written before the name of each, and is the module name
+// and a colon followed by the data path of e.
+func writeRPCSignatures(w io.Writer, prefix string, e *yang.Entry) {
+	for _, c := range sortedChildren(e) {
+		switch {
+		case c.RPC != nil:
+			fmt.Fprintf(w, "%s%s(%s) -> (%s)\n", prefix, c.Name, rpcParams(c.RPC.Input, 0), rpcParams(c.RPC.Output, 0))
+		case c.Kind == yang.NotificationEntry:
+		case c.IsChoice(), c.IsCase():
+			writeRPCSignatures(w, prefix, c)
+		case c.IsDir():
+			writeRPCSignatures(w, prefix+c.Name+"/", c)
+		}
+	}
+}
+
+// rpcParams returns the comma separated parameters that are the children of
+// e, an input, output, container or list, which is at the given depth beneath
+// the input or output.
+func rpcParams(e *yang.Entry, depth int) string {
+	if e == nil {
+		return ""
+	}
+	var params []string
+	var add func(e *yang.Entry)
+	add = func(e *yang.Entry) {
+		for _, c := range sortedChildren(e) {
+			switch {
+			case c.IsChoice(), c.IsCase():
+				add(c)
+			case c.IsLeaf():
+				params = append(params, c.Name+":"+c.Type.DisplayName())
+			case c.IsLeafList():
+				params = append(params, c.Name+":["+c.Type.DisplayName()+"]")
+			case c.Kind == yang.AnyDataEntry:
+				params = append(params, c.Name+":anydata")
+			case c.Kind == yang.AnyXMLEntry:
+				params = append(params, c.Name+":anyxml")
+			default:
+				body := "..."
+				if depth+1 < rpcSignaturesDepth {
+					body = rpcParams(c, depth+1)
+				}
+				if c.IsList() {
+					params = append(params, c.Name+":[{"+body+"}]")
+				} else {
+					params = append(params, c.Name+":{"+body+"}")
+				}
+			}
+		}
+	}
+	add(e)
+	return strings.Join(params, ", ")
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestRPCSignatures(t *testing.T) {
+	ms := yang.NewModules()
+	if err := ms.Parse(`module m {
+  yang-version 1.1;
+  prefix "m";
+  namespace "urn:m";
+
+  rpc reboot {
+    input {
+      leaf delay { type uint32; }
+      choice when {
+        leaf at { type string; }
+      }
+    }
+  }
+  container interfaces {
+    list interface {
+      key "name";
+      leaf name { type string; }
+      action reset {
+        input {
+          container opts {
+            leaf force { type boolean; }
+            list step {
+              key "id";
+              leaf id { type uint8; }
+            }
+          }
+        }
+        output {
+          leaf-list log { type string; }
+        }
+      }
+    }
+  }
+}`, "m.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := yang.ToEntry(ms.Modules["m"])
+	defer func(depth int) { rpcSignaturesDepth = depth }(rpcSignaturesDepth)
+
+	for _, tt := range []struct {
+		depth int
+		want  string
+	}{{
+		depth: 0,
+		want: "m:interfaces/interface/reset(opts:{...}) -> (log:[string])\n" +
+			"m:reboot(delay:uint32, at:string) -> ()\n",
+	}, {
+		depth: 1,
+		want: "m:interfaces/interface/reset(opts:{...}) -> (log:[string])\n" +
+			"m:reboot(delay:uint32, at:string) -> ()\n",
+	}, {
+		depth: 2,
+		want: "m:interfaces/interface/reset(opts:{force:boolean, step:[{...}]}) -> (log:[string])\n" +
+			"m:reboot(delay:uint32, at:string) -> ()\n",
+	}, {
+		depth: 3,
+		want: "m:interfaces/interface/reset(opts:{force:boolean, step:[{id:uint8}]}) -> (log:[string])\n" +
+			"m:reboot(delay:uint32, at:string) -> ()\n",
+	}} {
+		rpcSignaturesDepth = tt.depth
+		var buf bytes.Buffer
+		doRPCSignatures(&buf, []*yang.Entry{e})
+		if got := buf.String(); got != tt.want {
+			t.Errorf("doRPCSignatures with depth %d: got:\n%s\nwant:\n%s", tt.depth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The sql format writes SQL DDL for a relational schema that can hold the
+// data of the modules.  Each list is a table whose primary key is made up of
+// the keys of the list and of all its ancestor lists, with a foreign key
+// referencing the table of its parent list.  The leaves of a list, including
+// those within its containers, choices and cases, are columns of its table.
+// Each leaf-list is a junction table holding its values.  Leaves that are not
+// within any list are columns of a single row table named after the module.
+// RPCs, actions and notifications are not data and are skipped.
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "sql",
+		Format: doSQL,
+		Help:   "display SQL DDL with a table for each list and leaf-list",
+	})
+}
+
+// An sqlColumn is a column of an sqlTable.
+type sqlColumn struct {
+	name    string
+	typ     string
+	notNull bool
+	def     string   // the DEFAULT expression, if any
+	checks  []string // CHECK expressions constraining the column
+}
+
+// An sqlTable is a table created for a list, leaf-list or module.
+type sqlTable struct {
+	name    string
+	short   string // the name of the list the table was created for, if any
+	source  string // the schema path the table was created for
+	columns []*sqlColumn
+	primary []string // the names of the primary key columns
+	parent  *sqlTable
+	// parentColumns are the columns referencing the primary key of
+	// parent, in the same order.
+	parentColumns []string
+	used          map[string]bool // the column names in use
+}
+
+// addColumn adds c to t, renaming it if its name is already in use.
+func (t *sqlTable) addColumn(c *sqlColumn) *sqlColumn {
+	name := c.name
+	for i := 2; t.used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", c.name, i)
+	}
+	c.name = name
+	t.used[name] = true
+	t.columns = append(t.columns, c)
+	return c
+}
+
+// inherit adds the primary key columns of parent to t, as the leading columns
+// of its primary key, and references them in a foreign key.  The columns are
+// prefixed by the short name of parent, if it has one.
+func (t *sqlTable) inherit(parent *sqlTable) {
+	if parent == nil || len(parent.primary) == 0 {
+		return
+	}
+	t.parent = parent
+	for _, pk := range parent.primary {
+		var pc *sqlColumn
+		for _, c := range parent.columns {
+			if c.name == pk {
+				pc = c
+			}
+		}
+		name := pk
+		if parent.short != "" && !strings.HasPrefix(name, parent.short+"_") {
+			name = parent.short + "_" + pk
+		}
+		c := t.addColumn(&sqlColumn{name: name, typ: pc.typ, notNull: true})
+		t.parentColumns = append(t.parentColumns, c.name)
+		t.primary = append(t.primary, c.name)
+	}
+}
+
+func doSQL(w io.Writer, entries []*yang.Entry) {
+	for _, e := range entries {
+		var tables []*sqlTable
+		root := newSQLTable(sqlIdent(e.Name), e.Path())
+		tables = append(tables, root)
+		tables = addSQLColumns(tables, root, e, nil, "")
+		for _, t := range tables {
+			if len(t.columns) > 0 {
+				writeSQLTable(w, t)
+			}
+		}
+	}
+}
+
+// newSQLTable returns an empty table named name created for the node at path.
+func newSQLTable(name, path string) *sqlTable {
+	return &sqlTable{name: name, source: path, used: map[string]bool{}}
+}
+
+// addSQLColumns adds the columns for the descendants of e to t, prefixing
+// their names with prefix, and appends the tables needed for lists and
+// leaf-lists to tables, returning the result.  keys are the names of the key
+// leaves of e, which have already been added to t.
+func addSQLColumns(tables []*sqlTable, t *sqlTable, e *yang.Entry, keys map[string]bool, prefix string) []*sqlTable {
+	for _, c := range sortedChildren(e) {
+		name := prefix + sqlIdent(c.Name)
+		switch {
+		case c.Kind == yang.NotificationEntry, c.RPC != nil:
+			// Not part of the data tree.
+		case c.IsChoice() || c.IsCase():
+			// Choice and case nodes are not part of the data
+			// tree, so their leaves do not include their names.
+			tables = addSQLColumns(tables, t, c, nil, prefix)
+		case c.IsList():
+			lt := newSQLTable(t.name+"_"+name, c.Path())
+			lt.short = sqlIdent(c.Name)
+			lt.inherit(t)
+			// The primary key must be complete before the tables
+			// of nested lists inherit it.
+			ck := map[string]bool{}
+			for _, k := range strings.Fields(c.Key) {
+				if ke := c.Dir[k]; ke != nil {
+					ck[k] = true
+					col := lt.addColumn(sqlLeafColumn(sqlIdent(k), ke))
+					col.notNull = true
+					lt.primary = append(lt.primary, col.name)
+				}
+			}
+			if len(ck) == 0 {
+				// A keyless list has no natural key, so its
+				// entries are numbered.
+				id := lt.addColumn(&sqlColumn{name: "id", typ: "BIGINT", notNull: true})
+				lt.primary = append(lt.primary, id.name)
+			}
+			tables = append(tables, lt)
+			tables = addSQLColumns(tables, lt, c, ck, "")
+		case c.IsLeafList():
+			jt := newSQLTable(t.name+"_"+name, c.Path())
+			jt.inherit(t)
+			col := sqlLeafColumn("value", c)
+			col.notNull = true
+			jt.addColumn(col)
+			jt.primary = append(jt.primary, col.name)
+			tables = append(tables, jt)
+		case c.IsLeaf():
+			if !keys[c.Name] {
+				t.addColumn(sqlLeafColumn(name, c))
+			}
+		case c.Kind == yang.AnyDataEntry, c.Kind == yang.AnyXMLEntry:
+			t.addColumn(&sqlColumn{name: name, typ: "TEXT"})
+		default:
+			tables = addSQLColumns(tables, t, c, nil, name+"_")
+		}
+	}
+	return tables
+}
+
+// sqlLeafColumn returns the column named name holding the value of the leaf
+// or leaf-list e.
+func sqlLeafColumn(name string, e *yang.Entry) *sqlColumn {
+	c := &sqlColumn{name: name, notNull: e.Mandatory == yang.TSTrue}
+	c.typ, c.checks = sqlType(sqlQuoteIdent(name), e.Type)
+	if len(e.Default) == 1 && e.IsLeaf() {
+		c.def = sqlLiteral(e.Type, e.Default[0])
+	}
+	return c
+}
+
+// sqlType returns the SQL type for a value of type t held in the column
+// named col and the CHECK expressions needed to enforce its range, length
+// and enumeration restrictions.
+func sqlType(col string, t *yang.YangType) (string, []string) {
+	if t == nil {
+		return "TEXT", nil
+	}
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yint64,
+		yang.Yuint8, yang.Yuint16, yang.Yuint32, yang.Yuint64:
+		typ := map[yang.TypeKind]string{
+			yang.Yint8:   "SMALLINT",
+			yang.Yint16:  "SMALLINT",
+			yang.Yint32:  "INTEGER",
+			yang.Yint64:  "BIGINT",
+			yang.Yuint8:  "SMALLINT",
+			yang.Yuint16: "INTEGER",
+			yang.Yuint32: "BIGINT",
+			yang.Yuint64: "NUMERIC(20)",
+		}[t.Kind]
+		return typ, sqlRangeChecks(col, t.Range, yang.BaseTypedefs[t.Kind.String()].YangType.Range)
+	case yang.Ydecimal64:
+		return fmt.Sprintf("NUMERIC(19, %d)", t.FractionDigits), sqlRangeChecks(col, t.Range, nil)
+	case yang.Ystring:
+		if max, ok := t.Length.UintMax(); ok && max != math.MaxUint64 {
+			var checks []string
+			if min, _ := t.Length.UintMin(); min > 0 {
+				checks = append(checks, fmt.Sprintf("CHAR_LENGTH(%s) >= %d", col, min))
+			}
+			return fmt.Sprintf("VARCHAR(%d)", max), checks
+		}
+		return "TEXT", nil
+	case yang.Ybool, yang.Yempty:
+		return "BOOLEAN", nil
+	case yang.Ybinary:
+		return "BLOB", nil
+	case yang.Yenum:
+		if t.Enum == nil {
+			return "TEXT", nil
+		}
+		names := t.Enum.Names()
+		width := 1
+		var quoted []string
+		for _, n := range names {
+			if len(n) > width {
+				width = len(n)
+			}
+			quoted = append(quoted, sqlQuote(n))
+		}
+		return fmt.Sprintf("VARCHAR(%d)", width), []string{fmt.Sprintf("%s IN (%s)", col, strings.Join(quoted, ", "))}
+	}
+	// bits, identityref, instance-identifier, leafref and union values
+	// are stored as their string representation.
+	return "TEXT", nil
+}
+
+// sqlRangeChecks returns the CHECK expression limiting col to the range r,
+// or nothing if r is empty or the same as the range of the column's type,
+// full.
+func sqlRangeChecks(col string, r, full yang.YangRange) []string {
+	if len(r) == 0 || (full != nil && r.Equal(full)) {
+		return nil
+	}
+	var alts []string
+	for _, yr := range r {
+		if yr.Min.Equal(yr.Max) {
+			alts = append(alts, fmt.Sprintf("%s = %s", col, yr.Min))
+		} else {
+			alts = append(alts, fmt.Sprintf("%s BETWEEN %s AND %s", col, yr.Min, yr.Max))
+		}
+	}
+	return []string{strings.Join(alts, " OR ")}
+}
+
+// sqlLiteral returns the default value v of type t as an SQL literal.
+func sqlLiteral(t *yang.YangType, v string) string {
+	if t != nil {
+		switch t.Kind {
+		case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yint64,
+			yang.Yuint8, yang.Yuint16, yang.Yuint32, yang.Yuint64, yang.Ydecimal64:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return v
+			}
+		case yang.Ybool:
+			if v == "true" || v == "false" {
+				return strings.ToUpper(v)
+			}
+		}
+	}
+	return sqlQuote(v)
+}
+
+// writeSQLTable writes the CREATE TABLE statement for t to w.
+func writeSQLTable(w io.Writer, t *sqlTable) {
+	fmt.Fprintf(w, "-- %s\n", t.source)
+	fmt.Fprintf(w, "CREATE TABLE %s (\n", sqlQuoteIdent(t.name))
+	var lines []string
+	for _, c := range t.columns {
+		line := fmt.Sprintf("  %s %s", sqlQuoteIdent(c.name), c.typ)
+		if c.notNull {
+			line += " NOT NULL"
+		}
+		if c.def != "" {
+			line += " DEFAULT " + c.def
+		}
+		for _, check := range c.checks {
+			line += " CHECK (" + check + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(t.primary) > 0 {
+		lines = append(lines, fmt.Sprintf("  PRIMARY KEY (%s)", sqlQuoteIdents(t.primary)))
+	}
+	if t.parent != nil {
+		lines = append(lines, fmt.Sprintf("  FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE CASCADE",
+			sqlQuoteIdents(t.parentColumns), sqlQuoteIdent(t.parent.name), sqlQuoteIdents(t.parent.primary)))
+	}
+	fmt.Fprintf(w, "%s\n);\n\n", strings.Join(lines, ",\n"))
+}
+
+// sqlIdent returns the YANG identifier name as an SQL identifier.  The
+// characters allowed in YANG identifiers but not SQL ones are replaced by
+// underscores.
+func sqlIdent(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// sqlQuoteIdent returns name as a quoted SQL identifier.
+func sqlQuoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// sqlQuoteIdents returns names as a comma separated list of quoted SQL
+// identifiers.
+func sqlQuoteIdents(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = sqlQuoteIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// sqlQuote returns s as an SQL string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The stats format writes the number of nodes of each kind in each module, as
+// computed by Entry.Stats, as tab separated columns with one line per module
+// after a line naming the columns, for tracking the size of models over time.
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "stats",
+		Format: doStats,
+		Help:   "display the number of nodes of each kind in each module",
+	})
+}
+
+func doStats(w io.Writer, entries []*yang.Entry) {
+	fmt.Fprintln(w, "module\tcontainers\tlists\tleaves\tleaf-lists\tchoices\tcases\tanydata\trpcs\tnotifications\tnodes\tmax-depth")
+	for _, e := range entries {
+		s := e.Stats()
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", e.Name,
+			s.Containers, s.Lists, s.Leaves, s.LeafLists, s.Choices, s.Cases,
+			s.AnyData, s.RPCs, s.Notifications, s.Nodes, s.MaxDepth)
+	}
+}
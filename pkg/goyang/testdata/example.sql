@@ -0,0 +1,32 @@
+-- /example
+CREATE TABLE "example" (
+  "system_hostname" TEXT DEFAULT 'router',
+  "system_primary" TEXT,
+  "system_state_uptime" NUMERIC(20),
+  "system_tcp_port" INTEGER,
+  "system_udp_port" INTEGER
+);
+
+-- /example/system/dns
+CREATE TABLE "example_system_dns" (
+  "value" TEXT NOT NULL,
+  PRIMARY KEY ("value")
+);
+
+-- /example/system/server
+CREATE TABLE "example_system_server" (
+  "name" TEXT NOT NULL,
+  "mode" VARCHAR(7) CHECK ("mode" IN ('active', 'standby')),
+  "weight" NUMERIC(19, 2) CHECK ("weight" BETWEEN 0.00 AND 100.00),
+  PRIMARY KEY ("name")
+);
+
+-- /example/system/server/peer
+CREATE TABLE "example_system_server_peer" (
+  "server_name" TEXT NOT NULL,
+  "address" TEXT NOT NULL,
+  "port" INTEGER,
+  PRIMARY KEY ("server_name", "address"),
+  FOREIGN KEY ("server_name") REFERENCES "example_system_server" ("name") ON DELETE CASCADE
+);
+
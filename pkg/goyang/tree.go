@@ -12,27 +12,43 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package goyang
 
 import (
 	"fmt"
 	"io"
 	"sort"
 
+	"github.com/openconfig/goyang/pkg/format"
 	"github.com/openconfig/goyang/pkg/indent"
 	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/pborman/getopt"
+)
+
+var (
+	treeShowUnits  bool
+	treeConfigOnly bool
 )
 
 func init() {
-	register(&formatter{
-		name: "tree",
-		f:    doTree,
-		help: "display in a tree format",
+	flags := getopt.New()
+	format.Register(&format.Formatter{
+		Name:   "tree",
+		Format: doTree,
+		Help:   "display in a tree format",
+		Flags:  flags,
 	})
+	flags.BoolVarLong(&treeShowUnits, "tree_show-units", 0, "display the units of leaves and leaf-lists")
+	flags.BoolVarLong(&treeConfigOnly, "tree_config-only", 0, "only display configuration, omitting state data, operations and notifications")
 }
 
 func doTree(w io.Writer, entries []*yang.Entry) {
 	for _, e := range entries {
+		if treeConfigOnly {
+			if e = e.ConfigOnly(); e == nil {
+				continue
+			}
+		}
 		Write(w, e)
 	}
 }
@@ -69,12 +85,16 @@ func Write(w io.Writer, e *yang.Entry) {
 	if e.Prefix != nil {
 		name = e.Prefix.Name + ":" + name
 	}
+	var units string
+	if u := e.EffectiveUnits(); treeShowUnits && u != "" {
+		units = " units=" + u
+	}
 	switch {
 	case e.Dir == nil && e.ListAttr != nil:
-		fmt.Fprintf(w, "[]%s\n", name)
+		fmt.Fprintf(w, "[]%s%s\n", name, units)
 		return
 	case e.Dir == nil:
-		fmt.Fprintf(w, "%s\n", name)
+		fmt.Fprintf(w, "%s%s\n", name, units)
 		return
 	case e.ListAttr != nil:
 		fmt.Fprintf(w, "[%s]%s {\n", e.Key, name) //}
@@ -105,8 +125,5 @@ func getTypeName(e *yang.Entry) string {
 	if e == nil || e.Type == nil {
 		return ""
 	}
-	// Return our root's type name.
-	// This is should be the builtin type-name
-	// for this entry.
-	return e.Type.Root.Name
+	return e.Type.DisplayName()
 }
@@ -12,13 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package main
+package goyang
 
 import (
 	"fmt"
 	"io"
 	"strings"
 
+	"github.com/openconfig/goyang/pkg/format"
 	"github.com/openconfig/goyang/pkg/indent"
 	"github.com/openconfig/goyang/pkg/yang"
 	"github.com/pborman/getopt"
@@ -31,11 +32,11 @@ var (
 
 func init() {
 	flags := getopt.New()
-	register(&formatter{
-		name:  "types",
-		f:     doTypes,
-		help:  "display found types",
-		flags: flags,
+	format.Register(&format.Formatter{
+		Name:   "types",
+		Format: doTypes,
+		Help:   "display found types",
+		Flags:  flags,
 	})
 	flags.BoolVarLong(&typesDebug, "types_debug", 0, "display debug information")
 	flags.BoolVarLong(&typesVerbose, "types_verbose", 0, "include base information")
@@ -105,7 +106,16 @@ func printType(w io.Writer, t *yang.YangType, verbose bool) {
 		fmt.Fprintf(w, " path=%q", t.Path)
 	}
 	if len(t.Pattern) > 0 {
-		fmt.Fprintf(w, " pattern=%s", strings.Join(t.Pattern, "|"))
+		// Patterns values must not match are marked with a !.
+		var ps []string
+		for _, p := range t.Pattern {
+			if p.InvertMatch {
+				ps = append(ps, "!"+p.Expr)
+			} else {
+				ps = append(ps, p.Expr)
+			}
+		}
+		fmt.Fprintf(w, " pattern=%s", strings.Join(ps, "|"))
 	}
 	b := yang.BaseTypedefs[t.Kind.String()].YangType
 	if len(t.Range) > 0 && !t.Range.Equal(b.Range) {
@@ -0,0 +1,205 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The types-json format is a machine readable inventory of the types used by
+// the leaves and leaf-lists of the modules.  As with the types format, a type
+// is distinct if it is the root of a YangType, i.e., it adds a restriction or
+// a name to the type it is derived from.  The output is a single JSON object:
+//
+//	{
+//	  "types": [
+//	    {
+//	      "name": "percent",           // the type or typedef name
+//	      "kind": "uint8",             // the built-in type it is based on
+//	      "module": "example",         // the module of the typedef, if any
+//	      "source": "ex.yang:12:3",    // where the type was defined, if known
+//	      "units": "percent",
+//	      "default": "0",
+//	      "range": "0..100",           // only if narrower than the kind's
+//	      "length": "1..64",
+//	      "pattern": ["[a-z]+"],
+//	      "invert-match-pattern": ["x.*"], // values must not match these
+//	      "fraction-digits": 2,
+//	      "enum": ["down", "up"],      // sorted names
+//	      "bits": ["a", "b"],          // sorted names
+//	      "identity-base": "ex:base",
+//	      "path": "../name",           // of a leafref
+//	      "require-instance": true,    // of a leafref or instance-identifier
+//	      "union": [ ... ],            // member types, without count and paths
+//	      "count": 2,                  // the number of leaves using the type
+//	      "paths": ["/example/a", "/example/b"]
+//	    }
+//	  ]
+//	}
+//
+// Members that do not apply to a type are omitted.  The types are sorted by
+// name, then module, then source and then paths, and the paths are sorted.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "types-json",
+		Format: doTypesJSON,
+		Help:   "display found types, and the leaves using them, as JSON",
+	})
+}
+
+// A typeObject is the JSON description of a type.
+type typeObject map[string]interface{}
+
+func doTypesJSON(w io.Writer, entries []*yang.Entry) {
+	uses := map[*yang.YangType][]string{}
+	for _, e := range entries {
+		addTypeUses(uses, e)
+	}
+
+	var types []typeObject
+	for t, paths := range uses {
+		o := typeJSON(t)
+		sort.Strings(paths)
+		o["count"] = len(paths)
+		o["paths"] = paths
+		types = append(types, o)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		a, b := types[i], types[j]
+		for _, k := range []string{"name", "module", "source", "paths"} {
+			if x, y := fmt.Sprint(a[k]), fmt.Sprint(b[k]); x != y {
+				return x < y
+			}
+		}
+		return false
+	})
+	if types == nil {
+		types = []typeObject{}
+	}
+
+	b, err := json.MarshalIndent(map[string]interface{}{"types": types}, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop(1)
+	}
+	fmt.Fprintf(w, "%s\n", b)
+}
+
+// addTypeUses adds the paths of e and its descendants to uses, keyed by the
+// root of their type.
+func addTypeUses(uses map[*yang.YangType][]string, e *yang.Entry) {
+	if e == nil {
+		return
+	}
+	if e.Type != nil {
+		t := e.Type.Root
+		if t == nil {
+			t = e.Type
+		}
+		uses[t] = append(uses[t], e.Path())
+	}
+	if e.RPC != nil {
+		addTypeUses(uses, e.RPC.Input)
+		addTypeUses(uses, e.RPC.Output)
+	}
+	for _, c := range e.Dir {
+		addTypeUses(uses, c)
+	}
+}
+
+// typeJSON returns the description of t, excluding its uses.
+func typeJSON(t *yang.YangType) typeObject {
+	o := typeObject{
+		"name": t.Name,
+		"kind": t.Kind.String(),
+	}
+	if _, builtin := yang.BaseTypedefs[t.Name]; !builtin && t.Base != nil {
+		if m := nodeModule(t.Base); m != "" {
+			o["module"] = m
+		}
+	}
+	if t.Base != nil {
+		if s := yang.Source(t.Base); s != "unknown" {
+			o["source"] = s
+		}
+	}
+	if t.Units != "" {
+		o["units"] = t.Units
+	}
+	if t.HasDefault {
+		o["default"] = t.Default
+	}
+	if b := yang.BaseTypedefs[t.Kind.String()]; len(t.Range) > 0 && (b == nil || !t.Range.Equal(b.YangType.Range)) {
+		o["range"] = t.Range.String()
+	}
+	if len(t.Length) > 0 {
+		o["length"] = t.Length.String()
+	}
+	if ps := patternExprs(t, false); len(ps) > 0 {
+		o["pattern"] = ps
+	}
+	if ps := patternExprs(t, true); len(ps) > 0 {
+		o["invert-match-pattern"] = ps
+	}
+	if t.FractionDigits != 0 {
+		o["fraction-digits"] = t.FractionDigits
+	}
+	switch t.Kind {
+	case yang.Yenum:
+		if t.Enum != nil {
+			o["enum"] = t.Enum.Names()
+		}
+	case yang.Ybits:
+		if t.Bit != nil {
+			o["bits"] = t.Bit.Names()
+		}
+	case yang.Yidentityref:
+		if t.IdentityBase != nil {
+			o["identity-base"] = nodeModule(t.IdentityBase) + ":" + t.IdentityBase.Name
+		}
+	case yang.Yleafref:
+		o["path"] = t.Path
+		o["require-instance"] = !t.OptionalInstance
+	case yang.YinstanceIdentifier:
+		o["require-instance"] = !t.OptionalInstance
+	case yang.Yunion:
+		members := []typeObject{}
+		for _, ut := range t.Type {
+			members = append(members, typeJSON(ut))
+		}
+		o["union"] = members
+	}
+	return o
+}
+
+// patternExprs returns the expressions of the patterns of t for which
+// InvertMatch is invert, in the order they were declared.
+func patternExprs(t *yang.YangType, invert bool) []string {
+	var exprs []string
+	for _, p := range t.Pattern {
+		if p.InvertMatch == invert {
+			exprs = append(exprs, p.Expr)
+		}
+	}
+	return exprs
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestTypesJSON(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "typedef used twice",
+		in: `
+  typedef percent {
+    type uint8 { range "0..100"; }
+    units "percent";
+    default "0";
+  }
+  leaf a { type percent; }
+  leaf b { type percent; }`,
+		want: `{
+  "types": [
+    {
+      "count": 2,
+      "default": "0",
+      "kind": "uint8",
+      "module": "m",
+      "name": "percent",
+      "paths": [
+        "/m/a",
+        "/m/b"
+      ],
+      "range": "0..100",
+      "source": "m.yang:6:5",
+      "units": "percent"
+    }
+  ]
+}
+`,
+	}, {
+		desc: "leafref",
+		in: `
+  leaf name { type string; }
+  leaf ref {
+    type leafref {
+      path "../name";
+      require-instance false;
+    }
+  }`,
+		want: `{
+  "types": [
+    {
+      "count": 1,
+      "kind": "leafref",
+      "name": "leafref",
+      "path": "../name",
+      "paths": [
+        "/m/ref"
+      ],
+      "require-instance": false
+    },
+    {
+      "count": 1,
+      "kind": "string",
+      "name": "string",
+      "paths": [
+        "/m/name"
+      ]
+    }
+  ]
+}
+`,
+	}, {
+		desc: "union",
+		in: `
+  leaf u {
+    type union {
+      type int8;
+      type enumeration { enum up; enum down; }
+    }
+  }`,
+		want: `{
+  "types": [
+    {
+      "count": 1,
+      "kind": "union",
+      "name": "union",
+      "paths": [
+        "/m/u"
+      ],
+      "union": [
+        {
+          "kind": "int8",
+          "name": "int8"
+        },
+        {
+          "enum": [
+            "down",
+            "up"
+          ],
+          "kind": "enumeration",
+          "name": "enumeration"
+        }
+      ]
+    }
+  ]
+}
+`,
+	}, {
+		desc: "no leaves",
+		in: `
+  container c { }`,
+		want: `{
+  "types": []
+}
+`,
+	}} {
+		ms := yang.NewModules()
+		if err := ms.Parse(`module m {
+  prefix "m";
+  namespace "urn:m";
+`+tt.in+`
+}`, "m.yang"); err != nil {
+			t.Fatalf("%s: cannot parse module: %v", tt.desc, err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("%s: cannot process module: %v", tt.desc, errs)
+		}
+		var buf bytes.Buffer
+		doTypesJSON(&buf, []*yang.Entry{yang.ToEntry(ms.Modules["m"])})
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.desc, got, tt.want)
+		}
+	}
+}
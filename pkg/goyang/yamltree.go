@@ -0,0 +1,233 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+// The yamltree format writes the schema tree as a YAML document.  Each node
+// is a mapping from its name to its attributes, such as its kind, type and
+// keys, with its children in the "children" attribute.  All mappings are
+// sorted by key so the output is stable.
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/format"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func init() {
+	format.Register(&format.Formatter{
+		Name:   "yamltree",
+		Format: doYAMLTree,
+		Help:   "display the schema tree as a YAML document",
+	})
+}
+
+// A yamlMap is a YAML mapping.  Values are strings, bools, numbers, slices of
+// strings or yamlMaps.
+type yamlMap map[string]interface{}
+
+func doYAMLTree(w io.Writer, entries []*yang.Entry) {
+	doc := yamlMap{}
+	for _, e := range entries {
+		doc[e.Name] = yamlNode(e)
+	}
+	writeYAML(w, doc, "")
+}
+
+// yamlNode returns the attributes of e as a yamlMap.
+func yamlNode(e *yang.Entry) yamlMap {
+	m := yamlMap{}
+	switch {
+	case e.IsLeafList():
+		// Leaf-lists are represented by a *yang.Leaf node.
+		m["kind"] = "leaf-list"
+	case e.Node != nil:
+		m["kind"] = e.Node.Kind()
+	}
+	if e.Parent != nil && isDataNode(e) {
+		m["config"] = !e.ReadOnly()
+	}
+	if e.Type != nil {
+		m["type"] = yamlType(e.Type)
+	}
+	if e.Key != "" {
+		m["key"] = e.Key
+	}
+	if e.Mandatory == yang.TSTrue {
+		m["mandatory"] = true
+	}
+	if u := e.EffectiveUnits(); u != "" {
+		m["units"] = u
+	}
+	switch {
+	case e.IsLeafList():
+		m["default"] = e.Default
+	case len(e.Default) > 0:
+		m["default"] = e.Default[0]
+	}
+	if d, ok := m["default"].([]string); ok && len(d) == 0 {
+		delete(m, "default")
+	}
+	if la := e.ListAttr; la != nil {
+		if la.MinElements > 0 {
+			m["min-elements"] = la.MinElements
+		}
+		if la.MaxElements != math.MaxUint64 {
+			m["max-elements"] = la.MaxElements
+		}
+	}
+
+	children := yamlMap{}
+	if e.RPC != nil {
+		if e.RPC.Input != nil {
+			children["input"] = yamlNode(e.RPC.Input)
+		}
+		if e.RPC.Output != nil {
+			children["output"] = yamlNode(e.RPC.Output)
+		}
+	}
+	for name, c := range e.Dir {
+		children[name] = yamlNode(c)
+	}
+	if len(children) > 0 {
+		m["children"] = children
+	}
+	return m
+}
+
+// yamlType returns the name of t if it has no restrictions, otherwise a
+// yamlMap describing t.
+func yamlType(t *yang.YangType) interface{} {
+	m := yamlMap{}
+	base := yang.BaseTypedefs[t.Kind.String()]
+	if t.Name != t.Kind.String() {
+		m["base"] = t.Kind.String()
+	}
+	if len(t.Range) > 0 && (base == nil || !t.Range.Equal(base.YangType.Range)) {
+		m["range"] = t.Range.String()
+	}
+	if len(t.Length) > 0 {
+		m["length"] = t.Length.String()
+	}
+	if ps := patternExprs(t, false); len(ps) > 0 {
+		m["pattern"] = ps
+	}
+	if ps := patternExprs(t, true); len(ps) > 0 {
+		m["invert-match-pattern"] = ps
+	}
+	if t.FractionDigits != 0 {
+		m["fraction-digits"] = t.FractionDigits
+	}
+	if t.Path != "" {
+		m["path"] = t.Path
+	}
+	switch t.Kind {
+	case yang.Yenum:
+		if t.Enum != nil {
+			m["enum"] = t.Enum.Names()
+		}
+	case yang.Ybits:
+		if t.Bit != nil {
+			m["bits"] = t.Bit.Names()
+		}
+	case yang.Yidentityref:
+		if t.IdentityBase != nil {
+			m["identity-base"] = t.IdentityBase.Name
+		}
+	case yang.Yunion:
+		var members []string
+		for _, ut := range t.Type {
+			members = append(members, ut.Name)
+		}
+		m["union"] = members
+	}
+	if len(m) == 0 {
+		return t.Name
+	}
+	m["name"] = t.Name
+	return m
+}
+
+// writeYAML writes the mapping m to w with each line prefixed by prefix.
+func writeYAML(w io.Writer, m yamlMap, prefix string) {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := yamlScalar(k)
+		switch v := m[k].(type) {
+		case yamlMap:
+			if len(v) == 0 {
+				fmt.Fprintf(w, "%s%s: {}\n", prefix, key)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:\n", prefix, key)
+			writeYAML(w, v, prefix+"  ")
+		case []string:
+			if len(v) == 0 {
+				fmt.Fprintf(w, "%s%s: []\n", prefix, key)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:\n", prefix, key)
+			for _, s := range v {
+				fmt.Fprintf(w, "%s- %s\n", prefix+"  ", yamlScalar(s))
+			}
+		case string:
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, key, yamlScalar(v))
+		default:
+			fmt.Fprintf(w, "%s%s: %v\n", prefix, key, v)
+		}
+	}
+}
+
+// yamlPlain matches strings that can be written as plain YAML scalars.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*$`)
+
+// yamlReserved are the plain scalars that YAML parsers may read as something
+// other than a string.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true,
+	"off": true, "y": true, "n": true, "null": true,
+}
+
+// yamlScalar returns s as a YAML scalar, double quoted unless it can be read
+// back as the same string without quotes.
+func yamlScalar(s string) string {
+	if yamlPlain.MatchString(s) && !yamlReserved[strings.ToLower(s)] {
+		return s
+	}
+	// The escapes used by Go are also valid in YAML double quoted scalars.
+	return strconv.Quote(s)
+}
+
+// isDataNode reports whether e is part of a data tree rather than the input or
+// output of an operation or a notification, which have no config state.
+func isDataNode(e *yang.Entry) bool {
+	for ; e != nil; e = e.Parent {
+		switch {
+		case e.RPC != nil, e.Kind == yang.InputEntry, e.Kind == yang.OutputEntry, e.Kind == yang.NotificationEntry:
+			return false
+		}
+	}
+	return true
+}
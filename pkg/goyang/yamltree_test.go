@@ -0,0 +1,138 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goyang
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+func TestYAMLTree(t *testing.T) {
+	for _, tt := range []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "leaves",
+		in: `
+  leaf hostname {
+    type string { length "1..64"; }
+    default "on";
+  }
+  leaf-list dns { type string; max-elements 3; }`,
+		want: `m:
+  children:
+    dns:
+      config: true
+      kind: leaf-list
+      max-elements: 3
+      type: string
+    hostname:
+      config: true
+      default: "on"
+      kind: leaf
+      type:
+        length: "1..64"
+        name: string
+  kind: module
+`,
+	}, {
+		desc: "list and state",
+		in: `
+  list server {
+    key "name";
+    leaf name { type string; }
+    leaf mode {
+      type enumeration { enum active; enum standby; }
+      mandatory true;
+    }
+  }
+  container state {
+    config false;
+    leaf uptime { type uint64; units "seconds"; }
+  }`,
+		want: `m:
+  children:
+    server:
+      children:
+        mode:
+          config: true
+          kind: leaf
+          mandatory: true
+          type:
+            enum:
+              - active
+              - standby
+            name: enumeration
+        name:
+          config: true
+          kind: leaf
+          type: string
+      config: true
+      key: name
+      kind: list
+    state:
+      children:
+        uptime:
+          config: false
+          kind: leaf
+          type: uint64
+          units: seconds
+      config: false
+      kind: container
+  kind: module
+`,
+	}, {
+		desc: "rpc",
+		in: `
+  rpc reboot {
+    input {
+      leaf delay { type uint32; }
+    }
+  }`,
+		want: `m:
+  children:
+    reboot:
+      children:
+        input:
+          children:
+            delay:
+              kind: leaf
+              type: uint32
+          kind: input
+      kind: rpc
+  kind: module
+`,
+	}} {
+		ms := yang.NewModules()
+		if err := ms.Parse(`module m {
+  prefix "m";
+  namespace "urn:m";
+`+tt.in+`
+}`, "m.yang"); err != nil {
+			t.Fatalf("%s: cannot parse module: %v", tt.desc, err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("%s: cannot process module: %v", tt.desc, errs)
+		}
+		var buf bytes.Buffer
+		doYAMLTree(&buf, []*yang.Entry{yang.ToEntry(ms.Modules["m"])})
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.desc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the ordering of augments so that an augment whose
+// target is added by another augment is applied after it.
+
+import "strings"
+
+// applyAugments applies the augments of mods in an order in which each
+// augment comes after those that add its target or one of the target's
+// ancestors.  Independent augments are applied in the order of mods and
+// then in the order they were declared, so augments of the same target are
+// always merged in the same order.  The augments whose target is not found
+// are left in the Augments of their module's Entry.
+func applyAugments(mods []*Module) {
+	var augments []*Entry
+	for _, m := range mods {
+		augments = append(augments, ToEntry(m).Augments...)
+	}
+	applied := map[*Entry]bool{}
+	for _, a := range augmentOrder(augments) {
+		applied[a] = a.applyAugment()
+	}
+	for _, m := range mods {
+		e := ToEntry(m)
+		var unapplied []*Entry
+		for _, a := range e.Augments {
+			if !applied[a] {
+				unapplied = append(unapplied, a)
+			}
+		}
+		e.Augments = unapplied
+	}
+}
+
+// augmentOrder returns augments ordered so that each augment comes after
+// the augments it depends on, i.e., those that add its target or an
+// ancestor of its target, but otherwise in the order given.
+//
+// The augments cannot depend on one another in a cycle: the target of an
+// augment is beneath a node added by each augment it depends on, and so has
+// a longer path than the target of that augment.
+func augmentOrder(augments []*Entry) []*Entry {
+	// added maps the key of each node added by an augment to the
+	// augments that add it.
+	added := map[string][]*Entry{}
+	for _, a := range augments {
+		if key := augmentTargetKey(a); key != "" {
+			for name := range a.Dir {
+				added[key+"/"+name] = append(added[key+"/"+name], a)
+			}
+		}
+	}
+
+	var order []*Entry
+	seen := map[*Entry]bool{}
+	var visit func(a *Entry)
+	visit = func(a *Entry) {
+		if seen[a] {
+			return
+		}
+		seen[a] = true
+		// Visit the augments adding the target or its ancestors.
+		key := augmentTargetKey(a)
+		for end := strings.Index(key, "/") + 1; key != "" && end <= len(key); end++ {
+			if end < len(key) && key[end] != '/' {
+				continue
+			}
+			for _, d := range added[key[:end]] {
+				visit(d)
+			}
+		}
+		order = append(order, a)
+	}
+	for _, a := range augments {
+		visit(a)
+	}
+	return order
+}
+
+// augmentTargetKey returns a key identifying the target of the augment a,
+// formed from the name of the module of the top level node of the target
+// followed by the names of the nodes in its path, without prefixes, e.g.,
+// "mod:/a/b".  Within a module, as Find does, nodes are identified by their
+// names only.  The empty string is returned if the target is not an
+// absolute path or its module is not known.
+func augmentTargetKey(a *Entry) string {
+	if !strings.HasPrefix(a.Name, "/") || a.Node == nil {
+		return ""
+	}
+	parts := strings.Split(a.Name[1:], "/")
+	prefix, _ := getPrefix(parts[0])
+	m := FindModuleByPrefix(a.Node, prefix)
+	if m == nil {
+		return ""
+	}
+	if m = module(m); m == nil {
+		return ""
+	}
+	key := m.Name + ":"
+	for _, part := range parts {
+		_, name := getPrefix(part)
+		key += "/" + name
+	}
+	return key
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChainedAugments(t *testing.T) {
+	// The modules are processed in order of name, and the augments of
+	// each in the order they are declared, so each augment here is seen
+	// before the augment adding its target.
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a.yang": `
+			module a {
+				prefix "a";
+				namespace "urn:a";
+				import b { prefix b; }
+				import c { prefix c; }
+				augment "/c:top/b:mid/b:inner" { leaf x { type string; } }
+				augment "/c:top/b:mid/b:inner/a:deep" { leaf y { type string; } }
+				augment "/c:top/b:mid/b:inner" { container deep { } }
+			}`,
+		"b.yang": `
+			module b {
+				prefix "b";
+				namespace "urn:b";
+				import c { prefix c; }
+				augment "/c:top/b:mid" { container inner { } }
+				augment "/c:top" { container mid { } }
+			}`,
+		"c.yang": `
+			module c {
+				prefix "c";
+				namespace "urn:c";
+				container top { }
+			}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	top := ToEntry(ms.Modules["c"]).Dir["top"]
+	for _, tt := range []struct {
+		path   string
+		module string
+	}{
+		{"mid", "b"},
+		{"mid/inner", "b"},
+		{"mid/inner/x", "a"},
+		{"mid/inner/deep", "a"},
+		{"mid/inner/deep/y", "a"},
+	} {
+		e := top.Find(tt.path)
+		if e == nil {
+			t.Errorf("%s not found", tt.path)
+			continue
+		}
+		if got, err := e.InstantiatingModule(); err != nil || got != tt.module {
+			t.Errorf("%s: got module %q, %v, want %q", tt.path, got, err, tt.module)
+		}
+	}
+
+	var augmented []string
+	for _, a := range top.Find("mid/inner").Augmented {
+		for name := range a.Dir {
+			augmented = append(augmented, name)
+		}
+	}
+	if diff := cmp.Diff([]string{"x", "deep"}, augmented); diff != "" {
+		t.Errorf("augments of /c:top/mid/inner (-want, +got):\n%s", diff)
+	}
+}
+
+func TestAugmentOrderMissingTarget(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			container top { }
+			augment "/m:top/m:added/m:missing" { leaf x { type string; } }
+			augment "/m:top" { container added { } }
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) != 1 || errs[0].Error() != "m.yang:6:4: augment /m:top/m:added/m:missing not found" {
+		t.Errorf("Process: got %v, want the augment of /m:top/m:added/m:missing not found", errs)
+	}
+	if e := ToEntry(ms.Modules["m"]).Find("/m:top/added"); e == nil {
+		t.Errorf("/m:top/added not found")
+	}
+}
+
+func TestAugmentOperation(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"base.yang": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+				rpc explicit {
+					input { leaf x { type string; } }
+				}
+				rpc implicit;
+				container c {
+					action a;
+				}
+			}`,
+		"ext.yang": `
+			module ext {
+				prefix "e";
+				namespace "urn:e";
+				import base { prefix b; }
+				augment "/b:explicit/b:input" { leaf y { type string; } }
+				augment "/b:implicit/b:input" { leaf y { type string; } }
+				augment "/b:implicit/b:output" { leaf z { type string; } }
+				augment "/b:c/b:a/b:output" { leaf z { type string; } }
+			}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	base := ToEntry(ms.Modules["base"])
+	for _, tt := range []struct {
+		path   string
+		kind   EntryKind
+		module string
+	}{
+		{"/b:explicit/input/x", LeafEntry, "base"},
+		{"/b:explicit/input/y", LeafEntry, "ext"},
+		{"/b:implicit/input", InputEntry, "base"},
+		{"/b:implicit/input/y", LeafEntry, "ext"},
+		{"/b:implicit/output", OutputEntry, "base"},
+		{"/b:implicit/output/z", LeafEntry, "ext"},
+		{"/b:c/a/output/z", LeafEntry, "ext"},
+	} {
+		e := base.Find(tt.path)
+		if e == nil {
+			t.Errorf("%s not found", tt.path)
+			continue
+		}
+		if e.Kind != tt.kind {
+			t.Errorf("%s: got kind %v, want %v", tt.path, e.Kind, tt.kind)
+		}
+		if got, err := e.InstantiatingModule(); err != nil || got != tt.module {
+			t.Errorf("%s: got module %q, %v, want %q", tt.path, got, err, tt.module)
+		}
+	}
+	if !base.Find("/b:implicit/output/z").ReadOnly() {
+		t.Errorf("/b:implicit/output/z is not read-only")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements a parse-only cache of a set of modules that can be
+// saved by one program and loaded by another without finding, reading and
+// parsing the original files again.
+//
+// An Entry refers to the AST node it was derived from, and most of what can
+// be asked of an Entry (its namespace, its source, the module instantiating
+// it, resolving paths relative to it) is answered from that node.  Rather
+// than serializing the Entry and AST graphs, the cache holds the statement
+// trees the ASTs are built from, which ImportParsed rebuilds.  The time taken
+// to search for, read and parse the files is saved, but the imported modules
+// must still be processed by Process.
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// CacheVersion identifies the format of the data written by ExportParsed.  It
+// is changed whenever the format, or the interpretation of the data by
+// ImportParsed, changes.
+const CacheVersion = "goyang-cache-4"
+
+// ErrCacheVersion is returned by ImportParsed when its input was written
+// with a CacheVersion other than the current one.  The cache is stale and
+// the modules should be read from their files again.
+var ErrCacheVersion = errors.New("cache version mismatch")
+
+// A cacheFile is the data written by ExportParsed.
+type cacheFile struct {
+	Version         string
+	ParseOptions    Options
+	DuplicatePolicy DuplicatePolicy
+	Path            []string
+	Modules         []*cacheStatement // modules, then submodules
+	Files           []string          // the file each of Modules was read from
+}
+
+// A cacheStatement is the serialized form of a Statement.
+type cacheStatement struct {
+	Keyword     string
+	HasArgument bool
+	Argument    string
+	File        string
+	Line        int
+	Col         int
+	Comments    []string
+	EndComments []string
+	Raw         string
+	Offset      int
+	Statements  []*cacheStatement
+}
+
+// ExportParsed writes the parsed modules and submodules in ms, along with its
+// ParseOptions, DuplicatePolicy, Path and the files the modules were read
+// from, to w in a form that can be read by ImportParsed.  Only the result of
+// parsing is written, not that of Process.  ExportParsed does not change ms.
+// The data written is only meaningful to ImportParsed from a version of this
+// package with the same CacheVersion.
+func (ms *Modules) ExportParsed(w io.Writer) error {
+	c := cacheFile{
+		Version:         CacheVersion,
+		ParseOptions:    ms.ParseOptions,
+		DuplicatePolicy: ms.DuplicatePolicy,
+		Path:            ms.Path,
+	}
+	// Each module is found under both its name and its name@revision,
+	// so sortedModules may return it more than once.
+	seen := map[*Module]bool{}
+	for _, m := range append(sortedModules(ms.Modules), sortedModules(ms.SubModules)...) {
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		if m.Source == nil {
+			return fmt.Errorf("cannot export %s %s: no source statement", m.Kind(), m.Name)
+		}
+		c.Modules = append(c.Modules, toCacheStatement(m.Source))
+		c.Files = append(c.Files, ms.files[m])
+	}
+	return gob.NewEncoder(w).Encode(&c)
+}
+
+// ImportParsed returns the modules written to r by ExportParsed, as they were
+// after being read and before being processed.  The caller must call Process
+// before using the modules.  An error wrapping ErrCacheVersion is returned if
+// r was written with a different CacheVersion.
+func ImportParsed(r io.Reader) (*Modules, error) {
+	var c cacheFile
+	if err := gob.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("reading cache: %v", err)
+	}
+	if c.Version != CacheVersion {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrCacheVersion, c.Version, CacheVersion)
+	}
+
+	ms := NewModules()
+	ms.ParseOptions = c.ParseOptions
+	ms.DuplicatePolicy = c.DuplicatePolicy
+	ms.AddPath(c.Path...)
+	for i, cs := range c.Modules {
+		n, err := buildASTWithTypeDict(fromCacheStatement(cs), ms.typeDict)
+		if err != nil {
+			return nil, err
+		}
+		if err := ms.add(n); err != nil {
+			return nil, err
+		}
+		if i < len(c.Files) && c.Files[i] != "" {
+			ms.files[n.(*Module)] = c.Files[i]
+		}
+	}
+	return ms, nil
+}
+
+// toCacheStatement returns the serialized form of s.
+func toCacheStatement(s *Statement) *cacheStatement {
+	cs := &cacheStatement{
+		Keyword:     s.Keyword,
+		HasArgument: s.HasArgument,
+		Argument:    s.Argument,
+		File:        s.file,
+		Line:        s.line,
+		Col:         s.col,
+		Comments:    s.Comments,
+		EndComments: s.EndComments,
+		Raw:         s.raw,
+		Offset:      s.offset,
+	}
+	for _, ss := range s.statements {
+		cs.Statements = append(cs.Statements, toCacheStatement(ss))
+	}
+	return cs
+}
+
+// fromCacheStatement returns the Statement serialized as cs.
+func fromCacheStatement(cs *cacheStatement) *Statement {
+	s := &Statement{
+		Keyword:     cs.Keyword,
+		HasArgument: cs.HasArgument,
+		Argument:    cs.Argument,
+		file:        cs.File,
+		line:        cs.Line,
+		col:         cs.Col,
+		Comments:    cs.Comments,
+		EndComments: cs.EndComments,
+		raw:         cs.Raw,
+		offset:      cs.Offset,
+	}
+	for _, css := range cs.Statements {
+		s.statements = append(s.statements, fromCacheStatement(css))
+	}
+	return s
+}
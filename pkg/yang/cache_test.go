@@ -0,0 +1,196 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestExportImport(t *testing.T) {
+	ms := NewModules()
+	ms.ParseOptions.StoreUses = true
+	ms.AddPath("testdata")
+	for name, text := range map[string]string{
+		"base": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+
+				include base-sub;
+
+				typedef percent {
+					type uint8 { range "0..100"; }
+					units "percent";
+				}
+				grouping g {
+					leaf load { type percent; }
+				}
+				container system {
+					uses g;
+					leaf mode { type mode; default "auto"; }
+				}
+			}`,
+		"base-sub": `
+			submodule base-sub {
+				belongs-to base { prefix "b"; }
+
+				typedef mode {
+					type enumeration { enum auto; enum manual; }
+				}
+			}`,
+		"aug": `
+			module aug {
+				prefix "a";
+				namespace "urn:a";
+
+				import base { prefix b; }
+
+				augment /b:system {
+					leaf extra { type string; }
+				}
+			}`,
+	} {
+		if err := ms.parse(text, name+".yang", "dir/"+name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	var buf bytes.Buffer
+	if err := ms.ExportParsed(&buf); err != nil {
+		t.Fatalf("ExportParsed: %v", err)
+	}
+	got, err := ImportParsed(&buf)
+	if err != nil {
+		t.Fatalf("ImportParsed: %v", err)
+	}
+	if errs := got.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process imported modules: %v", errs)
+	}
+
+	if diff := cmp.Diff(ms.ParseOptions, got.ParseOptions); diff != "" {
+		t.Errorf("ParseOptions (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ms.Path, got.Path); diff != "" {
+		t.Errorf("Path (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(ms.moduleNames(), got.moduleNames()); diff != "" {
+		t.Errorf("modules (-want, +got):\n%s", diff)
+	}
+	if got.SubModules["base-sub"] == nil {
+		t.Errorf("submodule base-sub not imported")
+	}
+	if diff := cmp.Diff(ms.SourceFiles(), got.SourceFiles()); diff != "" {
+		t.Errorf("SourceFiles (-want, +got):\n%s", diff)
+	}
+
+	// leaf summarizes an Entry in a way that can be compared between the
+	// two sets of modules.
+	type leaf struct {
+		Namespace string
+		Source    string
+		Type      string
+		Units     string
+		Default   string
+	}
+	summarize := func(ms *Modules) map[string]leaf {
+		m := map[string]leaf{}
+		for _, name := range ms.moduleNames() {
+			e := ToEntry(ms.Modules[name])
+			for _, p := range e.LeafPaths() {
+				l, err := ms.ResolvePath(p)
+				if err != nil {
+					t.Errorf("ResolvePath(%q): %v", p, err)
+					continue
+				}
+				d, _ := l.SingleDefaultValue()
+				m[p] = leaf{
+					Namespace: l.Namespace().Name,
+					Source:    Source(l.Node),
+					Type:      l.Type.Name,
+					Units:     l.EffectiveUnits(),
+					Default:   d,
+				}
+			}
+		}
+		return m
+	}
+	want := summarize(ms)
+	if len(want) != 3 {
+		t.Fatalf("got %d leaves in the original modules, want 3: %v", len(want), want)
+	}
+	if diff := cmp.Diff(want, summarize(got)); diff != "" {
+		t.Errorf("imported leaves (-want, +got):\n%s", diff)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	encode := func(c cacheFile) *bytes.Buffer {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(&c); err != nil {
+			t.Fatalf("cannot encode cache: %v", err)
+		}
+		return &buf
+	}
+
+	tests := []struct {
+		desc             string
+		in               *bytes.Buffer
+		wantErrSubstring string
+	}{{
+		desc:             "not a cache",
+		in:               bytes.NewBufferString("module foo {}"),
+		wantErrSubstring: "reading cache",
+	}, {
+		desc:             "stale version",
+		in:               encode(cacheFile{Version: "goyang-cache-0"}),
+		wantErrSubstring: `cache version mismatch: got "goyang-cache-0"`,
+	}, {
+		desc: "not a module",
+		in: encode(cacheFile{
+			Version: CacheVersion,
+			Modules: []*cacheStatement{{
+				Keyword:     "leaf",
+				HasArgument: true,
+				Argument:    "bad",
+				Statements: []*cacheStatement{
+					{Keyword: "type", HasArgument: true, Argument: "string"},
+				},
+			}},
+		}),
+		wantErrSubstring: "not a module or submodule",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ImportParsed(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("ImportParsed: %s", diff)
+			}
+		})
+	}
+
+	if _, err := ImportParsed(encode(cacheFile{Version: "old"})); !errors.Is(err, ErrCacheVersion) {
+		t.Errorf("ImportParsed of a stale cache: got error %v, want ErrCacheVersion", err)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the comparison of two revisions of a schema, reporting
+// the changes that are not backwards compatible as described in RFC7950
+// section 11.
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A SchemaChange is a difference between two revisions of a schema.
+type SchemaChange struct {
+	Path     string // the path, as returned by Entry.Path, of the changed node
+	Breaking bool   // whether the change is not backwards compatible
+	Message  string // a description of the change
+}
+
+// String returns c as "path: message", marking breaking changes.
+func (c SchemaChange) String() string {
+	if c.Breaking {
+		return fmt.Sprintf("%s: %s (breaking)", c.Path, c.Message)
+	}
+	return fmt.Sprintf("%s: %s", c.Path, c.Message)
+}
+
+// CompareEntries returns the changes from old to new, two revisions of the
+// same node, to the nodes beneath them, sorted by path.  Nodes are matched by
+// name, and the input and output of RPCs and actions are compared as their
+// children.
+//
+// Removing a node, or changing its kind, is breaking.  Adding a node is not,
+// unless the node is mandatory and is data a client provides, i.e.,
+// configuration or the input of an operation, and its parent already existed
+// in old: a client of old does not set it.  A mandatory node added beneath a
+// new list or presence container is not breaking, as no existing data
+// contains the new node's parent.  A non-presence container exists whenever
+// its parent does, so a new one is mandatory if any of its children are.
+// Making an existing node mandatory, or raising the min-elements of a list or
+// leaf-list, is breaking for the same data.  Changes to the type of a leaf or
+// leaf-list are reported as by CompareTypes.
+func CompareEntries(old, new *Entry) []SchemaChange {
+	var changes []SchemaChange
+	compareChildren(&changes, old, new)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// compareChildren appends the changes to the children of old and new, and to
+// their descendants, to changes.
+func compareChildren(changes *[]SchemaChange, old, new *Entry) {
+	oc, nc := compareChildMap(old), compareChildMap(new)
+	var names []string
+	for name := range oc {
+		names = append(names, name)
+	}
+	for name := range nc {
+		if oc[name] == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	add := func(e *Entry, breaking bool, format string, args ...interface{}) {
+		*changes = append(*changes, SchemaChange{
+			Path:     e.Path(),
+			Breaking: breaking,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	for _, name := range names {
+		o, n := oc[name], nc[name]
+		switch {
+		case n == nil:
+			add(o, true, "%s removed", compareKind(o))
+		case o == nil:
+			if n.isClientData() && n.isMandatoryNode() {
+				add(n, true, "mandatory %s added", compareKind(n))
+			} else {
+				add(n, false, "%s added", compareKind(n))
+			}
+		case compareKind(o) != compareKind(n):
+			add(n, true, "changed from %s to %s", compareKind(o), compareKind(n))
+		default:
+			if n.isClientData() {
+				switch {
+				case (n.IsList() || n.IsLeafList()) && o.ListAttr != nil && n.ListAttr != nil && o.ListAttr.MinElements < n.ListAttr.MinElements:
+					add(n, true, "min-elements raised from %d to %d", o.ListAttr.MinElements, n.ListAttr.MinElements)
+				case n.Mandatory == TSTrue && o.Mandatory != TSTrue:
+					add(n, true, "%s made mandatory", compareKind(n))
+				}
+			}
+			if o.Type != nil && n.Type != nil {
+				for _, c := range CompareTypes(o.Type, n.Type) {
+					c.Path = n.Path()
+					*changes = append(*changes, c)
+				}
+			}
+			compareChildren(changes, o, n)
+		}
+	}
+}
+
+// CompareTypes returns the changes from old to new, two revisions of the type
+// of a leaf or leaf-list.  The Path of each change is empty.  Changes that
+// narrow the values of the type are breaking, as existing values may no
+// longer be valid, while those that only widen it are not (RFC7950 section
+// 11): shrinking a range or length, adding a pattern, removing an enum or bit
+// or changing its value or position, removing a member type of a union, and
+// requiring an instance are breaking, and the reverse changes are not.
+// Changing the built-in type, fraction-digits, the base of an identityref or
+// the path of a leafref, i.e., its target, is breaking.  The member types of
+// unions are compared in order.
+func CompareTypes(old, new *YangType) []SchemaChange {
+	var changes []SchemaChange
+	add := func(breaking bool, format string, args ...interface{}) {
+		changes = append(changes, SchemaChange{
+			Breaking: breaking,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	if old == nil || new == nil {
+		return nil
+	}
+	if old.Kind != new.Kind {
+		add(true, "type changed from %s to %s", old.Kind, new.Kind)
+		return changes
+	}
+
+	compareRange := func(what string, o, n YangRange) {
+		o, n = coalescedRange(o), coalescedRange(n)
+		switch narrowed, widened := !n.Contains(o), !o.Contains(n); {
+		case narrowed && widened:
+			add(true, "%s changed from %s to %s", what, o, n)
+		case narrowed:
+			add(true, "%s narrowed from %s to %s", what, o, n)
+		case widened:
+			add(false, "%s widened from %s to %s", what, o, n)
+		}
+	}
+	if old.FractionDigits != new.FractionDigits {
+		add(true, "fraction-digits changed from %d to %d", old.FractionDigits, new.FractionDigits)
+	} else {
+		compareRange("range", old.Range, new.Range)
+	}
+	// A type without a length is not restricted, i.e., is of any length.
+	oldLength, newLength := old.Length, new.Length
+	if len(oldLength) == 0 {
+		oldLength = Uint64Range
+	}
+	if len(newLength) == 0 {
+		newLength = Uint64Range
+	}
+	compareRange("length", oldLength, newLength)
+
+	comparePatterns := func(what string, o, n []string) {
+		oset, nset := map[string]bool{}, map[string]bool{}
+		for _, p := range o {
+			oset[p] = true
+		}
+		for _, p := range n {
+			nset[p] = true
+		}
+		for _, p := range n {
+			if !oset[p] {
+				add(true, "%s %s added", what, p)
+			}
+		}
+		for _, p := range o {
+			if !nset[p] {
+				add(false, "%s %s removed", what, p)
+			}
+		}
+	}
+	comparePatterns("pattern", patternKeys(old.Pattern), patternKeys(new.Pattern))
+	comparePatterns("posix-pattern", quoteAll(old.POSIXPattern), quoteAll(new.POSIXPattern))
+
+	compareMembers := func(what, value string, o, n *EnumType) {
+		if o == nil || n == nil {
+			return
+		}
+		om, nm := o.NameMap(), n.NameMap()
+		for _, name := range o.Names() {
+			switch nv, ok := nm[name]; {
+			case !ok:
+				add(true, "%s %s removed", what, name)
+			case nv != om[name]:
+				add(true, "%s of %s %s changed from %d to %d", value, what, name, om[name], nv)
+			}
+		}
+		for _, name := range n.Names() {
+			if _, ok := om[name]; !ok {
+				add(false, "%s %s added", what, name)
+			}
+		}
+	}
+	compareMembers("enum", "value", old.Enum, new.Enum)
+	compareMembers("bit", "position", old.Bit, new.Bit)
+
+	if old.Path != new.Path {
+		add(true, "leafref path changed from %q to %q", old.Path, new.Path)
+	}
+	if (old.Kind == Yleafref || old.Kind == YinstanceIdentifier) && old.OptionalInstance != new.OptionalInstance {
+		add(old.OptionalInstance, "require-instance changed from %t to %t", !old.OptionalInstance, !new.OptionalInstance)
+	}
+	if old.IdentityBase != nil && new.IdentityBase != nil {
+		// The revisions have different Identities, so the bases are
+		// compared by name.
+		if ob, nb := old.IdentityBase.modulePrefixedName(), new.IdentityBase.modulePrefixedName(); ob != nb {
+			add(true, "identityref base changed from %s to %s", ob, nb)
+		}
+	}
+
+	for i, ot := range old.Type {
+		if i >= len(new.Type) {
+			add(true, "union member %d (%s) removed", i+1, ot.DisplayName())
+			continue
+		}
+		for _, c := range CompareTypes(ot, new.Type[i]) {
+			c.Message = fmt.Sprintf("union member %d: %s", i+1, c.Message)
+			changes = append(changes, c)
+		}
+	}
+	for i := len(old.Type); i < len(new.Type); i++ {
+		add(false, "union member %d (%s) added", i+1, new.Type[i].DisplayName())
+	}
+	return changes
+}
+
+// coalescedRange returns r sorted and coalesced, so it may be compared
+// using Contains.  r is not changed.
+func coalescedRange(r YangRange) YangRange {
+	c := append(YangRange{}, r...)
+	c.Sort()
+	return coalesce(c)
+}
+
+// patternKeys returns the expressions of ps quoted, followed by
+// " (invert-match)" for those that values must not match.
+func patternKeys(ps []*YangPattern) []string {
+	var keys []string
+	for _, p := range ps {
+		key := fmt.Sprintf("%q", p.Expr)
+		if p.InvertMatch {
+			key += " (invert-match)"
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// quoteAll returns the strings of ss quoted.
+func quoteAll(ss []string) []string {
+	var q []string
+	for _, s := range ss {
+		q = append(q, fmt.Sprintf("%q", s))
+	}
+	return q
+}
+
+// compareChildMap returns the children of e, including the input and output
+// of e if it is an RPC or action, by name.
+func compareChildMap(e *Entry) map[string]*Entry {
+	m := make(map[string]*Entry, len(e.Dir)+2)
+	for name, c := range e.Dir {
+		m[name] = c
+	}
+	if e.RPC != nil {
+		if e.RPC.Input != nil {
+			m["input"] = e.RPC.Input
+		}
+		if e.RPC.Output != nil {
+			m["output"] = e.RPC.Output
+		}
+	}
+	return m
+}
+
+// compareKind returns the keyword that defines e, e.g., "leaf" or "list".
+func compareKind(e *Entry) string {
+	switch {
+	case e.IsChoice():
+		return "choice"
+	case e.IsCase():
+		return "case"
+	case e.Kind == InputEntry:
+		return "input"
+	case e.Kind == OutputEntry:
+		return "output"
+	case e.IsLeafList():
+		// A leaf-list entry is built from an equivalent Leaf node.
+		return "leaf-list"
+	case e.Node != nil:
+		return e.Node.Kind()
+	}
+	return e.Kind.String()
+}
+
+// isClientData reports whether e is data a client provides, i.e.,
+// configuration or the input of an RPC or action, rather than state data, the
+// output of an operation or a notification.
+func (e *Entry) isClientData() bool {
+	for p := e; p != nil; p = p.Parent {
+		switch p.Kind {
+		case InputEntry:
+			return true
+		case OutputEntry, NotificationEntry:
+			return false
+		}
+	}
+	return !e.ReadOnly()
+}
+
+// isMandatoryNode reports whether e is a mandatory node as defined in
+// RFC7950 section 3: a mandatory leaf, choice, anydata or anyxml, a list or
+// leaf-list with a min-elements greater than zero, or a non-presence
+// container or operation input with a mandatory child.
+func (e *Entry) isMandatoryNode() bool {
+	switch {
+	case e.IsList(), e.IsLeafList():
+		return e.ListAttr != nil && e.ListAttr.MinElements > 0
+	case e.IsContainer(), e.Kind == InputEntry:
+		if e.isPresence() {
+			return false
+		}
+		for _, c := range e.Dir {
+			if c.isMandatoryNode() {
+				return true
+			}
+		}
+		return false
+	}
+	return e.Mandatory == TSTrue
+}
@@ -0,0 +1,373 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompareEntries(t *testing.T) {
+	const old = `
+		container c {
+			leaf a { type string; }
+			leaf b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`
+
+	tests := []struct {
+		desc string
+		new  string
+		want []SchemaChange
+	}{{
+		desc: "no changes",
+		new:  old,
+	}, {
+		desc: "mandatory leaf added to an existing container",
+		new: `
+		container c {
+			leaf a { type string; }
+			leaf b { type string; }
+			leaf m { type string; mandatory true; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{{Path: "/m/c/m", Breaking: true, Message: "mandatory leaf added"}},
+	}, {
+		desc: "mandatory leaves added under a new list and presence container",
+		new: `
+		container c {
+			leaf a { type string; }
+			leaf b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			list nl {
+				key k;
+				leaf k { type string; }
+				leaf m { type string; mandatory true; }
+			}
+			container np {
+				presence "enables np";
+				leaf m { type string; mandatory true; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{
+			{Path: "/m/c/nl", Message: "list added"},
+			{Path: "/m/c/np", Message: "container added"},
+		},
+	}, {
+		desc: "mandatory leaf added under a new non-presence container",
+		new: `
+		container c {
+			leaf a { type string; }
+			leaf b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container nc {
+				leaf m { type string; mandatory true; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{{Path: "/m/c/nc", Breaking: true, Message: "mandatory container added"}},
+	}, {
+		desc: "mandatory state, output and input added",
+		new: `
+		container c {
+			leaf a { type string; }
+			leaf b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+				leaf m { type string; mandatory true; }
+			}
+		}
+		rpc r {
+			input {
+				leaf x { type string; }
+				leaf m { type string; mandatory true; }
+			}
+			output {
+				leaf y { type string; }
+				leaf m { type string; mandatory true; }
+			}
+		}`,
+		want: []SchemaChange{
+			{Path: "/m/c/s/m", Message: "leaf added"},
+			{Path: "/m/r/input/m", Breaking: true, Message: "mandatory leaf added"},
+			{Path: "/m/r/output/m", Message: "leaf added"},
+		},
+	}, {
+		desc: "leaf type narrowed",
+		new: `
+		container c {
+			leaf a { type string { length "1..5"; } }
+			leaf b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container s {
+				config false;
+				leaf v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{{Path: "/m/c/a", Breaking: true, Message: "length narrowed from 0..18446744073709551615 to 1..5"}},
+	}, {
+		desc: "nodes made mandatory, removed and changed",
+		new: `
+		container c {
+			leaf a { type string; mandatory true; }
+			container b { }
+			list l {
+				key k;
+				min-elements 2;
+				leaf k { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{
+			{Path: "/m/c/a", Breaking: true, Message: "leaf made mandatory"},
+			{Path: "/m/c/b", Breaking: true, Message: "changed from leaf to container"},
+			{Path: "/m/c/l", Breaking: true, Message: "min-elements raised from 1 to 2"},
+			{Path: "/m/c/s", Breaking: true, Message: "container removed"},
+		},
+	}, {
+		desc: "leaf changed to a leaf-list",
+		new: `
+		container c {
+			leaf a { type string; }
+			leaf-list b { type string; }
+			list l {
+				key k;
+				min-elements 1;
+				leaf k { type string; }
+			}
+			container s {
+				config false;
+				leaf-list v { type string; }
+			}
+		}
+		rpc r {
+			input { leaf x { type string; } }
+			output { leaf y { type string; } }
+		}`,
+		want: []SchemaChange{
+			{Path: "/m/c/b", Breaking: true, Message: "changed from leaf to leaf-list"},
+			{Path: "/m/c/s/v", Breaking: true, Message: "changed from leaf to leaf-list"},
+		},
+	}}
+
+	entry := func(t *testing.T, body string) *Entry {
+		t.Helper()
+		ms := NewModules()
+		if err := ms.Parse(`module m { prefix "m"; namespace "urn:m"; `+body+` }`, "m.yang"); err != nil {
+			t.Fatalf("cannot parse module: %v", err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("cannot process module: %v", errs)
+		}
+		return ToEntry(ms.Modules["m"])
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := CompareEntries(entry(t, old), entry(t, tt.new))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CompareEntries (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaChangeString(t *testing.T) {
+	for _, tt := range []struct {
+		in   SchemaChange
+		want string
+	}{
+		{SchemaChange{Path: "/m/a", Message: "leaf added"}, "/m/a: leaf added"},
+		{SchemaChange{Path: "/m/b", Breaking: true, Message: "leaf removed"}, "/m/b: leaf removed (breaking)"},
+	} {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("%#v.String(): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompareTypes(t *testing.T) {
+	tests := []struct {
+		desc     string
+		old, new string
+		want     []SchemaChange
+	}{{
+		desc: "unchanged",
+		old:  `type int32 { range "1..10"; }`,
+		new:  `type int32 { range "1..5 | 6..10"; }`,
+	}, {
+		desc: "range narrowed",
+		old:  `type int32 { range "1..10"; }`,
+		new:  `type int32 { range "1..5"; }`,
+		want: []SchemaChange{{Breaking: true, Message: "range narrowed from 1..10 to 1..5"}},
+	}, {
+		desc: "range widened",
+		old:  `type uint8 { range "1..10"; }`,
+		new:  `type uint8;`,
+		want: []SchemaChange{{Message: "range widened from 1..10 to 0..255"}},
+	}, {
+		desc: "range moved",
+		old:  `type int32 { range "1..10"; }`,
+		new:  `type int32 { range "5..20"; }`,
+		want: []SchemaChange{{Breaking: true, Message: "range changed from 1..10 to 5..20"}},
+	}, {
+		desc: "type changed",
+		old:  `type int32;`,
+		new:  `type string;`,
+		want: []SchemaChange{{Breaking: true, Message: "type changed from int32 to string"}},
+	}, {
+		desc: "length and patterns",
+		old:  `type string { length "1..10"; pattern "[a-z]*"; }`,
+		new:  `type string { length "1..20"; pattern "[a-m]*"; pattern "x.*" { modifier invert-match; } }`,
+		want: []SchemaChange{
+			{Message: "length widened from 1..10 to 1..20"},
+			{Breaking: true, Message: `pattern "[a-m]*" added`},
+			{Breaking: true, Message: `pattern "x.*" (invert-match) added`},
+			{Message: `pattern "[a-z]*" removed`},
+		},
+	}, {
+		desc: "enum membership",
+		old:  `type enumeration { enum a; enum b; enum c { value 5; } }`,
+		new:  `type enumeration { enum a; enum c { value 6; } enum d; }`,
+		want: []SchemaChange{
+			{Breaking: true, Message: "enum b removed"},
+			{Breaking: true, Message: "value of enum c changed from 5 to 6"},
+			{Message: "enum d added"},
+		},
+	}, {
+		desc: "bits membership",
+		old:  `type bits { bit a; bit b { position 3; } }`,
+		new:  `type bits { bit b { position 3; } bit c { position 4; } }`,
+		want: []SchemaChange{
+			{Breaking: true, Message: "bit a removed"},
+			{Message: "bit c added"},
+		},
+	}, {
+		desc: "leafref target",
+		old:  `type leafref { path "../a"; require-instance false; }`,
+		new:  `type leafref { path "../b"; }`,
+		want: []SchemaChange{
+			{Breaking: true, Message: `leafref path changed from "../a" to "../b"`},
+			{Breaking: true, Message: "require-instance changed from false to true"},
+		},
+	}, {
+		desc: "fraction digits",
+		old:  `type decimal64 { fraction-digits 2; }`,
+		new:  `type decimal64 { fraction-digits 3; }`,
+		want: []SchemaChange{{Breaking: true, Message: "fraction-digits changed from 2 to 3"}},
+	}, {
+		desc: "identityref base unchanged",
+		old:  `type identityref { base id1; }`,
+		new:  `type identityref { base id1; }`,
+	}, {
+		desc: "identityref base",
+		old:  `type identityref { base id1; }`,
+		new:  `type identityref { base id2; }`,
+		want: []SchemaChange{{Breaking: true, Message: "identityref base changed from m:id1 to m:id2"}},
+	}, {
+		desc: "union members",
+		old:  `type union { type int8 { range "0..5"; } type string; }`,
+		new:  `type union { type int8; }`,
+		want: []SchemaChange{
+			{Message: "union member 1: range widened from 0..5 to -128..127"},
+			{Breaking: true, Message: "union member 2 (string) removed"},
+		},
+	}}
+
+	leafType := func(t *testing.T, typ string) *YangType {
+		t.Helper()
+		ms := NewModules()
+		if err := ms.Parse(`module m { prefix "m"; namespace "urn:m"; identity id1; identity id2;
+			leaf a { type string; } leaf b { type string; } leaf l { `+typ+` } }`, "m.yang"); err != nil {
+			t.Fatalf("cannot parse module: %v", err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("cannot process module: %v", errs)
+		}
+		return ToEntry(ms.Modules["m"]).Dir["l"].Type
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := CompareTypes(leafType(t, tt.old), leafType(t, tt.new))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CompareTypes (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the listing of the when and must constraints of the
+// schema trees of a set of modules.
+
+import "sort"
+
+// A Constraint is a when or must statement on a node of a schema tree.
+type Constraint struct {
+	Node *Entry // the node the constraint is on
+	Path string // the path of Node, as returned by Entry.Path
+	Kind string // either "when" or "must"
+	Expr string // the XPath expression of the constraint
+	// ErrorMessage and ErrorAppTag are the error-message and
+	// error-app-tag of a must constraint, if any.
+	ErrorMessage string
+	ErrorAppTag  string
+	Description  string // the description of the constraint, if any
+	// FromAugment is true if the constraint is the when condition of the
+	// augment that added Node, as described by WhenStatement.
+	FromAugment bool
+}
+
+// Constraints returns the when and must constraints of every node in the
+// schema trees of the modules in ms, including those in operations and
+// notifications.  The constraints are ordered by module name and then by a
+// depth first walk of the schema tree, in which the constraints of a node
+// precede those of its descendants, the input and output of an operation
+// precede its other children, and the children of a node are taken in order
+// of name.  The when conditions of a node, as returned by
+// WhenConditions, precede its must constraints, as returned by Must.
+// Constraints must be called after a successful call to Process.
+func (ms *Modules) Constraints() []Constraint {
+	var cs []Constraint
+	for _, name := range ms.moduleNames() {
+		cs = appendConstraints(cs, ToEntry(ms.Modules[name]))
+	}
+	return cs
+}
+
+// appendConstraints appends the constraints of e and its descendants to cs,
+// returning the result.
+func appendConstraints(cs []Constraint, e *Entry) []Constraint {
+	if e == nil {
+		return cs
+	}
+	path := e.Path()
+	for _, w := range e.WhenConditions() {
+		cs = append(cs, Constraint{
+			Node:        e,
+			Path:        path,
+			Kind:        "when",
+			Expr:        w.Expr,
+			Description: w.Description,
+			FromAugment: w.FromAugment,
+		})
+	}
+	for _, m := range e.Must() {
+		cs = append(cs, Constraint{
+			Node:         e,
+			Path:         path,
+			Kind:         "must",
+			Expr:         m.Expr,
+			ErrorMessage: m.ErrorMessage,
+			ErrorAppTag:  m.ErrorAppTag,
+			Description:  m.Description,
+		})
+	}
+	if e.RPC != nil {
+		cs = appendConstraints(cs, e.RPC.Input)
+		cs = appendConstraints(cs, e.RPC.Output)
+	}
+	var names []string
+	for name := range e.Dir {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cs = appendConstraints(cs, e.Dir[name])
+	}
+	return cs
+}
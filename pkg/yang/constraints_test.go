@@ -0,0 +1,90 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestConstraints(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"b.yang": `
+			module b {
+				prefix "b";
+				namespace "urn:b";
+				import a { prefix a; }
+				augment "/a:c" {
+					when "a:enabled = 'true'";
+					leaf x { type string; when "../a:enabled"; }
+				}
+			}`,
+		"a.yang": `
+			module a {
+				prefix "a";
+				namespace "urn:a";
+				container c {
+					must "count(l) < 10" {
+						error-message "too many entries";
+						error-app-tag "too-many";
+					}
+					must "enabled or not(l)" { description "l needs enabled"; }
+					leaf enabled { type boolean; }
+					list l {
+						key k;
+						when "../enabled";
+						leaf k { type string; }
+					}
+				}
+				rpc r {
+					input {
+						leaf v { type string; must ". != 'bad'"; }
+					}
+				}
+				notification n {
+					leaf w { type string; when "true()"; }
+				}
+			}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	want := []Constraint{
+		{Path: "/a/c", Kind: "must", Expr: "count(l) < 10", ErrorMessage: "too many entries", ErrorAppTag: "too-many"},
+		{Path: "/a/c", Kind: "must", Expr: "enabled or not(l)", Description: "l needs enabled"},
+		{Path: "/a/c/l", Kind: "when", Expr: "../enabled"},
+		{Path: "/a/c/x", Kind: "when", Expr: "../a:enabled"},
+		{Path: "/a/c/x", Kind: "when", Expr: "a:enabled = 'true'", FromAugment: true},
+		{Path: "/a/n/w", Kind: "when", Expr: "true()"},
+		{Path: "/a/r/input/v", Kind: "must", Expr: ". != 'bad'"},
+	}
+	got := ms.Constraints()
+	for _, c := range got {
+		if c.Node == nil || c.Node.Path() != c.Path {
+			t.Errorf("constraint %q on %s: got Node %v, want the Entry at %s", c.Expr, c.Path, c.Node, c.Path)
+		}
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Constraint{}, "Node")); diff != "" {
+		t.Errorf("Constraints (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the computation of the configuration that is in effect
+// when none has been set, as described in RFC7950 sections 7.6.1, 7.7.2 and
+// 7.9.3, and the check that mandatory leaves and leaf-lists have no default.
+
+import "encoding/json"
+
+// DefaultConfig returns the configuration data beneath e that is in effect
+// when no configuration has been set, i.e., the default values of its leaves
+// and leaf-lists, including defaults inherited from their types.  The data is
+// encoded per RFC7951 in the form used by encoding/json, with member names
+// qualified as returned by JSONName.  An empty map is returned if there are
+// no defaults.
+//
+// A non-presence container exists whenever its parent does, so the defaults
+// of its descendants are included.  A presence container, list entry, or
+// any case of a choice other than its default case exists only if it is
+// created, so their defaults, including those of any non-presence containers
+// within them, are not (RFC7950 sections 7.5.1 and 7.6.1).  The defaults in
+// effect once one is created are returned by calling DefaultConfig on it.  A
+// leaf-list with a min-elements greater than zero must always be set, so its
+// defaults are never used (RFC7950 section 7.7.2).  State data, operations and
+// notifications are not configuration and are omitted.  When and if-feature
+// statements are not evaluated.
+func (e *Entry) DefaultConfig() map[string]interface{} {
+	m := map[string]interface{}{}
+	e.addDefaults(m)
+	return m
+}
+
+// addDefaults adds the default configuration of the children of e to m.
+func (e *Entry) addDefaults(m map[string]interface{}) {
+	for _, c := range e.Dir {
+		switch {
+		case c.ReadOnly(), c.RPC != nil, c.Kind == NotificationEntry:
+		case c.IsChoice():
+			// Only the default case of a choice exists when none of
+			// its cases has been created.  Process has made every
+			// child of a choice a case.
+			if len(c.Default) > 0 {
+				if dc := c.Dir[c.Default[0]]; dc != nil {
+					dc.addDefaults(m)
+				}
+			}
+		case c.IsLeaf():
+			if v, ok := c.SingleDefaultValue(); ok {
+				m[c.JSONName()] = defaultJSON(c.Type, v)
+			}
+		case c.IsLeafList():
+			if c.ListAttr != nil && c.ListAttr.MinElements > 0 {
+				break
+			}
+			if vs := c.DefaultValues(); len(vs) > 0 {
+				var values []interface{}
+				for _, v := range vs {
+					values = append(values, defaultJSON(c.Type, v))
+				}
+				m[c.JSONName()] = values
+			}
+		case c.IsContainer() && !c.isPresence():
+			d := map[string]interface{}{}
+			c.addDefaults(d)
+			if len(d) > 0 {
+				m[c.JSONName()] = d
+			}
+		}
+	}
+}
+
+// isPresence reports whether e is a presence container.
+func (e *Entry) isPresence() bool {
+	return len(e.Extra["presence"]) > 0
+}
+
+// defaultJSON returns the default value v of type t encoded per RFC7951.
+// Integers of 32 bits or less are numbers and booleans are true or false,
+// everything else is a string.
+func defaultJSON(t *YangType, v string) interface{} {
+	if t == nil {
+		return v
+	}
+	switch t.Kind {
+	case Yint8, Yint16, Yint32, Yuint8, Yuint16, Yuint32:
+		if _, err := ParseInt(v); err == nil {
+			return json.Number(v)
+		}
+	case Ybool:
+		switch v {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+	}
+	return v
+}
+
+// mandatoryDefaultErrors returns an error for each leaf in the modules in ms
+// that is both mandatory and has a default, and for each leaf-list that has
+// both a min-elements greater than zero and a default, which RFC7950 sections
+// 7.6.1 and 7.7.4 do not allow.  The node may have been made mandatory, or
+// given its default, by a refine or a deviation.  A default inherited from
+// the type of a mandatory node is not used and so is not an error.  Each
+// statement is reported once, not once for each use of a grouping that
+// defines it.
+func (ms *Modules) mandatoryDefaultErrors() []error {
+	var errs []error
+	for _, name := range ms.moduleNames() {
+		m := ms.Modules[name]
+		var ie instanceErrors
+		addMandatoryDefaultErrors(&ie, ToEntry(m))
+		mErrs := ie.errors()
+		ms.noteErrors(m, mErrs...)
+		errs = append(errs, mErrs...)
+	}
+	return errs
+}
+
+// addMandatoryDefaultErrors adds the errors for the mandatory leaves with a
+// default, and the leaf-lists with a min-elements and a default, found in e
+// and its descendants, including the input and output of operations, to ie.
+func addMandatoryDefaultErrors(ie *instanceErrors, e *Entry) {
+	if e == nil {
+		return
+	}
+	if e.IsLeaf() && e.Mandatory == TSTrue && len(e.Default) > 0 {
+		ie.add(e.Node, "", "%s: leaf %s is mandatory and has a default of %q", Source(e.Node), e.Path(), e.Default[0])
+	}
+	if e.IsLeafList() && e.ListAttr != nil && e.ListAttr.MinElements > 0 && len(e.Default) > 0 {
+		ie.add(e.Node, "", "%s: leaf-list %s has min-elements %d and a default of %q", Source(e.Node), e.Path(), e.ListAttr.MinElements, e.Default[0])
+	}
+	if e.RPC != nil {
+		addMandatoryDefaultErrors(ie, e.RPC.Input)
+		addMandatoryDefaultErrors(ie, e.RPC.Output)
+	}
+	for _, c := range e.Dir {
+		addMandatoryDefaultErrors(ie, c)
+	}
+}
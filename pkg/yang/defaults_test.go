@@ -0,0 +1,303 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			typedef mtu {
+				type uint16;
+				default 1500;
+			}
+
+			container system {
+				leaf hostname {
+					type string;
+					default "router";
+				}
+				leaf mtu { type mtu; }
+				leaf required {
+					type mtu;
+					mandatory true;
+				}
+				leaf enabled {
+					type boolean;
+					default true;
+				}
+				leaf big {
+					type uint64;
+					default 10;
+				}
+				leaf-list servers {
+					type string;
+					default "a";
+					default "b";
+				}
+				leaf none { type string; }
+				leaf-list mtus {
+					type mtu;
+					min-elements 0;
+				}
+				leaf-list required-mtus {
+					type mtu;
+					min-elements 1;
+				}
+				leaf-list ports {
+					type uint16;
+					min-elements 0;
+					default 22;
+				}
+				container timers {
+					leaf retry {
+						type uint8;
+						default 3;
+					}
+				}
+				container empty {
+					leaf x { type string; }
+				}
+				container ssh {
+					presence "enables ssh";
+					leaf port {
+						type uint16;
+						default 22;
+					}
+				}
+				container state {
+					config false;
+					leaf counter {
+						type uint32;
+						default 0;
+					}
+				}
+				choice transport {
+					default udp-port;
+					case tcp {
+						leaf tcp-port {
+							type uint16;
+							default 80;
+						}
+					}
+					leaf udp-port {
+						type uint16;
+						default 53;
+					}
+				}
+				choice other {
+					leaf a {
+						type string;
+						default "a";
+					}
+				}
+				list server {
+					key "name";
+					leaf name { type string; }
+					leaf port {
+						type uint16;
+						default 22;
+					}
+				}
+			}
+			rpc reset {
+				input {
+					leaf delay {
+						type uint8;
+						default 1;
+					}
+				}
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+
+	want := `{
+		"dev:system": {
+			"big": "10",
+			"enabled": true,
+			"hostname": "router",
+			"mtu": 1500,
+			"mtus": [1500],
+			"ports": [22],
+			"servers": ["a", "b"],
+			"timers": {"retry": 3},
+			"udp-port": 53
+		}
+	}`
+	got, err := json.Marshal(ToEntry(ms.Modules["dev"]).DefaultConfig())
+	if err != nil {
+		t.Fatalf("cannot marshal default config: %v", err)
+	}
+	var gotJSON, wantJSON interface{}
+	if err := json.Unmarshal(got, &gotJSON); err != nil {
+		t.Fatalf("cannot unmarshal default config: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantJSON); err != nil {
+		t.Fatalf("cannot unmarshal want: %v", err)
+	}
+	if diff := cmp.Diff(wantJSON, gotJSON); diff != "" {
+		t.Errorf("DefaultConfig (-want, +got):\n%s", diff)
+	}
+}
+
+func TestDefaultConfigPresence(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module p {
+			prefix "p";
+			namespace "urn:p";
+
+			container system {
+				container ssh {
+					presence "enables ssh";
+					leaf port {
+						type uint16;
+						default 22;
+					}
+					container timers {
+						leaf idle {
+							type uint16;
+							default 300;
+						}
+						container keepalive {
+							presence "enables keepalives";
+							leaf interval {
+								type uint8;
+								default 10;
+							}
+						}
+					}
+				}
+			}
+		}`, "p.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	system := ToEntry(ms.Modules["p"]).Dir["system"]
+
+	for _, tt := range []struct {
+		desc string
+		in   *Entry
+		want map[string]interface{}
+	}{{
+		desc: "presence container not created",
+		in:   ToEntry(ms.Modules["p"]),
+		want: map[string]interface{}{},
+	}, {
+		desc: "presence container created",
+		in:   system.Dir["ssh"],
+		want: map[string]interface{}{
+			"port":   json.Number("22"),
+			"timers": map[string]interface{}{"idle": json.Number("300")},
+		},
+	}, {
+		desc: "nested presence container created",
+		in:   system.Dir["ssh"].Dir["timers"].Dir["keepalive"],
+		want: map[string]interface{}{"interval": json.Number("10")},
+	}} {
+		if diff := cmp.Diff(tt.want, tt.in.DefaultConfig()); diff != "" {
+			t.Errorf("%s: DefaultConfig (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+}
+
+func TestMandatoryDefault(t *testing.T) {
+	tests := []struct {
+		desc             string
+		in               string
+		wantErrSubstring string
+	}{{
+		desc: "mandatory leaf whose type has a default",
+		in: `
+			typedef t { type string; default "x"; }
+			leaf l { type t; mandatory true; }`,
+	}, {
+		desc: "default removed from a leaf made mandatory by a refine",
+		in: `
+			grouping g { leaf l { type string; default "x"; } }
+			container c { uses g { refine l { mandatory true; } } }
+			deviation /c/l { deviate delete { default "x"; } }`,
+	}, {
+		desc:             "mandatory leaf with a default",
+		in:               `container c { leaf l { type string; mandatory true; default "x"; } }`,
+		wantErrSubstring: `m.yang:1:57: leaf /m/c/l is mandatory and has a default of "x"`,
+	}, {
+		desc: "mandatory from a refine of a leaf with a default",
+		in: `
+			grouping g { leaf l { type string; default "x"; } }
+			container c { uses g { refine l { mandatory true; } } }`,
+		wantErrSubstring: `m.yang:2:17: leaf /m/c/l is mandatory and has a default of "x"`,
+	}, {
+		desc: "default from a refine of a mandatory leaf",
+		in: `
+			grouping g { leaf l { type string; mandatory true; } }
+			container c { uses g { refine l { default "y"; } } }`,
+		wantErrSubstring: `leaf /m/c/l is mandatory and has a default of "y"`,
+	}, {
+		desc: "default added by a deviation to an input leaf",
+		in: `
+			rpc r { input { leaf l { type string; mandatory true; } } }
+			deviation /r/input/l { deviate add { default "z"; } }`,
+		wantErrSubstring: `leaf /m/r/input/l is mandatory and has a default of "z"`,
+	}, {
+		desc: "leaf-list with min-elements 0 and a default",
+		in:   `container c { leaf-list l { type string; min-elements 0; default "x"; } }`,
+	}, {
+		desc:             "leaf-list with min-elements 1 and a default",
+		in:               `container c { leaf-list l { type string; min-elements 1; default "x"; } }`,
+		wantErrSubstring: `m.yang:1:57: leaf-list /m/c/l has min-elements 1 and a default of "x"`,
+	}, {
+		desc: "min-elements from a refine of a leaf-list with a default",
+		in: `
+			grouping g { leaf-list l { type string; default "x"; } }
+			container c { uses g { refine l { min-elements 2; } } }`,
+		wantErrSubstring: `leaf-list /m/c/l has min-elements 2 and a default of "x"`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module m { prefix "m"; namespace "urn:m"; `+tt.in+` }`, "m.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Process: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the encoding of instance data as JSON per RFC7951.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EncodeJSON returns data, the instance data of the children of e, encoded as
+// JSON per RFC7951.  e is normally a module, container or list entry.  data is
+// a tree in the form used by encoding/json, i.e., objects are
+// map[string]interface{} and arrays are slices, whose member names may be
+// module qualified or not, as accepted by Validate.  The encoded member names
+// are qualified as returned by JSONName.
+//
+// The values of leaves and leaf-lists may be given in the form in which they
+// are encoded or as the corresponding Go value, and are converted as required
+// by their types: integers of up to 32 bits are numbers, 64 bit integers and
+// decimal64 values are strings, a []byte binary value is base64 encoded, an
+// empty value is [null] and may be given as nil or true, bits may be given as
+// a []string, and identities are qualified by the name of the module that
+// defines them.  A union value is encoded as the first member type that it is
+// a valid value of.  A leaf of type empty carries no value, only its
+// presence, so one given as false is not present and is omitted.  Each value
+// is then checked as it is by Validate, and an error, prefixed with the path
+// in data at which it was found, is returned for the first invalid value or
+// unknown member.  The constraints checked by Validate on the number and
+// presence of nodes are not checked.
+func (e *Entry) EncodeJSON(data map[string]interface{}) ([]byte, error) {
+	m, err := encodeObject(e, "", data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// encodeError returns an error found at path in the instance data.
+func encodeError(path, format string, args ...interface{}) error {
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("%s: "+format, append([]interface{}{path}, args...)...)
+}
+
+// encodeObject returns data, the JSON object holding the children of e, with
+// its members encoded.
+func encodeObject(e *Entry, path string, data map[string]interface{}) (map[string]interface{}, error) {
+	members := dataMembers(e)
+	present := map[*Entry]bool{}
+	m := map[string]interface{}{}
+
+	var names []string
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := members[name]
+		if c == nil {
+			return nil, encodeError(path, "unknown member %q", name)
+		}
+		if present[c] {
+			return nil, encodeError(path, "member %q specified more than once", name)
+		}
+		present[c] = true
+		if data[name] == false && isEmptyLeaf(c) {
+			continue
+		}
+		v, err := encodeNode(c, path+"/"+c.Name, data[name])
+		if err != nil {
+			return nil, err
+		}
+		m[c.JSONName()] = v
+	}
+	return m, nil
+}
+
+// isEmptyLeaf reports whether e is a leaf of type empty, or a leafref to one.
+func isEmptyLeaf(e *Entry) bool {
+	if !e.IsLeaf() {
+		return false
+	}
+	t, err := e.ResolvedType()
+	return err == nil && t != nil && t.Kind == Yempty
+}
+
+// encodeNode returns data, the value of the data node e, encoded.
+func encodeNode(e *Entry, path string, data interface{}) (interface{}, error) {
+	switch {
+	case e.Kind == AnyDataEntry || e.Kind == AnyXMLEntry:
+		// Any value is allowed, and is encoded as is.
+		return data, nil
+	case e.IsList():
+		items, ok := encodeArray(data)
+		if !ok {
+			return nil, encodeError(path, "list value must be an array, got %T", data)
+		}
+		var values []interface{}
+		for i, item := range items {
+			ipath := fmt.Sprintf("%s[%d]", path, i)
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, encodeError(ipath, "list entry must be an object, got %T", item)
+			}
+			v, err := encodeObject(e, ipath, m)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case e.IsLeafList():
+		items, ok := encodeArray(data)
+		if !ok {
+			return nil, encodeError(path, "leaf-list value must be an array, got %T", data)
+		}
+		var values []interface{}
+		for i, item := range items {
+			v, err := e.encodeValue(e.Type, item)
+			if err != nil {
+				return nil, encodeError(fmt.Sprintf("%s[%d]", path, i), "%v", err)
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case e.IsLeaf():
+		v, err := e.encodeValue(e.Type, data)
+		if err != nil {
+			return nil, encodeError(path, "%v", err)
+		}
+		return v, nil
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, encodeError(path, "%s value must be an object, got %T", e.Kind, data)
+	}
+	return encodeObject(e, path, m)
+}
+
+// encodeArray returns the elements of data if it is a slice other than a
+// []byte, which is a single binary value.
+func encodeArray(data interface{}) ([]interface{}, bool) {
+	switch a := data.(type) {
+	case []interface{}:
+		return a, true
+	case []byte:
+		return nil, false
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+// encodeValue returns data, a value of type t for the leaf or leaf-list e,
+// converted to its RFC7951 encoding and checked to be valid.
+func (e *Entry) encodeValue(t *YangType, data interface{}) (interface{}, error) {
+	if t == nil {
+		return data, nil
+	}
+	switch t.Kind {
+	case Yunion:
+		for _, ut := range t.Type {
+			if v, err := e.encodeValue(ut, data); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%v does not match any type of the union", data)
+	case Yleafref:
+		// The value is encoded as a value of the node referenced.
+		if target, err := e.resolveLeafref(t.Path); err == nil {
+			return target.encodeValue(target.Type, data)
+		}
+		return data, nil
+	case Yidentityref:
+		s, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("identityref value must be a string, got %T", data)
+		}
+		id, err := t.findIdentity(s, nil)
+		if err != nil {
+			return nil, err
+		}
+		return id.modulePrefixedName(), nil
+	}
+	v := convertValue(t, data)
+	if err := e.checkValue(t, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// convertValue returns data, a Go value of type t, in the form decoded from
+// RFC7951 JSON by encoding/json, or data itself if it cannot be converted.
+// The value returned is not checked.
+func convertValue(t *YangType, data interface{}) interface{} {
+	switch t.Kind {
+	case Yint8, Yint16, Yint32, Yuint8, Yuint16, Yuint32:
+		if s, ok := jsonNumber(data); ok {
+			return json.Number(s)
+		}
+	case Yint64, Yuint64:
+		if s, ok := jsonNumber(data); ok {
+			return s
+		}
+	case Ydecimal64:
+		switch n := data.(type) {
+		case float64:
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		case float32:
+			return strconv.FormatFloat(float64(n), 'f', -1, 32)
+		}
+		if s, ok := jsonNumber(data); ok {
+			return s
+		}
+	case Ybinary:
+		if b, ok := data.([]byte); ok {
+			return base64.StdEncoding.EncodeToString(b)
+		}
+	case Yempty:
+		if data == nil || data == true {
+			return []interface{}{nil}
+		}
+	case Ybits:
+		if bits, ok := data.([]string); ok {
+			return strings.Join(bits, " ")
+		}
+	}
+	return data
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(validateTestModule, "val.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if err := ms.Parse(`
+		module ext {
+			prefix "e";
+			namespace "urn:e";
+			import val { prefix v; }
+			identity ext-id { base v:base-id; }
+			augment /v:types { leaf extra { type uint64; } }
+		}`, "ext.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["val"])
+
+	tests := []struct {
+		desc    string
+		in      map[string]interface{}
+		want    string
+		wantErr string
+		// valid is true if the encoded data is valid for Validate.
+		valid bool
+	}{{
+		desc:  "Go values",
+		valid: true,
+		in: map[string]interface{}{
+			"top": map[string]interface{}{"tcp-port": uint16(80)},
+			"val:types": map[string]interface{}{
+				"i8":      int8(-3),
+				"i64":     int64(-9007199254740993),
+				"dec":     3.14,
+				"str":     "abc",
+				"color":   "red",
+				"flags":   []string{"a", "b"},
+				"present": nil,
+				"id":      "derived-id",
+				"either":  5,
+				"ref":     7,
+				"bin":     []byte("hi"),
+				"iid":     "/v:top/v:tcp-port",
+				"extra":   uint64(18446744073709551615),
+			},
+			"item": []map[string]interface{}{
+				{"name": "a", "value": 1, "tag": []string{"t"}},
+			},
+		},
+		want: `{"val:item":[{"name":"a","tag":["t"],"value":1}],` +
+			`"val:top":{"tcp-port":80},` +
+			`"val:types":{"bin":"aGk=","color":"red","dec":"3.14","either":5,"ext:extra":"18446744073709551615",` +
+			`"flags":"a b","i64":"-9007199254740993","i8":-3,"id":"val:derived-id","iid":"/v:top/v:tcp-port","present":[null],"ref":7,"str":"abc"}}`,
+	}, {
+		desc: "RFC7951 values",
+		in: map[string]interface{}{
+			"types": map[string]interface{}{
+				"i64":     "12",
+				"dec":     "1.5",
+				"present": []interface{}{nil},
+				"id":      "ext:ext-id",
+				"either":  "none",
+				"bin":     "aGk=",
+			},
+		},
+		want: `{"val:types":{"bin":"aGk=","dec":"1.5","either":"none","i64":"12","id":"ext:ext-id","present":[null]}}`,
+	}, {
+		desc:  "empty leaf given as true",
+		valid: true,
+		in: map[string]interface{}{
+			"top":   map[string]interface{}{"tcp-port": 80},
+			"item":  []interface{}{map[string]interface{}{"name": "a", "value": 1}},
+			"types": map[string]interface{}{"present": true},
+		},
+		want: `{"val:item":[{"name":"a","value":1}],"val:top":{"tcp-port":80},"val:types":{"present":[null]}}`,
+	}, {
+		desc:  "empty leaf given as false",
+		valid: true,
+		in: map[string]interface{}{
+			"top":   map[string]interface{}{"tcp-port": 80},
+			"item":  []interface{}{map[string]interface{}{"name": "a", "value": 1}},
+			"types": map[string]interface{}{"present": false, "i8": 1},
+		},
+		want: `{"val:item":[{"name":"a","value":1}],"val:top":{"tcp-port":80},"val:types":{"i8":1}}`,
+	}, {
+		desc:    "value for an empty leaf",
+		in:      map[string]interface{}{"types": map[string]interface{}{"present": "x"}},
+		wantErr: `/types/present: empty value must be [null], got x`,
+	}, {
+		desc:    "unknown member",
+		in:      map[string]interface{}{"types": map[string]interface{}{"unknown": 1}},
+		wantErr: `/types: unknown member "unknown"`,
+	}, {
+		desc:    "invalid value",
+		in:      map[string]interface{}{"types": map[string]interface{}{"i8": 11}},
+		wantErr: `/types/i8: 11 is outside the range -10..10`,
+	}, {
+		desc:    "too many fraction digits",
+		in:      map[string]interface{}{"types": map[string]interface{}{"dec": 3.141}},
+		wantErr: `/types/dec: "3.141" is not a valid decimal64`,
+	}, {
+		desc:    "string for an integer",
+		in:      map[string]interface{}{"types": map[string]interface{}{"i8": "1"}},
+		wantErr: `/types/i8: int8 value must be a number, got string`,
+	}, {
+		desc:    "base identity",
+		in:      map[string]interface{}{"types": map[string]interface{}{"id": "base-id"}},
+		wantErr: `/types/id: "base-id" is not a valid identity`,
+	}, {
+		desc:    "invalid leaf-list element",
+		in:      map[string]interface{}{"item": []interface{}{map[string]interface{}{"tag": []interface{}{1}}}},
+		wantErr: `/item[0]/tag[0]: string value must be a string, got int`,
+	}, {
+		desc:    "list that is not an array",
+		in:      map[string]interface{}{"item": map[string]interface{}{}},
+		wantErr: `/item: list value must be an array, got map[string]interface {}`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := mod.EncodeJSON(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatalf("EncodeJSON: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeJSON: got\n%s\nwant\n%s", got, tt.want)
+			}
+			var data map[string]interface{}
+			if err := json.Unmarshal(got, &data); err != nil {
+				t.Fatalf("cannot decode encoded data: %v", err)
+			}
+			if errs := mod.Validate(data); tt.valid && len(errs) != 0 {
+				t.Errorf("Validate of encoded data: %v", errs)
+			}
+		})
+	}
+}
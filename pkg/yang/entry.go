@@ -122,12 +122,17 @@ type Entry struct {
 	deviatePresence deviationPresence
 	Uses            []*UsesStmt `json:",omitempty"` // Uses merged into this entry.
 
-	// Extra maps all the unsupported fields to their values
+	// Extra maps all the unsupported fields to their values.  The keys are
+	// YANG keywords (e.g., "must" or "when") and the values the AST nodes
+	// of the statements.  Extra is populated by this library and its
+	// contents may change between releases; it should not be modified by
+	// calling code, which should use SetAnnotation instead.
 	Extra map[string][]interface{} `json:"extra-unstable,omitempty"`
 
 	// Annotation stores annotated values, and is not populated by this
 	// library but rather can be used by calling code where additional
-	// information should be stored alongside the Entry.
+	// information should be stored alongside the Entry.  It should be
+	// accessed using SetAnnotation and GetAnnotation.
 	Annotation map[string]interface{} `json:",omitempty"`
 
 	// namespace stores the namespace of the Entry if it overrides the
@@ -136,9 +141,15 @@ type Entry struct {
 	// the augmenting entity per RFC6020 Section 7.15.2. The namespace
 	// of the Entry should be accessed using the Namespace function.
 	namespace *Value
+
+	// augmentedBy is the augment that merged this Entry into its parent,
+	// if any.  It should be accessed using the AugmentedBy function.
+	augmentedBy *Entry
 }
 
-// An RPCEntry contains information related to an RPC Node.
+// An RPCEntry contains information related to an RPC or action Node.  The RPC
+// field of an Entry for an rpc or action statement is always set.  Input and
+// Output are nil if the statement has no input or output, respectively.
 type RPCEntry struct {
 	Input  *Entry
 	Output *Entry
@@ -196,9 +207,34 @@ func (e *Entry) IsList() bool {
 	return e.IsDir() && e.ListAttr != nil
 }
 
-// IsContainer returns true if e is a container.
+// ListKeyName returns the name of the single key leaf of the list e and true.
+// If e is not a list, or is a list with no key or with more than one key
+// leaf, ListKeyName returns "" and false.  A list that represents state data
+// need not have a key, in which case its entries are identified only by
+// their position.
+func (e *Entry) ListKeyName() (string, bool) {
+	if !e.IsList() {
+		return "", false
+	}
+	if keys := strings.Fields(e.Key); len(keys) == 1 {
+		return keys[0], true
+	}
+	return "", false
+}
+
+// IsContainer returns true if e is a container.  Modules, RPCs and actions are
+// also directories without list attributes but are not containers.
 func (e *Entry) IsContainer() bool {
-	return e.Kind == DirectoryEntry && e.ListAttr == nil
+	if e.Kind != DirectoryEntry || e.ListAttr != nil || e.RPC != nil {
+		return false
+	}
+	if e.Node != nil {
+		switch e.Node.Kind() {
+		case "module", "submodule", "rpc", "action":
+			return false
+		}
+	}
+	return true
 }
 
 // IsChoice returns true if the entry is a choice node within the schema.
@@ -401,6 +437,185 @@ func (e *Entry) delete(key string) {
 	delete(e.Dir, key)
 }
 
+// AnnotationKey returns the key used for the annotation name owned by
+// namespace.  namespace should uniquely identify the code setting the
+// annotation, such as its Go import path, so that annotations from different
+// users of an Entry do not collide.
+func AnnotationKey(namespace, name string) string {
+	return namespace + ":" + name
+}
+
+// SetAnnotation stores v as the annotation of e named key, replacing any
+// previous value.  key should be namespaced by its owner, e.g., by using
+// AnnotationKey.  Annotations are never set or read by this library, so they
+// cannot clobber the values it stores in Extra.
+func (e *Entry) SetAnnotation(key string, v interface{}) {
+	if e.Annotation == nil {
+		e.Annotation = map[string]interface{}{}
+	}
+	e.Annotation[key] = v
+}
+
+// GetAnnotation returns the annotation of e named key, and whether it has
+// been set.
+func (e *Entry) GetAnnotation(key string) (interface{}, bool) {
+	v, ok := e.Annotation[key]
+	return v, ok
+}
+
+// AugmentedBy returns the augment that added e to its parent, or nil if e was
+// not added by an augment.  The Node of the returned Entry is the *Augment
+// statement and its Namespace is that of the augmenting module.
+// Descendants of an augmented node return nil.
+func (e *Entry) AugmentedBy() *Entry {
+	return e.augmentedBy
+}
+
+// MandatoryDescendants returns the descendants of e that must be provided
+// when e is created, in the order they are declared: mandatory leaves,
+// choices, anydata and anyxml nodes, lists and leaf-lists with a
+// min-elements of at least one (RFC7950 section 3), and the keys of a list.
+// The descent continues through non-presence containers, which exist
+// whenever their parent does, but not into presence containers, lists,
+// choices or cases, whose descendants must be provided only once they are
+// created or chosen.  Only descendants that are configuration are returned if
+// e is, and operations and notifications beneath e are not descended into;
+// the input of an RPC or action e is.
+func (e *Entry) MandatoryDescendants() []*Entry {
+	return e.appendMandatoryDescendants(nil, e.isClientData())
+}
+
+// appendMandatoryDescendants appends the mandatory descendants of e, as
+// returned by MandatoryDescendants, to ms and returns the result.  client
+// is whether the entry MandatoryDescendants was called on is client data.
+func (e *Entry) appendMandatoryDescendants(ms []*Entry, client bool) []*Entry {
+	keys := map[string]bool{}
+	if e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			keys[k] = true
+		}
+	}
+	for _, c := range declaredChildren(e) {
+		switch {
+		case c.isClientData() != client, c.RPC != nil, c.Kind == NotificationEntry:
+		case keys[c.Name]:
+			ms = append(ms, c)
+		case c.IsList(), c.IsLeafList():
+			if c.ListAttr.MinElements > 0 {
+				ms = append(ms, c)
+			}
+		case c.IsChoice(), c.IsLeaf(), c.Kind == AnyDataEntry, c.Kind == AnyXMLEntry:
+			if c.Mandatory == TSTrue {
+				ms = append(ms, c)
+			}
+		case c.Kind == InputEntry, c.IsContainer() && !c.isPresence():
+			ms = c.appendMandatoryDescendants(ms, client)
+		}
+	}
+	return ms
+}
+
+// Notifications returns the notifications defined directly within e, keyed
+// by name.  In YANG 1.1 notifications may be defined within containers and
+// lists, as well as at the top level of a module.  The notifications remain
+// in e.Dir, so the Parent of each is the data node it is defined in.  nil is
+// returned if e defines no notifications.
+func (e *Entry) Notifications() map[string]*Entry {
+	var ns map[string]*Entry
+	for name, c := range e.Dir {
+		if c.Kind == NotificationEntry {
+			if ns == nil {
+				ns = map[string]*Entry{}
+			}
+			ns[name] = c
+		}
+	}
+	return ns
+}
+
+// Reference returns the argument of the reference statement of e, or the
+// empty string if e has none.
+func (e *Entry) Reference() string {
+	var ref string
+	for _, i := range e.Extra["reference"] {
+		if v, ok := i.(*Value); ok && v != nil {
+			ref = v.Name
+		}
+	}
+	return ref
+}
+
+// FullDescription returns the description of e followed, if e has a
+// reference statement, by a line giving the reference.  The two are
+// separated by a blank line when both are present.
+func (e *Entry) FullDescription() string {
+	ref := e.Reference()
+	switch {
+	case ref == "":
+		return e.Description
+	case e.Description == "":
+		return "Reference: " + ref
+	}
+	return e.Description + "\n\nReference: " + ref
+}
+
+// A MustStatement is a must constraint on the data represented by an Entry.
+type MustStatement struct {
+	Expr         string // the XPath expression that must be true
+	ErrorMessage string // the error-message to report if Expr is false, if any
+	ErrorAppTag  string // the error-app-tag to report if Expr is false, if any
+	Description  string // the description of the constraint, if any
+}
+
+// Must returns the must statements of e, in the order they were declared.
+func (e *Entry) Must() []*MustStatement {
+	var ms []*MustStatement
+	for _, i := range e.Extra["must"] {
+		m, ok := i.(*Must)
+		if !ok || m == nil {
+			continue
+		}
+		ms = append(ms, &MustStatement{
+			Expr:         m.Name,
+			ErrorMessage: m.ErrorMessage.asString(),
+			ErrorAppTag:  m.ErrorAppTag.asString(),
+			Description:  m.Description.asString(),
+		})
+	}
+	return ms
+}
+
+// A WhenStatement is a when condition on the existence of an Entry.
+type WhenStatement struct {
+	Expr        string // the XPath expression that must be true
+	Description string // the description of the condition, if any
+	// FromAugment is true if the condition is that of the augment that
+	// added the Entry, rather than of the Entry itself.  The context node
+	// of such a condition is the target of the augment, i.e., the parent
+	// of the Entry, rather than the Entry.
+	FromAugment bool
+}
+
+// WhenConditions returns the when conditions on e, all of which must be true
+// for e to exist: its own when statement, if any, followed by that of the
+// augment that added e, if any.
+func (e *Entry) WhenConditions() []*WhenStatement {
+	var ws []*WhenStatement
+	for _, i := range e.Extra["when"] {
+		v, ok := i.(*Value)
+		if !ok || v == nil {
+			continue
+		}
+		_, fromAugment := v.Parent.(*Augment)
+		ws = append(ws, &WhenStatement{
+			Expr:        v.Name,
+			Description: v.Description.asString(),
+			FromAugment: fromAugment,
+		})
+	}
+	return ws
+}
+
 // GetWhenXPath returns the when XPath statement of e if able.
 func (e *Entry) GetWhenXPath() (string, bool) {
 	switch n := e.Node.(type) {
@@ -581,6 +796,9 @@ func ToEntry(n Node) (e *Entry) {
 		if s.Default != nil {
 			e.Default = []string{s.Default.Name}
 		}
+		if s.Units != nil {
+			e.Units = s.Units.Name
+		}
 		e.Type = s.Type.YangType
 		e.Config, err = tristateValue(s.Config)
 		e.addError(err)
@@ -635,6 +853,7 @@ func ToEntry(n Node) (e *Entry) {
 		// grouping has a leafref that references outside the group.
 		e = ToEntry(g).dup()
 		addExtraKeywordsToLeafEntry(n, e)
+		e.applyRefines(s)
 		return e
 	}
 
@@ -705,7 +924,13 @@ func ToEntry(n Node) (e *Entry) {
 			}
 		case "action":
 			for _, r := range fv.Interface().([]*Action) {
-				e.add(r.Name, ToEntry(r))
+				action := ToEntry(r)
+				if action.RPC == nil {
+					// As for "rpc", when "action" has no "input"
+					// or "output" children.
+					action.RPC = &RPCEntry{}
+				}
+				e.add(r.Name, action)
 			}
 		case "augment":
 			for _, a := range fv.Interface().([]*Augment) {
@@ -878,16 +1103,14 @@ func ToEntry(n Node) (e *Entry) {
 			case LeafEntry, ChoiceEntry:
 				// default is handled separately for leaf, leaf-list and choice
 			case DeviateEntry:
-				// handle deviate statements.
-				// TODO(wenovus): support refine statement's default substatement.
-				d, ok := fv.Interface().(*Value)
+				// handle deviate statements, which may have several
+				// defaults for a leaf-list (YANG 1.1).
+				ds, ok := fv.Interface().([]*Value)
 				if !ok {
 					e.addError(fmt.Errorf("%s: unexpected default type in %s:%s", Source(n), n.Kind(), n.NName()))
 				}
-				// TODO(wenovus): deviate statement and refine statement should
-				// allow multiple default substatements for leaf-list types (YANG1.1).
-				if d != nil {
-					e.Default = []string{d.asString()}
+				for _, d := range ds {
+					e.Default = append(e.Default, d.asString())
 				}
 			}
 		case "typedef":
@@ -1063,20 +1286,13 @@ func getRootPrefix(e *Entry) *Value {
 
 // Augment processes augments in e, return the number of augments processed
 // and the augments skipped.  If addErrors is true then missing augments will
-// generate errors.
+// generate errors.  The augments are applied in the order they were declared;
+// Process applies the augments of all modules in an order in which those
+// that target nodes added by other augments come after them.
 func (e *Entry) Augment(addErrors bool) (processed, skipped int) {
-	// Now process the augments we found
-	// NOTE(borman): is it possible this will fail if the augment refers
-	// to some removed sibling that has not been processed?  Perhaps this
-	// should be done after the entire tree is built.  Is it correct to
-	// assume augment paths are data tree paths and not schema tree paths?
-	// Augments can depend upon augments.  We need to figure out how to
-	// order the augments (or just keep trying until we can make no further
-	// progress)
 	var unapplied []*Entry
 	for _, a := range e.Augments {
-		target := a.Find(a.Name)
-		if target == nil {
+		if !a.applyAugment() {
 			if addErrors {
 				e.errorf("%s: augment %s not found", Source(a.Node), a.Name)
 			}
@@ -1084,18 +1300,76 @@ func (e *Entry) Augment(addErrors bool) (processed, skipped int) {
 			unapplied = append(unapplied, a)
 			continue
 		}
-		// Augments do not have a prefix we merge in, just a node.
-		// We retain the namespace from the original context of the
-		// augment since the nodes have this namespace even though they
-		// are merged into another entry.
 		processed++
-		target.merge(nil, a.Namespace(), a)
-		target.Augmented = append(target.Augmented, a.shallowDup())
 	}
 	e.Augments = unapplied
 	return processed, skipped
 }
 
+// applyAugment merges e, an augment, into its target, reporting whether the
+// target was found.
+func (e *Entry) applyAugment() bool {
+	target := e.Find(e.Name)
+	if target == nil {
+		target = e.implicitOperationTarget()
+	}
+	if target == nil {
+		return false
+	}
+	// Augments do not have a prefix we merge in, just a node.
+	// We retain the namespace from the original context of the
+	// augment since the nodes have this namespace even though they
+	// are merged into another entry.
+	target.merge(nil, e.Namespace(), e)
+	for k, v := range e.Dir {
+		if c := target.Dir[k]; c != nil && c.Node == v.Node {
+			c.augmentedBy = e
+			// The nodes an augment adds exist only when the
+			// augment's when condition is true.
+			if ws := e.Extra["when"]; len(ws) > 0 {
+				c.setExtra("when", append(append([]interface{}{}, c.Extra["when"]...), ws...))
+			}
+		}
+	}
+	target.Augmented = append(target.Augmented, e.shallowDup())
+	return true
+}
+
+// implicitOperationTarget returns the input or output of the RPC or action
+// that the augment e targets, creating it if the operation has none, or nil
+// if e does not target the input or output of an operation.  An operation
+// without an input or output statement has an implicit, empty one (RFC7950
+// sections 7.14.2 and 7.14.3), which may be augmented.
+func (e *Entry) implicitOperationTarget() *Entry {
+	i := strings.LastIndex(e.Name, "/")
+	if i <= 0 {
+		return nil
+	}
+	op := e.Find(e.Name[:i])
+	if op == nil || op.RPC == nil {
+		return nil
+	}
+	switch _, part := getPrefix(e.Name[i+1:]); part {
+	case "input":
+		if op.RPC.Input == nil {
+			in := ToEntry(&Input{Name: "input", Source: op.Node.Statement(), Parent: op.Node})
+			in.Parent = op
+			in.Kind = InputEntry
+			op.RPC.Input = in
+		}
+		return op.RPC.Input
+	case "output":
+		if op.RPC.Output == nil {
+			out := ToEntry(&Output{Name: "output", Source: op.Node.Statement(), Parent: op.Node})
+			out.Parent = op
+			out.Kind = OutputEntry
+			op.RPC.Output = out
+		}
+		return op.RPC.Output
+	}
+	return nil
+}
+
 // ApplyDeviate walks the deviations within the supplied entry, and applies them to the
 // schema.
 func (e *Entry) ApplyDeviate() []error {
@@ -1123,14 +1397,23 @@ func (e *Entry) ApplyDeviate() []error {
 							case deviatedNode.IsLeafList():
 								deviatedNode.Default = append(deviatedNode.Default, devSpec.Default...)
 							case len(devSpec.Default) > 1:
-								appendErr(fmt.Errorf("%s: tried to add more than one default to a non-leaflist entry at deviation", Source(e.Node)))
+								appendErr(fmt.Errorf("%s: tried to add more than one default to a non-leaflist entry at deviation", Source(devSpec.Node)))
 							case len(deviatedNode.Default) != 0:
-								appendErr(fmt.Errorf("%s: tried to add a default value to an entry that already has a default value", Source(e.Node)))
+								appendErr(fmt.Errorf("%s: tried to add a default value to an entry that already has a default value", Source(devSpec.Node)))
 							case len(devSpec.Default) == 1 && len(deviatedNode.Default) == 0:
 								deviatedNode.Default = append([]string{}, devSpec.Default[0])
 							}
 						case DeviationReplace:
-							deviatedNode.Default = append([]string{}, devSpec.Default...)
+							// The properties replaced must exist, see
+							// https://tools.ietf.org/html/rfc7950#section-7.20.3.2
+							switch {
+							case len(deviatedNode.Default) == 0:
+								appendErr(fmt.Errorf("%s: tried to deviate replace a default statement that doesn't exist on %s", Source(devSpec.Node), deviatedNode.Path()))
+							case len(devSpec.Default) > 1 && !deviatedNode.IsLeafList():
+								appendErr(fmt.Errorf("%s: tried to replace the default of a non-leaflist entry with more than one default", Source(devSpec.Node)))
+							default:
+								deviatedNode.Default = append([]string{}, devSpec.Default...)
+							}
 						}
 					}
 
@@ -1158,7 +1441,27 @@ func (e *Entry) ApplyDeviate() []error {
 						deviatedNode.Units = devSpec.Units
 					}
 
+					for _, kw := range []string{"must", "unique"} {
+						vs := devSpec.Extra[kw]
+						switch {
+						case len(vs) == 0:
+						case dt == DeviationReplace:
+							// See https://tools.ietf.org/html/rfc7950#section-7.20.3.2
+							appendErr(fmt.Errorf("%s: tried to deviate replace a %s statement, only deviate add and delete may change %s statements", Source(devSpec.Node), kw, kw))
+						case kw == "unique" && !deviatedNode.IsList():
+							appendErr(fmt.Errorf("%s: tried to deviate add a unique statement to a non-list entry %s", Source(devSpec.Node), deviatedNode.Path()))
+						default:
+							deviatedNode.setExtra(kw, append(append([]interface{}{}, deviatedNode.Extra[kw]...), vs...))
+						}
+					}
+
 					if devSpec.Type != nil {
+						// The type of a node can only be replaced, see
+						// https://tools.ietf.org/html/rfc7950#section-7.20.3.2
+						if dt != DeviationReplace {
+							appendErr(fmt.Errorf("%s: tried to deviate add a type statement, only deviate replace may change a type", Source(devSpec.Node)))
+							continue
+						}
 						deviatedNode.Type = devSpec.Type
 					}
 
@@ -1174,19 +1477,23 @@ func (e *Entry) ApplyDeviate() []error {
 						deviatedNode.Config = TSUnset
 					}
 
-					if len(devSpec.Default) > 0 {
+					// The argument of each default deleted must match a
+					// default of the node, see
+					// https://tools.ietf.org/html/rfc7950#section-7.20.3.2
+					for _, def := range devSpec.Default {
+						i := 0
+						for i < len(deviatedNode.Default) && deviatedNode.Default[i] != def {
+							i++
+						}
 						switch {
-						case deviatedNode.IsLeafList():
-							// It is unclear from RFC7950 on how deviate delete works
-							// when there are duplicate leaf-list values in config-false leafs.
-							// TODO(wenbli): Add support for deleting default values when the leaf-list is a config leaf (duplicates are not allowed).
-							appendErr(fmt.Errorf("%s: deviate delete on default statements unsupported for leaf-lists, please use replace instead", Source(e.Node)))
 						case len(deviatedNode.Default) == 0:
-							appendErr(fmt.Errorf("%s: tried to deviate delete a default statement that doesn't exist", Source(e.Node)))
-						case devSpec.Default[0] != deviatedNode.Default[0]:
-							appendErr(fmt.Errorf("%s: tried to deviate delete a default statement with a non-matching keyword", Source(e.Node)))
-						default:
+							appendErr(fmt.Errorf("%s: tried to deviate delete a default statement that doesn't exist on %s", Source(devSpec.Node), deviatedNode.Path()))
+						case i == len(deviatedNode.Default):
+							appendErr(fmt.Errorf("%s: tried to deviate delete a default statement with a non-matching keyword %q on %s", Source(devSpec.Node), def, deviatedNode.Path()))
+						case len(deviatedNode.Default) == 1:
 							deviatedNode.Default = nil
+						default:
+							deviatedNode.Default = append(append([]string{}, deviatedNode.Default[:i]...), deviatedNode.Default[i+1:]...)
 						}
 					}
 
@@ -1194,6 +1501,18 @@ func (e *Entry) ApplyDeviate() []error {
 						deviatedNode.Mandatory = TSUnset
 					}
 
+					if devSpec.Units != "" {
+						deviatedNode.Units = ""
+					}
+
+					for _, kw := range []string{"must", "unique"} {
+						for _, v := range devSpec.Extra[kw] {
+							if !deviatedNode.deleteExtra(kw, v) {
+								appendErr(fmt.Errorf("%s: tried to deviate delete a %s statement that doesn't exist", Source(devSpec.Node), kw))
+							}
+						}
+					}
+
 					if devSpec.deviatePresence.hasMinElements {
 						if !deviatedNode.IsList() && !deviatedNode.IsLeafList() {
 							appendErr(fmt.Errorf("tried to deviate min-elements on a non-list type %s", deviatedNode.Kind))
@@ -1228,6 +1547,38 @@ func (e *Entry) ApplyDeviate() []error {
 	return errs
 }
 
+// setExtra sets the values of the keyword kw in e.Extra to vs.  e.Extra is
+// copied first as it is shared with the copies of e made by dup.
+func (e *Entry) setExtra(kw string, vs []interface{}) {
+	extra := make(map[string][]interface{}, len(e.Extra)+1)
+	for k, v := range e.Extra {
+		extra[k] = v
+	}
+	if len(vs) == 0 {
+		delete(extra, kw)
+	} else {
+		extra[kw] = vs
+	}
+	e.Extra = extra
+}
+
+// deleteExtra removes the first value of the keyword kw in e.Extra with the
+// same argument as v, reporting whether there was one.
+func (e *Entry) deleteExtra(kw string, v interface{}) bool {
+	n, ok := v.(Node)
+	if !ok {
+		return false
+	}
+	for i, ev := range e.Extra[kw] {
+		if en, ok := ev.(Node); ok && en.NName() == n.NName() {
+			vs := append(append([]interface{}{}, e.Extra[kw][:i]...), e.Extra[kw][i+1:]...)
+			e.setExtra(kw, vs)
+			return true
+		}
+	}
+	return false
+}
+
 // FixChoice inserts missing Case entries for non-case entries within a choice
 // entry.
 func (e *Entry) FixChoice() {
@@ -1300,6 +1651,13 @@ func (e *Entry) Find(name string) *Entry {
 			if m != e.Node.(*Module) {
 				e = ToEntry(m)
 			}
+		} else if sm, ok := e.Node.(*Module); ok && sm.Kind() == "submodule" {
+			// An unprefixed node in a submodule is in the namespace of
+			// the module it belongs to, and so may be any node of that
+			// module, not only those defined in the submodule.
+			if m := module(sm); m != nil {
+				e = ToEntry(m)
+			}
 		}
 	}
 
@@ -1317,6 +1675,10 @@ func (e *Entry) Find(name string) *Entry {
 				e = e.RPC.Input
 			case "output":
 				e = e.RPC.Output
+			default:
+				// An RPC has no children other than its input
+				// and output.
+				return nil
 			}
 		default:
 			_, part = getPrefix(part)
@@ -1391,6 +1753,35 @@ func (e *Entry) InstantiatingModule() (string, error) {
 	return module.Name, nil
 }
 
+// JSONName returns the member name used for e when encoding data as JSON per
+// RFC7951 section 4.  The name is qualified with the name of the module that
+// instantiates e (i.e., "module:name") if e is a top-level data node, or if its
+// instantiating module differs from that of its closest ancestor data node.
+// Otherwise the unqualified name of e is returned.  Choice and case entries do
+// not appear in encoded data, and are skipped when looking for the ancestor.
+// The name of a module entry is returned unqualified.
+func (e *Entry) JSONName() string {
+	if e.Parent == nil {
+		return e.Name
+	}
+	p := e.Parent
+	for p != nil && (p.IsChoice() || p.IsCase()) {
+		p = p.Parent
+	}
+	mod, err := e.InstantiatingModule()
+	if err != nil {
+		return e.Name
+	}
+	if p == nil || p.Parent == nil {
+		// e is a top-level node, which is always qualified.
+		return mod + ":" + e.Name
+	}
+	if pmod, err := p.InstantiatingModule(); err != nil || pmod != mod {
+		return mod + ":" + e.Name
+	}
+	return e.Name
+}
+
 // shallowDup makes a shallow duplicate of e (only direct children are
 // duplicated; grandchildren and deeper descendants are deleted).
 func (e *Entry) shallowDup() *Entry {
@@ -1431,6 +1822,17 @@ func (e *Entry) dup() *Entry {
 			ne.Dir[k] = de
 		}
 	}
+	if e.RPC != nil {
+		ne.RPC = &RPCEntry{}
+		if e.RPC.Input != nil {
+			ne.RPC.Input = e.RPC.Input.dup()
+			ne.RPC.Input.Parent = &ne
+		}
+		if e.RPC.Output != nil {
+			ne.RPC.Output = e.RPC.Output.dup()
+			ne.RPC.Output.Parent = &ne
+		}
+	}
 	return &ne
 }
 
@@ -1460,6 +1862,88 @@ func (e *Entry) merge(prefix *Value, namespace *Value, oe *Entry) {
 	}
 }
 
+// applyRefines applies the refine statements of u to e, the duplicate of the
+// grouping used by u.  The default, mandatory, config, min-elements,
+// max-elements and description of the refined nodes are replaced by those of
+// the refine statement, if set.
+func (e *Entry) applyRefines(u *Uses) {
+	for _, r := range u.Refine {
+		t := e.refineTarget(r.Name)
+		if t == nil {
+			e.addError(fmt.Errorf("%s: refine target %s not found in grouping %s", Source(r), r.Name, u.Name))
+			continue
+		}
+		if r.Default != nil {
+			t.Default = []string{r.Default.Name}
+		}
+		for _, v := range []struct {
+			keyword string
+			value   *Value
+			ts      *TriState
+		}{{"mandatory", r.Mandatory, &t.Mandatory}, {"config", r.Config, &t.Config}} {
+			if v.value == nil {
+				continue
+			}
+			switch v.value.Name {
+			case "true":
+				*v.ts = TSTrue
+			case "false":
+				*v.ts = TSFalse
+			default:
+				e.addError(fmt.Errorf("%s: invalid %s value: %s", Source(r), v.keyword, v.value.Name))
+			}
+		}
+		if r.MinElements != nil || r.MaxElements != nil {
+			if t.ListAttr == nil {
+				e.addError(fmt.Errorf("%s: refine of min-elements or max-elements of %s, which is not a list or leaf-list", Source(r), r.Name))
+				continue
+			}
+			// The ListAttr of t is shared with the other uses of the
+			// grouping, so the refined bounds are set in a copy.
+			la := *t.ListAttr
+			var err error
+			if r.MinElements != nil {
+				if la.MinElements, err = semCheckMinElements(r.MinElements); err != nil {
+					e.addError(err)
+				}
+			}
+			if r.MaxElements != nil {
+				if la.MaxElements, err = semCheckMaxElements(r.MaxElements); err != nil {
+					e.addError(err)
+				}
+			}
+			t.ListAttr = &la
+		}
+		if r.Description != nil {
+			t.Description = r.Description.Name
+		}
+	}
+}
+
+// refineTarget returns the node beneath e named by path, the descendant
+// schema node identifier of a refine statement, or nil if there is none.
+// The prefixes of the identifier are ignored as the nodes of a grouping are
+// all in the module that uses it.  The case of a shorthand case statement,
+// which has the name of its only child, may be named or left out.
+func (e *Entry) refineTarget(path string) *Entry {
+	t := e
+	for _, name := range strings.Split(path, "/") {
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[i+1:]
+		}
+		if t.Parent != nil && t.Parent.IsChoice() && !t.IsCase() && name == t.Name {
+			// The shorthand case of t.
+			continue
+		}
+		c := t.Dir[name]
+		if c == nil {
+			return nil
+		}
+		t = c
+	}
+	return t
+}
+
 // nless returns -1 if a is less than b, 0 if a == b, and 1 if a > b.
 // If a and b are both numeric, then nless compares them as numbers,
 // otherwise they are compared lexicographically.
@@ -1531,7 +2015,7 @@ func (s sortedErrors) Less(i, j int) bool {
 
 // errorSort sorts the strings in the errors slice assuming each line starts
 // with file:line:col.  Line and column number are sorted numerically.
-// Duplicate errors are stripped.
+// Duplicate errors, those with the same message and location, are stripped.
 func errorSort(errors []error) []error {
 	switch len(errors) {
 	case 0:
@@ -1546,8 +2030,8 @@ func errorSort(errors []error) []error {
 	sort.Sort(elist)
 	errors = make([]error, len(errors))
 	i := 0
-	for _, err := range elist {
-		if i > 0 && reflect.DeepEqual(err.err, errors[i-1]) {
+	for x, err := range elist {
+		if x > 0 && err.s == elist[x-1].s {
 			continue
 		}
 		errors[i] = err.err
@@ -1556,6 +2040,18 @@ func errorSort(errors []error) []error {
 	return errors[:i]
 }
 
+// EffectiveUnits returns the units of the leaf or leaf-list entry e.  If e
+// has no units statement, the units of its type (if any) are returned.
+func (e *Entry) EffectiveUnits() string {
+	if e.Units != "" {
+		return e.Units
+	}
+	if e.Type != nil {
+		return e.Type.Units
+	}
+	return ""
+}
+
 // SingleDefaultValue returns the single schema default value for e and a bool
 // indicating whether the entry contains one and only one default value. The
 // empty string is returned when the entry has zero or multiple default values.
@@ -441,6 +441,85 @@ func TestEntryNamespace(t *testing.T) {
 	}
 }
 
+func TestEntryJSONName(t *testing.T) {
+	ms := NewModules()
+	for _, tt := range parentTestModules {
+		if err := ms.Parse(tt.in, tt.name); err != nil {
+			t.Fatalf("could not parse module %s: %v", tt.name, err)
+		}
+	}
+
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("could not process modules: %v", errs)
+	}
+
+	foo, _ := ms.GetModule("foo")
+
+	for _, tc := range []struct {
+		descr string
+		entry *Entry
+		want  string
+	}{{
+		descr: "module entry is not qualified",
+		entry: foo,
+		want:  "foo",
+	}, {
+		descr: "top-level container is always qualified",
+		entry: foo.Dir["foo-c"],
+		want:  "foo:foo-c",
+	}, {
+		descr: "child in the same module as its parent is not qualified",
+		entry: foo.Dir["foo-c"].Dir["zzz"],
+		want:  "zzz",
+	}, {
+		descr: "grouping from another module used in foo is not qualified",
+		entry: foo.Dir["foo-c"].Dir["test1"],
+		want:  "test1",
+	}, {
+		descr: "leaf augmented from baz is qualified",
+		entry: foo.Dir["foo-c"].Dir["baz-direct-leaf"],
+		want:  "baz:baz-direct-leaf",
+	}, {
+		descr: "child of an augmented container from baz is not qualified",
+		entry: foo.Dir["foo-c"].Dir["baz-dir"].Dir["aardvark"],
+		want:  "aardvark",
+	}} {
+		if got := tc.entry.JSONName(); got != tc.want {
+			t.Errorf("%s: %s.JSONName(): got %q, want %q", tc.descr, tc.entry.Path(), got, tc.want)
+		}
+	}
+}
+
+func TestEntryAnnotation(t *testing.T) {
+	e := &Entry{Name: "leaf"}
+	if v, ok := e.GetAnnotation("any"); ok || v != nil {
+		t.Errorf("GetAnnotation on unannotated entry: got (%v, %v), want (nil, false)", v, ok)
+	}
+
+	hint := AnnotationKey("example.com/ui", "hint")
+	other := AnnotationKey("example.com/other", "hint")
+	if hint == other {
+		t.Fatalf("AnnotationKey: keys for different namespaces are equal: %q", hint)
+	}
+
+	e.SetAnnotation(hint, "slider")
+	e.SetAnnotation(other, 42)
+	if v, ok := e.GetAnnotation(hint); !ok || v != "slider" {
+		t.Errorf("GetAnnotation(%q): got (%v, %v), want (slider, true)", hint, v, ok)
+	}
+	if v, ok := e.GetAnnotation(other); !ok || v != 42 {
+		t.Errorf("GetAnnotation(%q): got (%v, %v), want (42, true)", other, v, ok)
+	}
+
+	e.SetAnnotation(hint, nil)
+	if v, ok := e.GetAnnotation(hint); !ok || v != nil {
+		t.Errorf("GetAnnotation(%q) after setting nil: got (%v, %v), want (nil, true)", hint, v, ok)
+	}
+	if e.Extra != nil {
+		t.Errorf("SetAnnotation modified Extra: %v", e.Extra)
+	}
+}
+
 var testWhenModules = []struct {
 	name string
 	in   string
@@ -469,6 +548,8 @@ module when {
   }
 
   list delta {
+    key "k";
+    leaf k { type string; }
     when "../condition = 'delta'";
   }
 
@@ -503,6 +584,71 @@ module when {
 	},
 }
 
+func TestEntryMust(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			container c {
+				must "count(l) < 10" {
+					error-message "too many entries";
+					error-app-tag "too-many";
+					description "at most 9 entries";
+				}
+				must "true()";
+				leaf-list l {
+					type uint8;
+					must ". != 0" {
+						error-message "zero is not allowed";
+					}
+				}
+				leaf plain { type string; }
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	c := ToEntry(ms.Modules["dev"]).Dir["c"]
+
+	tests := []struct {
+		desc string
+		in   *Entry
+		want []*MustStatement
+	}{{
+		desc: "container with two musts",
+		in:   c,
+		want: []*MustStatement{{
+			Expr:         "count(l) < 10",
+			ErrorMessage: "too many entries",
+			ErrorAppTag:  "too-many",
+			Description:  "at most 9 entries",
+		}, {
+			Expr: "true()",
+		}},
+	}, {
+		desc: "leaf-list",
+		in:   c.Dir["l"],
+		want: []*MustStatement{{
+			Expr:         ". != 0",
+			ErrorMessage: "zero is not allowed",
+		}},
+	}, {
+		desc: "no must",
+		in:   c.Dir["plain"],
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.in.Must()); diff != "" {
+				t.Errorf("Must (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestGetWhenXPath(t *testing.T) {
 	ms := NewModules()
 	ms.ParseOptions.StoreUses = true
@@ -772,6 +918,179 @@ func TestAugmentedEntry(t *testing.T) {
 	}
 }
 
+func TestAugmentOrder(t *testing.T) {
+	inModules := map[string]string{
+		"target": `
+			module target {
+				prefix "t";
+				namespace "urn:t";
+				container c;
+			}`,
+		"zulu": `
+			module zulu {
+				prefix "z";
+				namespace "urn:z";
+				import target { prefix t; }
+				augment /t:c { leaf z1 { type string; } }
+				augment /t:c { leaf z2 { type string; } }
+			}`,
+		"alpha": `
+			module alpha {
+				prefix "a";
+				namespace "urn:a";
+				import target { prefix t; }
+				augment /t:c { leaf a1 { type string; } }
+			}`,
+		"mike": `
+			module mike {
+				prefix "m";
+				namespace "urn:m";
+				import target { prefix t; }
+				augment /t:c { leaf m1 { type string; } }
+			}`,
+	}
+
+	// Map iteration order differs between runs so process the modules
+	// several times to make sure the order does not depend on it.
+	for i := 0; i < 10; i++ {
+		ms := NewModules()
+		for name, text := range inModules {
+			if err := ms.Parse(text, name+".yang"); err != nil {
+				t.Fatalf("cannot parse module %s: %v", name, err)
+			}
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("cannot process modules: %v", errs)
+		}
+		c := ToEntry(ms.Modules["target"]).Dir["c"]
+
+		var got []string
+		for _, a := range c.Augmented {
+			for name := range a.Dir {
+				got = append(got, name)
+			}
+		}
+		if diff := cmp.Diff([]string{"a1", "m1", "z1", "z2"}, got); diff != "" {
+			t.Fatalf("run %d: Augmented (-want, +got):\n%s", i, diff)
+		}
+
+		for name, want := range map[string]string{"a1": "urn:a", "m1": "urn:m", "z1": "urn:z", "z2": "urn:z"} {
+			a := c.Dir[name].AugmentedBy()
+			if a == nil {
+				t.Errorf("%s: AugmentedBy() is nil", name)
+				continue
+			}
+			if got := a.Namespace().Name; got != want {
+				t.Errorf("%s: AugmentedBy().Namespace() got %s, want %s", name, got, want)
+			}
+			if _, ok := a.Node.(*Augment); !ok {
+				t.Errorf("%s: AugmentedBy().Node is %T, want *Augment", name, a.Node)
+			}
+		}
+		if a := c.AugmentedBy(); a != nil {
+			t.Errorf("c: AugmentedBy() got %v, want nil", a.Node)
+		}
+	}
+}
+
+func TestAugmentCollision(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inModules        map[string]string
+		wantErrSubstring []string
+	}{{
+		desc: "two augments adding the same leaf",
+		inModules: map[string]string{
+			"base": `
+				module base {
+					prefix "b";
+					namespace "urn:b";
+					container c {
+						leaf x { type string; }
+					}
+				}`,
+			"one": `
+				module one {
+					prefix "o";
+					namespace "urn:o";
+					import base { prefix b; }
+					augment /b:c {
+						leaf added { type string; }
+					}
+				}`,
+			"two": `
+				module two {
+					prefix "t";
+					namespace "urn:t";
+					import base { prefix b; }
+					augment /b:c {
+						leaf added { type int8; }
+					}
+				}`,
+		},
+		wantErrSubstring: []string{`two.yang:6:6: Duplicate node "added" in "c"`},
+	}, {
+		desc: "augment adding a leaf already in the target",
+		inModules: map[string]string{
+			"base": `
+				module base {
+					prefix "b";
+					namespace "urn:b";
+					container c {
+						leaf x { type string; }
+					}
+				}`,
+			"one": `
+				module one {
+					prefix "o";
+					namespace "urn:o";
+					import base { prefix b; }
+					augment /b:c {
+						leaf x { type string; }
+					}
+				}`,
+		},
+		wantErrSubstring: []string{`one.yang:6:6: Duplicate node "x" in "c"`},
+	}, {
+		desc: "uses adding a leaf already present",
+		inModules: map[string]string{
+			"base": `
+				module base {
+					prefix "b";
+					namespace "urn:b";
+					grouping g {
+						leaf x { type string; }
+					}
+					container c {
+						leaf x { type string; }
+						uses g;
+					}
+				}`,
+		},
+		wantErrSubstring: []string{"base.yang:8:6: duplicate key from base.yang:9:7: x"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			for n, m := range tt.inModules {
+				if err := ms.Parse(m, n+".yang"); err != nil {
+					t.Fatalf("cannot parse module %s: %v", n, err)
+				}
+			}
+			errs := ms.Process()
+			if len(errs) != len(tt.wantErrSubstring) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.wantErrSubstring), errs)
+			}
+			for i, err := range errs {
+				if diff := errdiff.Substring(err, tt.wantErrSubstring[i]); diff != "" {
+					t.Errorf("Process: %s", diff)
+				}
+			}
+		})
+	}
+}
+
 func TestUsesEntry(t *testing.T) {
 	ms := NewModules()
 	ms.ParseOptions.StoreUses = true
@@ -1837,6 +2156,8 @@ func TestActionRPC(t *testing.T) {
   namespace "urn:test";
   prefix "test";
   list list {
+    key "k";
+    leaf k { type string; }
     action operation {
       description "action";
       input { leaf string { type string; } }
@@ -1878,7 +2199,7 @@ func TestActionRPC(t *testing.T) {
       output { leaf string { type string; } }
     }
   }
-  list list { uses g; }
+  list list { key "k"; leaf k { type string; } uses g; }
 }`,
 		},
 
@@ -2105,6 +2426,8 @@ var testIfFeatureModules = []struct {
   }
 
   list ls {
+    key "k";
+    leaf k { type string; }
     if-feature ft-list;
   }
 
@@ -2130,12 +2453,87 @@ var testIfFeatureModules = []struct {
       if-feature ft-refine;
     }
   }
-  grouping g {}
+  grouping g {
+    leaf rf { type string; }
+  }
 }
 `,
 	},
 }
 
+func TestRPCWithoutInputOutput(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			yang-version 1.1;
+			prefix "d";
+			namespace "urn:d";
+
+			grouping g {
+				action act;
+				action act-in {
+					input { leaf x { type string; } }
+				}
+			}
+			container a { uses g; }
+			container b { uses g; }
+			rpc bare;
+			rpc out-only {
+				output { leaf y { type string; } }
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	dev := ToEntry(ms.Modules["dev"])
+
+	tests := []struct {
+		path       string
+		wantInput  bool
+		wantOutput bool
+	}{
+		{"bare", false, false},
+		{"out-only", false, true},
+		{"a/act", false, false},
+		{"b/act", false, false},
+		{"a/act-in", true, false},
+		{"b/act-in", true, false},
+	}
+	for _, tt := range tests {
+		e := dev.Find(tt.path)
+		if e == nil {
+			t.Errorf("%s: not found", tt.path)
+			continue
+		}
+		if e.RPC == nil {
+			t.Errorf("%s: RPC is nil", tt.path)
+			continue
+		}
+		if got := e.RPC.Input != nil; got != tt.wantInput {
+			t.Errorf("%s: got input %v, want %v", tt.path, got, tt.wantInput)
+		}
+		if got := e.RPC.Output != nil; got != tt.wantOutput {
+			t.Errorf("%s: got output %v, want %v", tt.path, got, tt.wantOutput)
+		}
+		if in := e.RPC.Input; in != nil && in.Parent != e {
+			t.Errorf("%s: input has parent %s, want %s", tt.path, in.Parent.Path(), e.Path())
+		}
+		if got := dev.Find(tt.path + "/nope"); got != nil {
+			t.Errorf("%s: Find of a child that is not input or output got %s, want nil", tt.path, got.Path())
+		}
+	}
+
+	if got, want := dev.Find("b/act-in/input/x").Path(), "/dev/b/act-in/input/x"; got != want {
+		t.Errorf("got path %s, want %s", got, want)
+	}
+
+	// Printing must not fail on missing input or output.
+	var buf bytes.Buffer
+	dev.Print(&buf)
+}
+
 func TestIfFeature(t *testing.T) {
 	entryIfFeatures := func(e *Entry) []*Value {
 		extra := e.Extra["if-feature"]
@@ -2306,6 +2704,8 @@ var testNotificationModules = []struct {
   }
 
   list ls {
+    key "k";
+    leaf k { type string; }
     notification ls-n {}
     uses g;
   }
@@ -2719,6 +3119,42 @@ func TestEntryTypes(t *testing.T) {
 		},
 	}
 
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			yang-version 1.1;
+			prefix "d";
+			namespace "urn:d";
+
+			container c {
+				action reset;
+				list l {
+					key "k";
+					leaf k { type string; }
+				}
+			}
+			rpc r;
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	dev := ToEntry(ms.Modules["dev"])
+	const None SchemaType = "None"
+	tests = append(tests, []struct {
+		desc     string
+		schema   *Entry
+		wantType SchemaType
+	}{
+		{desc: "parsed module", schema: dev, wantType: None},
+		{desc: "parsed container", schema: dev.Dir["c"], wantType: Container},
+		{desc: "parsed list", schema: dev.Dir["c"].Dir["l"], wantType: List},
+		{desc: "parsed key", schema: dev.Dir["c"].Dir["l"].Dir["k"], wantType: Leaf},
+		{desc: "parsed action", schema: dev.Dir["c"].Dir["reset"], wantType: None},
+		{desc: "parsed rpc", schema: dev.Dir["r"], wantType: None},
+	}...)
+
 	for _, tt := range tests {
 		gotm := map[SchemaType]bool{
 			Leaf:      tt.schema.IsLeaf(),
@@ -3335,9 +3771,119 @@ func TestDeviation(t *testing.T) {
 			}},
 		},
 	}, {
-		// TODO(wenovus): Support deviate delete for leaf-lists for config-false leafs once its semantics are clear.
-		// https://github.com/mbj4668/pyang/issues/756
-		desc: "error case - deviation delete on a leaf-list",
+		desc: "deviation replace and delete of defaults",
+		inFiles: map[string]string{
+			"deviate": `
+				module deviate {
+					prefix "d";
+					namespace "urn:d";
+
+					leaf a {
+						type string;
+						default "fish";
+					}
+					leaf b {
+						type string;
+						default "fish";
+					}
+
+					deviation /a {
+						deviate replace {
+							default "chips";
+						}
+					}
+					deviation /b {
+						deviate delete {
+							default "fish";
+						}
+					}
+				}`,
+		},
+		wants: map[string][]deviationTest{
+			"deviate": {{
+				path:  "/a",
+				entry: &Entry{Default: []string{"chips"}},
+			}, {
+				path:  "/b",
+				entry: &Entry{},
+			}},
+		},
+	}, {
+		desc: "deviation add, replace and delete of several defaults of leaf-lists",
+		inFiles: map[string]string{
+			"deviate": `
+				module deviate {
+					prefix "d";
+					namespace "urn:d";
+
+					leaf-list a {
+						type string;
+					}
+					leaf-list b {
+						type string;
+						default "fish";
+					}
+					leaf-list c {
+						type string;
+						default "fish";
+						default "sticks";
+						default "chips";
+					}
+
+					deviation /a {
+						deviate add {
+							default "fish";
+							default "chips";
+						}
+					}
+					deviation /b {
+						deviate replace {
+							default "fish";
+							default "sticks";
+						}
+					}
+					deviation /c {
+						deviate delete {
+							default "fish";
+							default "sticks";
+						}
+					}
+				}`,
+		},
+		wants: map[string][]deviationTest{
+			"deviate": {{
+				path:  "/a",
+				entry: &Entry{Default: []string{"fish", "chips"}},
+			}, {
+				path:  "/b",
+				entry: &Entry{Default: []string{"fish", "sticks"}},
+			}, {
+				path:  "/c",
+				entry: &Entry{Default: []string{"chips"}},
+			}},
+		},
+	}, {
+		desc: "error case - deviation replace where the default didn't exist",
+		inFiles: map[string]string{
+			"deviate": `
+				module deviate {
+					prefix "d";
+					namespace "urn:d";
+
+					leaf a {
+						type string;
+					}
+
+					deviation /a {
+						deviate replace {
+							default "fishsticks";
+						}
+					}
+				}`,
+		},
+		wantProcessErrSubstring: "deviate:11:7: tried to deviate replace a default statement that doesn't exist on /deviate/a",
+	}, {
+		desc: "error case - deviation delete on a leaf-list of a default it does not have",
 		inFiles: map[string]string{
 			"deviate": `
 				module deviate {
@@ -3356,7 +3902,7 @@ func TestDeviation(t *testing.T) {
 					}
 				}`,
 		},
-		wantProcessErrSubstring: "deviate delete on default statements unsupported for leaf-lists",
+		wantProcessErrSubstring: `non-matching keyword "fishsticks" on /deviate/a`,
 	}, {
 		desc: "error case - deviation delete of default has different keyword value",
 		inFiles: map[string]string{
@@ -3476,7 +4022,7 @@ func TestDeviation(t *testing.T) {
 					Type: &YangType{
 						Name:    "rstr",
 						Kind:    Ystring,
-						Pattern: []string{"a.*"},
+						Pattern: []*YangPattern{{Expr: "a.*"}},
 					},
 				},
 			}},
@@ -3522,8 +4068,66 @@ func TestDeviation(t *testing.T) {
 				},
 			}},
 		},
-	}}
-
+	}, {
+		desc: "error case - deviation add of a type",
+		inFiles: map[string]string{
+			"deviate": `
+				module deviate {
+					prefix "d";
+					namespace "urn:d";
+
+					leaf a { type string; }
+
+					deviation /a {
+						deviate add {
+							type uint32;
+						}
+					}
+				}`,
+		},
+		wantProcessErrSubstring: "only deviate replace may change a type",
+	}, {
+		desc: "deviation replacing an enumeration with a restricted string",
+		inFiles: map[string]string{
+			"foo": `
+			module foo {
+				prefix "f";
+				namespace "urn:f";
+
+				container a {
+					leaf b {
+						type enumeration {
+							enum one;
+							enum two;
+						}
+					}
+				}
+
+				deviation /a/b {
+					deviate replace {
+						type string {
+							pattern "[a-z]+";
+							length "1..10";
+						}
+					}
+				}
+			}`,
+		},
+		wants: map[string][]deviationTest{
+			"foo": {{
+				path: "/a/b",
+				entry: &Entry{
+					Type: &YangType{
+						Name:    "string",
+						Kind:    Ystring,
+						Pattern: []*YangPattern{{Expr: "[a-z]+"}},
+						Length:  YangRange{R(1, 10)},
+					},
+				},
+			}},
+		},
+	}}
+
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			ms := NewModules()
@@ -3613,6 +4217,20 @@ func TestDeviation(t *testing.T) {
 						if got.Type.Kind != want.entry.Type.Kind {
 							t.Errorf("%d (%s): type kind, got: %s, want: %s", idx, want.path, got.Type.Kind, want.entry.Type.Kind)
 						}
+
+						if want.entry.Type.Pattern != nil {
+							if diff := cmp.Diff(want.entry.Type.Pattern, got.Type.Pattern); diff != "" {
+								t.Errorf("%d (%s): type pattern (-want, +got):\n%s", idx, want.path, diff)
+							}
+						}
+
+						if want.entry.Type.Length != nil && !got.Type.Length.Equal(want.entry.Type.Length) {
+							t.Errorf("%d (%s): type length, got: %v, want: %v", idx, want.path, got.Type.Length, want.entry.Type.Length)
+						}
+
+						if want.entry.Type.Enum == nil && got.Type.Enum != nil {
+							t.Errorf("%d (%s): type has enum values %v, want none", idx, want.path, got.Type.Enum.Names())
+						}
 					}
 
 					if got.Units != want.entry.Units {
@@ -3624,6 +4242,136 @@ func TestDeviation(t *testing.T) {
 	}
 }
 
+func TestDeviateMustUnique(t *testing.T) {
+	const base = `
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			grouping server {
+				list server {
+					key "name";
+					leaf name { type string; }
+					leaf ip { type string; must "../port"; }
+					leaf port { type uint16; }
+					unique "port";
+				}
+			}
+			container a { uses server; }
+			container b { uses server; }
+			leaf mtu { type uint16; }
+	`
+	// extras returns the arguments of the kw statements of e.
+	extras := func(e *Entry, kw string) []string {
+		var args []string
+		for _, v := range e.Extra[kw] {
+			args = append(args, v.(Node).NName())
+		}
+		return args
+	}
+
+	tests := []struct {
+		desc             string
+		inDeviations     string
+		wantMust         map[string][]string // by path
+		wantUnique       map[string][]string // by path
+		wantErrSubstring string
+	}{{
+		desc: "add must and unique",
+		inDeviations: `
+			deviation /a/server/ip {
+				deviate add { must "../name != 'x'"; }
+			}
+			deviation /a/server {
+				deviate add { unique "ip"; }
+			}
+			deviation /mtu {
+				deviate add { must ". > 68"; must ". < 9000"; }
+			}`,
+		wantMust: map[string][]string{
+			"/a/server/ip": {"../port", "../name != 'x'"},
+			"/b/server/ip": {"../port"},
+			"/mtu":         {". > 68", ". < 9000"},
+		},
+		wantUnique: map[string][]string{
+			"/a/server": {"port", "ip"},
+			"/b/server": {"port"},
+		},
+	}, {
+		desc: "delete must and unique",
+		inDeviations: `
+			deviation /b/server/ip {
+				deviate delete { must "../port"; }
+			}
+			deviation /b/server {
+				deviate delete { unique "port"; }
+			}`,
+		wantMust: map[string][]string{
+			"/a/server/ip": {"../port"},
+			"/b/server/ip": nil,
+		},
+		wantUnique: map[string][]string{
+			"/a/server": {"port"},
+			"/b/server": nil,
+		},
+	}, {
+		desc: "added unique is checked",
+		inDeviations: `
+			deviation /a/server {
+				deviate add { unique "address"; }
+			}`,
+		wantErrSubstring: "address not found in /dev/a/server",
+	}, {
+		desc: "unique added to a non-list",
+		inDeviations: `
+			deviation /mtu {
+				deviate add { unique "name"; }
+			}`,
+		wantErrSubstring: "tried to deviate add a unique statement to a non-list entry /dev/mtu",
+	}, {
+		desc: "delete of a must that does not exist",
+		inDeviations: `
+			deviation /mtu {
+				deviate delete { must ". > 68"; }
+			}`,
+		wantErrSubstring: "tried to deviate delete a must statement that doesn't exist",
+	}, {
+		desc: "replace of a must",
+		inDeviations: `
+			deviation /a/server/ip {
+				deviate replace { must "../name"; }
+			}`,
+		wantErrSubstring: "only deviate add and delete may change must statements",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(base+tt.inDeviations+"}", "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("Process: %s", diff)
+			}
+			e := ToEntry(ms.Modules["dev"])
+			for path, want := range tt.wantMust {
+				if diff := cmp.Diff(want, extras(e.Find(path), "must")); diff != "" {
+					t.Errorf("%s: must (-want, +got):\n%s", path, diff)
+				}
+			}
+			for path, want := range tt.wantUnique {
+				if diff := cmp.Diff(want, extras(e.Find(path), "unique")); diff != "" {
+					t.Errorf("%s: unique (-want, +got):\n%s", path, diff)
+				}
+			}
+		})
+	}
+}
+
 func TestLeafEntry(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -3930,3 +4678,574 @@ func TestLess(t *testing.T) {
 		}
 	}
 }
+
+func TestEffectiveUnits(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			typedef octets {
+				type uint64;
+				units "bytes";
+			}
+			typedef counter {
+				type octets;
+			}
+
+			leaf own {
+				type uint8;
+				units "percent";
+			}
+			leaf typed { type octets; }
+			leaf derived { type counter; }
+			leaf override {
+				type octets;
+				units "kilobytes";
+			}
+			leaf-list list {
+				type octets;
+			}
+			leaf none { type string; }
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	for _, tt := range []struct {
+		name      string
+		wantUnits string
+		wantOwn   string
+	}{
+		{"own", "percent", "percent"},
+		{"typed", "bytes", ""},
+		{"derived", "bytes", ""},
+		{"override", "kilobytes", "kilobytes"},
+		{"list", "bytes", ""},
+		{"none", "", ""},
+	} {
+		e := mod.Dir[tt.name]
+		if got := e.EffectiveUnits(); got != tt.wantUnits {
+			t.Errorf("%s: EffectiveUnits: got %q, want %q", tt.name, got, tt.wantUnits)
+		}
+		if e.Units != tt.wantOwn {
+			t.Errorf("%s: Units: got %q, want %q", tt.name, e.Units, tt.wantOwn)
+		}
+	}
+}
+
+func TestEntryNotifications(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			yang-version 1.1;
+			prefix "d";
+			namespace "urn:d";
+
+			grouping link-events {
+				notification link-up;
+			}
+
+			notification restart;
+			container interfaces {
+				list interface {
+					key "name";
+					leaf name { type string; }
+					notification link-down {
+						leaf reason { type string; }
+					}
+					uses link-events;
+				}
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	names := func(ns map[string]*Entry) []string {
+		var got []string
+		for n, e := range ns {
+			got = append(got, n+" "+e.Parent.Path())
+		}
+		sort.Strings(got)
+		return got
+	}
+	if diff := cmp.Diff([]string{"restart /dev"}, names(mod.Notifications())); diff != "" {
+		t.Errorf("module Notifications (-want, +got):\n%s", diff)
+	}
+	if got := mod.Dir["interfaces"].Notifications(); got != nil {
+		t.Errorf("container Notifications: got %v, want nil", got)
+	}
+	list := mod.Dir["interfaces"].Dir["interface"]
+	want := []string{
+		"link-down /dev/interfaces/interface",
+		"link-up /dev/interfaces/interface",
+	}
+	if diff := cmp.Diff(want, names(list.Notifications())); diff != "" {
+		t.Errorf("list Notifications (-want, +got):\n%s", diff)
+	}
+	if got := list.Notifications()["link-down"].Dir["reason"]; got == nil || !got.IsLeaf() {
+		t.Errorf("link-down payload: got %v, want leaf reason", got)
+	}
+}
+
+func TestFullDescription(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			container both {
+				description "The system.";
+				reference "RFC 7317";
+			}
+			leaf description-only {
+				type string;
+				description "A name.";
+			}
+			leaf reference-only {
+				type string;
+				reference "RFC 7950 section 9.4";
+			}
+			leaf-list neither { type string; }
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	for _, tt := range []struct {
+		name          string
+		wantReference string
+		wantFull      string
+	}{
+		{"both", "RFC 7317", "The system.\n\nReference: RFC 7317"},
+		{"description-only", "", "A name."},
+		{"reference-only", "RFC 7950 section 9.4", "Reference: RFC 7950 section 9.4"},
+		{"neither", "", ""},
+	} {
+		e := mod.Dir[tt.name]
+		if got := e.Reference(); got != tt.wantReference {
+			t.Errorf("%s: Reference: got %q, want %q", tt.name, got, tt.wantReference)
+		}
+		if got := e.FullDescription(); got != tt.wantFull {
+			t.Errorf("%s: FullDescription: got %q, want %q", tt.name, got, tt.wantFull)
+		}
+	}
+}
+
+func TestAugmentChoice(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a": `
+			module a {
+				prefix "a";
+				namespace "urn:a";
+
+				container c {
+					choice ch {
+						case one {
+							leaf one { type string; }
+						}
+						leaf two { type string; }
+					}
+				}
+			}`,
+		"b": `
+			module b {
+				prefix "b";
+				namespace "urn:b";
+
+				import a { prefix a; }
+
+				augment /a:c/a:ch {
+					case three {
+						leaf three { type string; }
+					}
+					leaf four { type int8; }
+				}
+				augment /a:c/a:ch/a:one {
+					leaf extra { type string; }
+				}
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	ch := ToEntry(ms.Modules["a"]).Dir["c"].Dir["ch"]
+	var cases []string
+	for name, c := range ch.Dir {
+		if !c.IsCase() {
+			t.Errorf("%s: got kind %v, want case", c.Path(), c.Kind)
+		}
+		if c.Parent != ch {
+			t.Errorf("%s: parent is not the choice", c.Path())
+		}
+		cases = append(cases, name)
+	}
+	sort.Strings(cases)
+	if diff := cmp.Diff([]string{"four", "one", "three", "two"}, cases); diff != "" {
+		t.Errorf("cases (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		path   string
+		wantNS string
+	}{
+		{"/a/c/ch/three/three", "urn:b"},
+		{"/a/c/ch/four/four", "urn:b"},
+		{"/a/c/ch/one/extra", "urn:b"},
+		{"/a/c/ch/one/one", "urn:a"},
+	} {
+		e, err := ms.ResolvePath(tt.path)
+		if err != nil {
+			t.Errorf("ResolvePath(%q): %v", tt.path, err)
+			continue
+		}
+		if got := e.Namespace().Name; got != tt.wantNS {
+			t.Errorf("%s: got namespace %q, want %q", tt.path, got, tt.wantNS)
+		}
+	}
+	if got := ch.Dir["three"].AugmentedBy(); got == nil || got.Namespace().Name != "urn:b" {
+		t.Errorf("case three: got AugmentedBy %v, want the augment in b", got)
+	}
+}
+
+func TestAugmentWhen(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"a": `
+			module a {
+				prefix "a";
+				namespace "urn:a";
+
+				container c {
+					leaf type { type string; }
+				}
+			}`,
+		"b": `
+			module b {
+				prefix "b";
+				namespace "urn:b";
+
+				import a { prefix a; }
+
+				augment /a:c {
+					when "a:type = 'b'" {
+						description "only for type b";
+					}
+					leaf plain { type string; }
+					leaf guarded {
+						when "../plain = 'x'";
+						type string;
+					}
+					container inner {
+						leaf deep { type string; }
+					}
+				}
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	augmentWhen := &WhenStatement{
+		Expr:        "a:type = 'b'",
+		Description: "only for type b",
+		FromAugment: true,
+	}
+	for _, tt := range []struct {
+		path string
+		want []*WhenStatement
+	}{
+		{"/a/c/type", nil},
+		{"/a/c/plain", []*WhenStatement{augmentWhen}},
+		{"/a/c/guarded", []*WhenStatement{{Expr: "../plain = 'x'"}, augmentWhen}},
+		{"/a/c/inner", []*WhenStatement{augmentWhen}},
+		// Descendants of the nodes the augment adds are conditional
+		// through their ancestors.
+		{"/a/c/inner/deep", nil},
+	} {
+		e, err := ms.ResolvePath(tt.path)
+		if err != nil {
+			t.Errorf("ResolvePath(%q): %v", tt.path, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, e.WhenConditions()); diff != "" {
+			t.Errorf("%s: WhenConditions (-want, +got):\n%s", tt.path, diff)
+		}
+	}
+}
+
+func TestListKeyName(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module a {
+			prefix "a";
+			namespace "urn:a";
+			list single { key "k"; leaf k { type string; } }
+			list multiple { key "k1 k2"; leaf k1 { type string; } leaf k2 { type string; } }
+			list keyless { config false; leaf v { type string; } }
+			container c { leaf l { type string; } }
+			leaf-list ll { type string; }
+		}`, "a.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	e := ToEntry(ms.Modules["a"])
+
+	for _, tt := range []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"single", "k", true},
+		{"multiple", "", false},
+		{"keyless", "", false},
+		{"c", "", false},
+		{"ll", "", false},
+	} {
+		got, ok := e.Dir[tt.name].ListKeyName()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: ListKeyName: got %q, %t, want %q, %t", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRefine(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			grouping g {
+				leaf a { type string; description "a"; }
+				choice ch { leaf b { type string; } }
+				leaf-list ll { type string; }
+				list l { key k; max-elements 5; leaf k { type string; } }
+			}
+			container c {
+				uses g {
+					refine m:a { description "refined"; config false; }
+					refine ch/b/b { default "x"; }
+					refine ll { min-elements 1; }
+					refine l { min-elements 2; max-elements unbounded; }
+				}
+			}
+			container d { uses g; }
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["m"])
+	a := e.Dir["c"].Dir["a"]
+	if a.Description != "refined" || a.Config != TSFalse {
+		t.Errorf("refined /c/a: got description %q, config %v, want refined, false", a.Description, a.Config)
+	}
+	if got := e.Dir["c"].Dir["ch"].Dir["b"].Dir["b"].Default; len(got) != 1 || got[0] != "x" {
+		t.Errorf("refined /c/ch/b/b: got default %v, want [x]", got)
+	}
+	if a := e.Dir["d"].Dir["a"]; a.Description != "a" || a.Config != TSUnset {
+		t.Errorf("unrefined /d/a: got description %q, config %v, want a, unset", a.Description, a.Config)
+	}
+	for _, tt := range []struct {
+		path     string
+		min, max uint64
+	}{
+		{"c/ll", 1, math.MaxUint64},
+		{"c/l", 2, math.MaxUint64},
+		{"d/ll", 0, math.MaxUint64},
+		{"d/l", 0, 5},
+	} {
+		la := e.Find(tt.path).ListAttr
+		if la.MinElements != tt.min || la.MaxElements != tt.max {
+			t.Errorf("%s: got min-elements %d, max-elements %d, want %d, %d", tt.path, la.MinElements, la.MaxElements, tt.min, tt.max)
+		}
+	}
+
+	ms = NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			grouping g { leaf a { type string; } }
+			uses g { refine b { mandatory true; } }
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) != 1 || errs[0].Error() != "m.yang:6:13: refine target b not found in grouping g" {
+		t.Errorf("Process with an unknown refine target: got %v", errs)
+	}
+	ms = NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			grouping g { leaf a { type string; } }
+			uses g { refine a { min-elements 1; } }
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs = ms.Process()
+	if len(errs) != 1 || errs[0].Error() != "m.yang:6:13: refine of min-elements or max-elements of a, which is not a list or leaf-list" {
+		t.Errorf("Process with a min-elements refine of a leaf: got %v", errs)
+	}
+}
+
+func TestMandatoryDescendants(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			grouping addressing {
+				leaf address { type string; mandatory true; }
+				leaf mask { type uint8; }
+			}
+			container c {
+				leaf a { type string; mandatory true; }
+				leaf optional { type string; }
+				container ip {
+					leaf enabled { type boolean; mandatory true; }
+					uses addressing;
+					leaf mtu { type uint16; mandatory true; }
+				}
+				container np {
+					leaf b { type string; mandatory true; }
+					leaf-list ll { type string; min-elements 1; }
+				}
+				container p {
+					presence "enables p";
+					leaf c { type string; mandatory true; }
+				}
+				list l {
+					key k;
+					min-elements 2;
+					leaf k { type string; }
+					leaf d { type string; mandatory true; }
+				}
+				list optional-list {
+					key k;
+					leaf k { type string; }
+				}
+				choice ch {
+					mandatory true;
+					leaf e { type string; mandatory true; }
+				}
+				choice optional-choice {
+					leaf f { type string; mandatory true; }
+				}
+				anydata any { mandatory true; }
+				container state {
+					config false;
+					leaf g { type string; mandatory true; }
+				}
+				action act {
+					input { leaf h { type string; mandatory true; } }
+					output { leaf i { type string; mandatory true; } }
+				}
+			}
+			rpc r {
+				input {
+					leaf j { type string; mandatory true; }
+					container np { leaf k { type string; mandatory true; } }
+				}
+				output { leaf o { type string; mandatory true; } }
+			}
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["m"])
+
+	paths := func(es []*Entry) []string {
+		var ps []string
+		for _, e := range es {
+			ps = append(ps, e.Path())
+		}
+		return ps
+	}
+	for _, tt := range []struct {
+		path string
+		want []string
+	}{
+		{"/c", []string{"/m/c/a", "/m/c/ip/enabled", "/m/c/ip/address", "/m/c/ip/mtu", "/m/c/np/b", "/m/c/np/ll", "/m/c/l", "/m/c/ch", "/m/c/any"}},
+		{"/c/p", []string{"/m/c/p/c"}},
+		{"/c/ip", []string{"/m/c/ip/enabled", "/m/c/ip/address", "/m/c/ip/mtu"}},
+		{"/c/l", []string{"/m/c/l/k", "/m/c/l/d"}},
+		{"/c/state", []string{"/m/c/state/g"}},
+		{"/c/act", []string{"/m/c/act/input/h"}},
+		{"/r", []string{"/m/r/input/j", "/m/r/input/np/k"}},
+		{"/r/output", []string{"/m/r/output/o"}},
+		{"/c/optional-list", []string{"/m/c/optional-list/k"}},
+		{"/c/np/ll", nil},
+	} {
+		if diff := cmp.Diff(tt.want, paths(e.Find(tt.path).MandatoryDescendants())); diff != "" {
+			t.Errorf("%s: MandatoryDescendants (-want, +got):\n%s", tt.path, diff)
+		}
+	}
+}
+
+func TestErrorSort(t *testing.T) {
+	errs := []error{
+		errors.New("b.yang:2:1: second"),
+		fmt.Errorf("a.yang:10:3: %s", "third"),
+		errors.New("a.yang:2:5: first"),
+		errors.New("a.yang:10:3: third"),
+		errors.New("b.yang:2:1: second"),
+	}
+	var got []string
+	for _, err := range errorSort(errs) {
+		got = append(got, err.Error())
+	}
+	want := []string{"a.yang:2:5: first", "a.yang:10:3: third", "b.yang:2:1: second"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("errorSort (-want, +got):\n%s", diff)
+	}
+}
+
+func TestBrokenTypedefErrors(t *testing.T) {
+	ms := NewModules()
+	ms.ParseOptions.ContinueOnError = true
+	if err := ms.Parse(`module m { prefix "m"; namespace "urn:m";
+		typedef t { type int8 { range "1..1000"; } }
+		leaf a { type t; }
+		leaf b { type t; }
+		container c { leaf d { type t; } leaf-list e { type t; } }
+	}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs := ms.Process()
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1: %v", len(errs), errs)
+	}
+	if diff := errdiff.Substring(errs[0], "m.yang:2:27: bad range"); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the comparison of Entry trees for equality, e.g., of
+// the result of a transformation of a tree against a golden tree in a test.
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EntriesEqual reports whether the Entry trees a and b are the same and, if
+// they are not, describes the first difference found, prefixed by the path
+// of the node in a where it was found.  The name, kind, description, default,
+// units, config, mandatory, prefix, key, type and list attributes of the
+// nodes are compared, as are their children, matched by name, and the input
+// and output of RPCs and actions.  Types are compared by value, as by
+// CompareTypes, along with their names, units and defaults, so the trees may
+// come from different calls to Process.  The Parent of a and b, the Nodes the
+// Entries were derived from, and their extensions, errors, annotations and
+// Extra statements are not compared.
+func EntriesEqual(a, b *Entry) (bool, string) {
+	if diff := entryDiff(a, b); diff != "" {
+		return false, diff
+	}
+	return true, ""
+}
+
+// entryDiff returns the first difference between a and b, or "" if there is
+// none.
+func entryDiff(a, b *Entry) string {
+	switch {
+	case a == nil && b == nil:
+		return ""
+	case a == nil:
+		return fmt.Sprintf("%s: missing in a", b.Path())
+	case b == nil:
+		return fmt.Sprintf("%s: missing in b", a.Path())
+	}
+	differs := func(field string, x, y interface{}) string {
+		return fmt.Sprintf("%s: %s differs: %q != %q", a.Path(), field, x, y)
+	}
+	valueName := func(v *Value) string {
+		if v == nil {
+			return ""
+		}
+		return v.Name
+	}
+	switch {
+	case a.Name != b.Name:
+		return differs("name", a.Name, b.Name)
+	case a.Kind != b.Kind:
+		return differs("kind", a.Kind.String(), b.Kind.String())
+	case a.Description != b.Description:
+		return differs("description", a.Description, b.Description)
+	case !ssEqual(a.Default, b.Default):
+		return differs("default", a.Default, b.Default)
+	case a.Units != b.Units:
+		return differs("units", a.Units, b.Units)
+	case a.Config != b.Config:
+		return differs("config", a.Config.String(), b.Config.String())
+	case a.Mandatory != b.Mandatory:
+		return differs("mandatory", a.Mandatory.String(), b.Mandatory.String())
+	case valueName(a.Prefix) != valueName(b.Prefix):
+		return differs("prefix", valueName(a.Prefix), valueName(b.Prefix))
+	case a.Key != b.Key:
+		return differs("key", a.Key, b.Key)
+	}
+	if diff := typeDiff(a.Type, b.Type); diff != "" {
+		return fmt.Sprintf("%s: type differs: %s", a.Path(), diff)
+	}
+
+	switch la, lb := a.ListAttr, b.ListAttr; {
+	case la == nil && lb == nil:
+	case la == nil:
+		return fmt.Sprintf("%s: list attributes missing in a", a.Path())
+	case lb == nil:
+		return fmt.Sprintf("%s: list attributes missing in b", a.Path())
+	case la.MinElements != lb.MinElements:
+		return differs("min-elements", fmt.Sprint(la.MinElements), fmt.Sprint(lb.MinElements))
+	case la.MaxElements != lb.MaxElements:
+		return differs("max-elements", fmt.Sprint(la.MaxElements), fmt.Sprint(lb.MaxElements))
+	case valueName(la.OrderedBy) != valueName(lb.OrderedBy):
+		return differs("ordered-by", valueName(la.OrderedBy), valueName(lb.OrderedBy))
+	}
+
+	switch {
+	case a.RPC == nil && b.RPC == nil:
+	case a.RPC == nil:
+		return fmt.Sprintf("%s: operation missing in a", a.Path())
+	case b.RPC == nil:
+		return fmt.Sprintf("%s: operation missing in b", a.Path())
+	default:
+		if diff := entryDiff(a.RPC.Input, b.RPC.Input); diff != "" {
+			return diff
+		}
+		if diff := entryDiff(a.RPC.Output, b.RPC.Output); diff != "" {
+			return diff
+		}
+	}
+
+	names := map[string]bool{}
+	for name := range a.Dir {
+		names[name] = true
+	}
+	for name := range b.Dir {
+		names[name] = true
+	}
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		ca, cb := a.Dir[name], b.Dir[name]
+		switch {
+		case ca == nil:
+			return fmt.Sprintf("%s: child %s missing in a", a.Path(), name)
+		case cb == nil:
+			return fmt.Sprintf("%s: child %s missing in b", a.Path(), name)
+		}
+		if diff := entryDiff(ca, cb); diff != "" {
+			return diff
+		}
+	}
+	return ""
+}
+
+// typeDiff returns the first difference between the types a and b, or "" if
+// there is none.
+func typeDiff(a, b *YangType) string {
+	switch {
+	case a == nil && b == nil:
+		return ""
+	case a == nil:
+		return "missing in a"
+	case b == nil:
+		return "missing in b"
+	case a.Name != b.Name:
+		return fmt.Sprintf("name %q != %q", a.Name, b.Name)
+	case a.Units != b.Units:
+		return fmt.Sprintf("units %q != %q", a.Units, b.Units)
+	case a.HasDefault != b.HasDefault || a.Default != b.Default:
+		return fmt.Sprintf("default %q != %q", a.Default, b.Default)
+	}
+	if changes := CompareTypes(a, b); len(changes) > 0 {
+		return changes[0].Message
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import "testing"
+
+func TestEntriesEqual(t *testing.T) {
+	const base = `
+		identity id;
+		container c {
+			description "c";
+			leaf a { type int8 { range "1..10"; } default 1; }
+			leaf i { type identityref { base id; } }
+			leaf-list ll { type string; max-elements 3; }
+		}
+		rpc r { input { leaf x { type string; } } }`
+
+	tests := []struct {
+		desc string
+		b    string
+		want string
+	}{{
+		desc: "equal",
+		b:    base,
+	}, {
+		desc: "description changed",
+		b: `
+		identity id;
+		container c {
+			description "changed";
+			leaf a { type int8 { range "1..10"; } default 1; }
+			leaf i { type identityref { base id; } }
+			leaf-list ll { type string; max-elements 3; }
+		}
+		rpc r { input { leaf x { type string; } } }`,
+		want: `/m/c: description differs: "c" != "changed"`,
+	}, {
+		desc: "type range changed",
+		b: `
+		identity id;
+		container c {
+			description "c";
+			leaf a { type int8 { range "1..5"; } default 1; }
+			leaf i { type identityref { base id; } }
+			leaf-list ll { type string; max-elements 3; }
+		}
+		rpc r { input { leaf x { type string; } } }`,
+		want: "/m/c/a: type differs: range narrowed from 1..10 to 1..5",
+	}, {
+		desc: "max-elements changed",
+		b: `
+		identity id;
+		container c {
+			description "c";
+			leaf a { type int8 { range "1..10"; } default 1; }
+			leaf i { type identityref { base id; } }
+			leaf-list ll { type string; }
+		}
+		rpc r { input { leaf x { type string; } } }`,
+		want: `/m/c/ll: max-elements differs: "3" != "18446744073709551615"`,
+	}, {
+		desc: "child removed",
+		b: `
+		identity id;
+		container c {
+			description "c";
+			leaf a { type int8 { range "1..10"; } default 1; }
+			leaf-list ll { type string; max-elements 3; }
+		}
+		rpc r { input { leaf x { type string; } } }`,
+		want: "/m/c: child i missing in b",
+	}, {
+		desc: "input changed",
+		b: `
+		identity id;
+		container c {
+			description "c";
+			leaf a { type int8 { range "1..10"; } default 1; }
+			leaf i { type identityref { base id; } }
+			leaf-list ll { type string; max-elements 3; }
+		}
+		rpc r { input { leaf x { type string; units "s"; } } }`,
+		want: `/m/r/input/x: units differs: "" != "s"`,
+	}}
+
+	entry := func(t *testing.T, body string) *Entry {
+		t.Helper()
+		ms := NewModules()
+		if err := ms.Parse(`module m { prefix "m"; namespace "urn:m"; `+body+` }`, "m.yang"); err != nil {
+			t.Fatalf("cannot parse module: %v", err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("cannot process module: %v", errs)
+		}
+		return ToEntry(ms.Modules["m"])
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			equal, diff := EntriesEqual(entry(t, base), entry(t, tt.b))
+			if equal != (tt.want == "") || diff != tt.want {
+				t.Errorf("EntriesEqual: got %t, %q, want %t, %q", equal, diff, tt.want == "", tt.want)
+			}
+		})
+	}
+}
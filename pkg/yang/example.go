@@ -0,0 +1,290 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the generation of example values of types, for use in
+// documentation and test data.
+
+import (
+	"encoding/base64"
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxExampleRepeat is the largest number of times ExampleValue repeats the
+// repeated part of a pattern when looking for a string of an allowed length.
+const maxExampleRepeat = 64
+
+// ExampleValue returns a plausible value of type t, encoded per RFC7951 in the
+// form used by encoding/json and accepted by Entry.Validate:
+//
+//   - integers and decimal64 values are in the middle of the first range of
+//     t, as a json.Number for integers of 32 bits or less and as a string
+//     otherwise,
+//   - strings are of an allowed length and, on a best-effort basis, conform to
+//     the patterns of t,
+//   - binary values are base64 encoded and of an allowed length,
+//   - booleans are true and empty values are [null],
+//   - enumerations have the name with the lowest value and bits the name
+//     of the bit with the lowest position,
+//   - identityrefs are the first, by module and name, of the identities
+//     derived from their base,
+//   - unions have the value of the first member type that has one.
+//
+// nil is returned if no value can be determined, as for leafrefs and
+// instance-identifiers, whose values depend on the data tree, and for enum,
+// bits and identityref types without any values.
+func (t *YangType) ExampleValue() interface{} {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind {
+	case Yint8, Yint16, Yint32, Yuint8, Yuint16, Yuint32, Yint64, Yuint64, Ydecimal64:
+		return defaultJSON(t, exampleNumber(t))
+	case Ystring:
+		return exampleString(t)
+	case Ybinary:
+		n := exampleLength(t, 4)
+		return base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", n)))
+	case Ybool:
+		return true
+	case Yempty:
+		return []interface{}{nil}
+	case Yenum:
+		if t.Enum != nil {
+			if vs := t.Enum.Values(); len(vs) > 0 {
+				return t.Enum.Name(vs[0])
+			}
+		}
+	case Ybits:
+		if t.Bit != nil {
+			if vs := t.Bit.Values(); len(vs) > 0 {
+				return t.Bit.Name(vs[0])
+			}
+		}
+	case Yidentityref:
+		if t.IdentityBase == nil {
+			return nil
+		}
+		var names []string
+		for _, id := range t.IdentityBase.Values {
+			if m := module(id); m != nil {
+				names = append(names, m.Name+":"+id.Name)
+			}
+		}
+		sort.Strings(names)
+		if len(names) > 0 {
+			return names[0]
+		}
+	case Yunion:
+		for _, ut := range t.Type {
+			if v := ut.ExampleValue(); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// exampleNumber returns the number in the middle of the first range of the
+// numeric type t, rounded towards zero to the precision of t.
+func exampleNumber(t *YangType) string {
+	if len(t.Range) == 0 {
+		if t.Kind == Ydecimal64 && t.FractionDigits > 0 {
+			return "0." + strings.Repeat("0", t.FractionDigits)
+		}
+		return "0"
+	}
+	r := t.Range[0]
+	mid := new(big.Rat).Add(numberRat(r.Min), numberRat(r.Max))
+	mid.Quo(mid, big.NewRat(2, 1))
+
+	digits := 0
+	if t.Kind == Ydecimal64 {
+		digits = t.FractionDigits
+	}
+	// Truncating mid to the precision of the bounds of the range, which
+	// are at most digits, keeps it within the range.
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	scaled := new(big.Int).Mul(mid.Num(), scale)
+	scaled.Quo(scaled, mid.Denom())
+	return new(big.Rat).SetFrac(scaled, scale).FloatString(digits)
+}
+
+// numberRat returns n as a big.Rat.
+func numberRat(n Number) *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.FractionDigits)), nil)
+	r := new(big.Rat).SetFrac(new(big.Int).SetUint64(n.Value), denom)
+	if n.Negative {
+		r.Neg(r)
+	}
+	return r
+}
+
+// exampleLength returns the allowed length of values of t closest to want.
+func exampleLength(t *YangType, want int) int {
+	if len(t.Length) == 0 {
+		return want
+	}
+	r := t.Length[0]
+	if min := int(r.Min.Value); want < min {
+		return min
+	}
+	if r.Max.Value < uint64(want) {
+		return int(r.Max.Value)
+	}
+	return want
+}
+
+// exampleString returns a string of an allowed length for the string type t
+// matching its patterns, if one can be found.  Otherwise a string of an
+// allowed length is returned.
+func exampleString(t *YangType) string {
+	// XSD patterns are anchored at both ends, POSIX patterns need not be.
+	// Examples are generated from the patterns values must match, and
+	// checked against all of them.
+	var patterns []*YangPattern
+	var res []*regexp.Regexp
+	var trees []*syntax.Regexp
+	for _, p := range t.Pattern {
+		if _, err := p.Matches(""); err != nil {
+			continue
+		}
+		patterns = append(patterns, p)
+		if p.InvertMatch {
+			continue
+		}
+		if tree, err := syntax.Parse(p.Expr, syntax.Perl); err == nil {
+			trees = append(trees, tree)
+		}
+	}
+	for _, p := range t.POSIXPattern {
+		if re, err := regexp.CompilePOSIX(p); err == nil {
+			res = append(res, re)
+			if tree, err := syntax.Parse(p, syntax.POSIX); err == nil {
+				trees = append(trees, tree)
+			}
+		}
+	}
+
+	matches := func(s string) bool {
+		if err := checkLength(t, utf8.RuneCountInString(s), s); err != nil {
+			return false
+		}
+		for _, p := range patterns {
+			if ok, _ := p.Matches(s); !ok {
+				return false
+			}
+		}
+		for _, re := range res {
+			if !re.MatchString(s) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, tree := range trees {
+		tree = tree.Simplify()
+		for n := 0; n <= maxExampleRepeat; n++ {
+			var b strings.Builder
+			writeExample(&b, tree, n)
+			if s := b.String(); matches(s) {
+				return s
+			}
+		}
+	}
+
+	s := "example"
+	n := exampleLength(t, len(s))
+	if n < len(s) {
+		return s[:n]
+	}
+	return s + strings.Repeat("x", n-len(s))
+}
+
+// writeExample writes a string matched by re to b, repeating each repeated
+// part of re n times, or as close to n times as re allows.
+func writeExample(b *strings.Builder, re *syntax.Regexp, n int) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(classExample(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune('a')
+	case syntax.OpCapture:
+		writeExample(b, re.Sub[0], n)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeExample(b, sub, n)
+		}
+	case syntax.OpAlternate:
+		writeExample(b, re.Sub[0], n)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, -1
+		switch re.Op {
+		case syntax.OpPlus:
+			min = 1
+		case syntax.OpQuest:
+			max = 1
+		case syntax.OpRepeat:
+			min, max = re.Min, re.Max
+		}
+		count := n
+		if count < min {
+			count = min
+		}
+		if max >= 0 && count > max {
+			count = max
+		}
+		for i := 0; i < count; i++ {
+			writeExample(b, re.Sub[0], n)
+		}
+	}
+}
+
+// classExample returns a rune in the character class whose ranges are
+// ranges, preferring a lower case letter, then a digit and then the first
+// printable rune.
+func classExample(ranges []rune) rune {
+	in := func(r rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, r := range []rune{'a', '0'} {
+		if in(r) {
+			return r
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i+1] > ' ' {
+			if ranges[i] > ' ' {
+				return ranges[i]
+			}
+			return ' ' + 1
+		}
+	}
+	return 'a'
+}
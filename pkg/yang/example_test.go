@@ -0,0 +1,114 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExampleValue(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module ex {
+			prefix "e";
+			namespace "urn:e";
+
+			identity base;
+			identity zulu { base base; }
+			identity alpha { base base; }
+
+			typedef ipv4-address {
+				type string {
+					pattern
+						'(([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])\.){3}'
+					+	'([0-9]|[1-9][0-9]|1[0-9][0-9]|2[0-4][0-9]|25[0-5])'
+					+	'(%[\p{N}\p{L}]+)?';
+				}
+			}
+
+			container c {
+				leaf i8 { type int8; }
+				leaf u8 { type uint8; }
+				leaf pct { type uint8 { range "10..20 | 50..60"; } }
+				leaf neg { type int32 { range "-7..-2"; } }
+				leaf i64 { type int64; }
+				leaf u64 { type uint64 { range "1..max"; } }
+				leaf dec { type decimal64 { fraction-digits 2; range "1.5..2.75"; } }
+				leaf s { type string; }
+				leaf short { type string { length "1..3"; } }
+				leaf long { type string { length "10..20"; } }
+				leaf ip { type ipv4-address; }
+				leaf name { type string { pattern "[A-Z][a-z]+-[0-9]{2}"; length "6..8"; } }
+				leaf multi { type string { pattern "[a-z]+"; pattern "[a-z]" { modifier invert-match; } } }
+				leaf bin { type binary { length "2"; } }
+				leaf b { type boolean; }
+				leaf flag { type empty; }
+				leaf color { type enumeration { enum red { value 3; } enum blue { value 1; } } }
+				leaf opts { type bits { bit b { position 2; } bit a { position 5; } } }
+				leaf id { type identityref { base base; } }
+				leaf u { type union { type leafref { path "../s"; } type int16 { range "100..200"; } } }
+				leaf ref { type leafref { path "../s"; } }
+			}
+		}`, "ex.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	c := ToEntry(ms.Modules["ex"]).Dir["c"]
+
+	want := map[string]interface{}{
+		"i8":    json.Number("0"),
+		"u8":    json.Number("127"),
+		"pct":   json.Number("15"),
+		"neg":   json.Number("-4"),
+		"i64":   "0",
+		"u64":   "9223372036854775808",
+		"dec":   "2.12",
+		"s":     "example",
+		"short": "exa",
+		"long":  "examplexxx",
+		"ip":    "0.0.0.0",
+		"name":  "Aaa-00",
+		"multi": "aa",
+		"bin":   "eHg=",
+		"b":     true,
+		"flag":  []interface{}{nil},
+		"color": "blue",
+		"opts":  "b",
+		"id":    "ex:alpha",
+		"u":     json.Number("150"),
+		"ref":   nil,
+	}
+	got := map[string]interface{}{}
+	data := map[string]interface{}{}
+	for name, e := range c.Dir {
+		v := e.Type.ExampleValue()
+		got[name] = v
+		if v != nil {
+			data[name] = v
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ExampleValue (-want, +got):\n%s", diff)
+	}
+
+	if errs := c.Validate(data); len(errs) != 0 {
+		t.Errorf("Validate of the examples: %v", errs)
+	}
+}
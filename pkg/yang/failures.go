@@ -0,0 +1,165 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the tracking of the errors found by Process in each
+// module, which allows Process to continue with the modules that are not
+// affected by the errors in others when ParseOptions.ContinueOnError is set.
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ModuleErrors returns the errors found by the last call to Process, keyed
+// by the name of the module they were found in.  Errors found in a
+// submodule are keyed by the name of the module it belongs to.  A module
+// that was not processed because it imports a module with errors has an
+// error saying so.  Modules without errors are not included.
+func (ms *Modules) ModuleErrors() map[string][]error {
+	em := map[string][]error{}
+	for m, errs := range ms.moduleErrs {
+		em[m.Name] = errorSort(errs)
+	}
+	return em
+}
+
+// owner returns the module that m, a module or submodule, belongs to, or m if
+// it is a submodule whose module has not been read.
+func owner(m *Module) *Module {
+	if om := module(m); om != nil {
+		return om
+	}
+	return m
+}
+
+// noteErrors records errs as found in the module that n belongs to, which
+// is then considered to have failed.
+func (ms *Modules) noteErrors(n Node, errs ...error) {
+	if len(errs) == 0 {
+		return
+	}
+	m := owner(RootNode(n))
+	ms.failed[m] = true
+	ms.moduleErrs[m] = append(ms.moduleErrs[m], errs...)
+}
+
+// hasFailed reports whether errors were found in the module that m, a module
+// or submodule, belongs to, or in one of the modules it depends on.
+func (ms *Modules) hasFailed(m *Module) bool {
+	return ms.failed[owner(m)]
+}
+
+// processable returns the modules and submodules of mods that have not
+// failed.
+func (ms *Modules) processable(mods []*Module) []*Module {
+	var ok []*Module
+	for _, m := range mods {
+		if !ms.hasFailed(m) {
+			ok = append(ok, m)
+		}
+	}
+	return ok
+}
+
+// propagateFailures marks each module that imports a failed module, directly
+// or through one of its submodules, as failed, returning an error for each.
+func (ms *Modules) propagateFailures() []error {
+	var errs []error
+	for changed := true; changed; {
+		changed = false
+		for _, m := range append(sortedModules(ms.Modules), sortedModules(ms.SubModules)...) {
+			if ms.hasFailed(m) {
+				continue
+			}
+			for _, i := range m.Import {
+				// The import may not have been resolved if
+				// processing the includes and imports of m
+				// stopped at an error in another module.
+				im := i.Module
+				if im == nil {
+					im = ms.FindModule(i)
+				}
+				if im != nil && ms.hasFailed(im) {
+					err := fmt.Errorf("%s: %s not processed: imported module %s has errors", Source(i), owner(m).Name, i.Name)
+					ms.noteErrors(m, err)
+					errs = append(errs, err)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// instanceErrors collects the errors found by a check of the Entry trees of
+// a module.  The Entries instantiated from a statement in a grouping that is
+// used in several places share the statement's Node, and so typically have
+// the same error.  Such an error is reported once rather than for each
+// instance, for the instance whose error sorts first, noting how many other
+// instances have it.
+type instanceErrors struct {
+	index map[instanceKey]int // index in msgs and count of an error
+	msgs  []string
+	count []int // number of other instances with msgs[i]
+}
+
+// An instanceKey identifies the errors of the same kind, as given by their
+// format, found at the instances of the statement n.  The id distinguishes
+// the errors of the same kind that a single instance can have, such as those
+// of the different identifiers of a unique statement.
+type instanceKey struct {
+	n      Node
+	id     string
+	format string
+}
+
+// add adds the error found at an instance of n, formatted from format and
+// args, unless the same kind of error with the same id has already been found
+// at another instance of n, which is then noted.
+func (ie *instanceErrors) add(n Node, id, format string, args ...interface{}) {
+	k := instanceKey{n, id, format}
+	msg := fmt.Sprintf(format, args...)
+	if i, ok := ie.index[k]; ok {
+		ie.count[i]++
+		if msg < ie.msgs[i] {
+			ie.msgs[i] = msg
+		}
+		return
+	}
+	if ie.index == nil {
+		ie.index = map[instanceKey]int{}
+	}
+	ie.index[k] = len(ie.msgs)
+	ie.msgs = append(ie.msgs, msg)
+	ie.count = append(ie.count, 0)
+}
+
+// errors returns the errors added to ie.
+func (ie *instanceErrors) errors() []error {
+	var errs []error
+	for i, msg := range ie.msgs {
+		switch n := ie.count[i]; n {
+		case 0:
+		case 1:
+			msg += " (and 1 other use)"
+		default:
+			msg += fmt.Sprintf(" (and %d other uses)", n)
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return errs
+}
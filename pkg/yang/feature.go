@@ -0,0 +1,310 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the parsing and evaluation of the if-feature
+// expressions defined in RFC7950 section 7.20.2.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A FeatureOp is the operation performed by a FeatureExpr.
+type FeatureOp int
+
+// Enumeration of the operations of a FeatureExpr.
+const (
+	FeatureName = FeatureOp(iota) // true if the feature Name is enabled
+	FeatureNot                    // the negation of Args[0]
+	FeatureAnd                    // true if all of Args are true
+	FeatureOr                     // true if any of Args is true
+)
+
+var featureOpToName = map[FeatureOp]string{
+	FeatureName: "name",
+	FeatureNot:  "not",
+	FeatureAnd:  "and",
+	FeatureOr:   "or",
+}
+
+func (op FeatureOp) String() string {
+	if s := featureOpToName[op]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("unknown-feature-op-%d", op)
+}
+
+// A FeatureExpr is a parsed if-feature expression, such as
+// "foo and (bar or not baz)".
+type FeatureExpr struct {
+	Op   FeatureOp
+	Name string         // the feature, possibly prefixed, if Op is FeatureName
+	Args []*FeatureExpr // the operands of FeatureNot, FeatureAnd and FeatureOr
+}
+
+// ParseFeatureExpr parses the if-feature expression s.  Following RFC7950,
+// "not" binds more tightly than "and", which binds more tightly than "or".
+func ParseFeatureExpr(s string) (*FeatureExpr, error) {
+	p := &featureParser{tokens: featureTokens(s)}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty if-feature expression")
+	}
+	x, err := p.expr()
+	if err != nil {
+		return nil, fmt.Errorf("if-feature %q: %v", s, err)
+	}
+	if t := p.peek(); t != "" {
+		return nil, fmt.Errorf("if-feature %q: unexpected %q", s, t)
+	}
+	return x, nil
+}
+
+// Eval returns the value of expr when the features in enabled that are true
+// are enabled.  A feature is looked up in enabled by the name it has in the
+// expression and, if that is prefixed and not in enabled, by its name without
+// the prefix.  Features not found in enabled are disabled.  A nil expression
+// is true.
+func (expr *FeatureExpr) Eval(enabled map[string]bool) bool {
+	if expr == nil {
+		return true
+	}
+	switch expr.Op {
+	case FeatureName:
+		if v, ok := enabled[expr.Name]; ok {
+			return v
+		}
+		_, name := getPrefix(expr.Name)
+		return enabled[name]
+	case FeatureNot:
+		return !expr.Args[0].Eval(enabled)
+	case FeatureAnd:
+		for _, a := range expr.Args {
+			if !a.Eval(enabled) {
+				return false
+			}
+		}
+		return true
+	case FeatureOr:
+		for _, a := range expr.Args {
+			if a.Eval(enabled) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// String returns expr as an if-feature expression, adding only the
+// parentheses required to preserve its meaning.
+func (expr *FeatureExpr) String() string {
+	if expr == nil {
+		return ""
+	}
+	switch expr.Op {
+	case FeatureName:
+		return expr.Name
+	case FeatureNot:
+		return "not " + expr.Args[0].operand(FeatureNot)
+	case FeatureAnd, FeatureOr:
+		parts := make([]string, len(expr.Args))
+		for i, a := range expr.Args {
+			parts[i] = a.operand(expr.Op)
+		}
+		return strings.Join(parts, " "+expr.Op.String()+" ")
+	}
+	return expr.Op.String()
+}
+
+// operand returns expr as the operand of op, parenthesized if it binds less
+// tightly than op.
+func (expr *FeatureExpr) operand(op FeatureOp) string {
+	s := expr.String()
+	switch {
+	case expr.Op == FeatureName, expr.Op == FeatureNot, expr.Op == op:
+	case op == FeatureOr:
+	default:
+		s = "(" + s + ")"
+	}
+	return s
+}
+
+// featureTokens splits s into the parentheses and words of an if-feature
+// expression.
+func featureTokens(s string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range s {
+		switch r {
+		case '(', ')', ' ', '\t', '\n', '\r':
+			if start >= 0 {
+				tokens = append(tokens, s[start:i])
+				start = -1
+			}
+			if r == '(' || r == ')' {
+				tokens = append(tokens, string(r))
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+// A featureParser is a recursive descent parser of the if-feature-expr
+// grammar in RFC7950 section 14.
+type featureParser struct {
+	tokens []string
+}
+
+// peek returns the next token, or "" if there are none left.
+func (p *featureParser) peek() string {
+	if len(p.tokens) == 0 {
+		return ""
+	}
+	return p.tokens[0]
+}
+
+// next returns and consumes the next token.
+func (p *featureParser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.tokens = p.tokens[1:]
+	}
+	return t
+}
+
+// expr parses an if-feature-expr, a list of terms separated by "or".
+func (p *featureParser) expr() (*FeatureExpr, error) {
+	return p.list(FeatureOr, p.term)
+}
+
+// term parses an if-feature-term, a list of factors separated by "and".
+func (p *featureParser) term() (*FeatureExpr, error) {
+	return p.list(FeatureAnd, p.factor)
+}
+
+// list parses one or more operands, read by parse, separated by the keyword
+// of op.  A single operand is returned as is.
+func (p *featureParser) list(op FeatureOp, parse func() (*FeatureExpr, error)) (*FeatureExpr, error) {
+	x, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	args := []*FeatureExpr{x}
+	for p.peek() == op.String() {
+		p.next()
+		x, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, x)
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &FeatureExpr{Op: op, Args: args}, nil
+}
+
+// factor parses an if-feature-factor: a negated factor, a parenthesized
+// expression or a feature name.
+func (p *featureParser) factor() (*FeatureExpr, error) {
+	switch t := p.next(); t {
+	case "":
+		return nil, errors.New("unexpected end of expression")
+	case "not":
+		x, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return &FeatureExpr{Op: FeatureNot, Args: []*FeatureExpr{x}}, nil
+	case "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t != ")" {
+			return nil, errors.New(`missing ")"`)
+		}
+		return x, nil
+	case ")", "and", "or":
+		return nil, fmt.Errorf("unexpected %q", t)
+	default:
+		return &FeatureExpr{Op: FeatureName, Name: t}, nil
+	}
+}
+
+// IfFeatureExpr returns the conjunction of the if-feature statements that
+// apply to e, including those of any uses or augment that e was instantiated
+// by, or nil if there are none.
+func (e *Entry) IfFeatureExpr() (*FeatureExpr, error) {
+	values := append([]interface{}{}, e.Extra["if-feature"]...)
+	parent := e.Parent
+	if a := e.augmentedBy; a != nil {
+		values = append(values, a.Extra["if-feature"]...)
+		parent = a
+	}
+	if parent != nil && parent.Node != nil && e.Node != nil {
+		uses, _ := usesPath(parent.Node, e.Node)
+		for _, u := range uses {
+			for _, v := range u.IfFeature {
+				values = append(values, v)
+			}
+		}
+	}
+	return ifFeatureExpr(values)
+}
+
+// IfFeatureExpr returns the conjunction of the if-feature statements of the
+// feature s, i.e., the features that s depends upon, or nil if there are
+// none.
+func (s *Feature) IfFeatureExpr() (*FeatureExpr, error) {
+	var values []interface{}
+	for _, v := range s.IfFeature {
+		values = append(values, v)
+	}
+	return ifFeatureExpr(values)
+}
+
+// ifFeatureExpr parses the if-feature *Values in values and returns their
+// conjunction.
+func ifFeatureExpr(values []interface{}) (*FeatureExpr, error) {
+	var args []*FeatureExpr
+	for _, i := range values {
+		v, ok := i.(*Value)
+		if !ok || v == nil {
+			continue
+		}
+		x, err := ParseFeatureExpr(v.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", Source(v), err)
+		}
+		args = append(args, x)
+	}
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		return args[0], nil
+	}
+	return &FeatureExpr{Op: FeatureAnd, Args: args}, nil
+}
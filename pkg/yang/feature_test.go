@@ -0,0 +1,233 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestParseFeatureExpr(t *testing.T) {
+	tests := []struct {
+		desc             string
+		in               string
+		wantString       string
+		wantErrSubstring string
+		// enabled maps sets of enabled features to the expected value.
+		enabled map[string]bool
+		want    bool
+	}{{
+		desc:       "single feature",
+		in:         "foo",
+		wantString: "foo",
+		enabled:    map[string]bool{"foo": true},
+		want:       true,
+	}, {
+		desc:       "prefixed feature matches unprefixed name",
+		in:         "p:foo",
+		wantString: "p:foo",
+		enabled:    map[string]bool{"foo": true},
+		want:       true,
+	}, {
+		desc:       "disabled feature",
+		in:         "foo",
+		wantString: "foo",
+		enabled:    map[string]bool{"bar": true},
+	}, {
+		desc:       "and",
+		in:         "foo and bar",
+		wantString: "foo and bar",
+		enabled:    map[string]bool{"foo": true},
+	}, {
+		desc:       "or",
+		in:         "foo or bar",
+		wantString: "foo or bar",
+		enabled:    map[string]bool{"bar": true},
+		want:       true,
+	}, {
+		desc:       "not",
+		in:         "not foo",
+		wantString: "not foo",
+		want:       true,
+	}, {
+		desc:       "and binds tighter than or",
+		in:         "a or b and c",
+		wantString: "a or b and c",
+		enabled:    map[string]bool{"b": true},
+	}, {
+		desc:       "parentheses",
+		in:         "foo and (bar or baz)",
+		wantString: "foo and (bar or baz)",
+		enabled:    map[string]bool{"foo": true, "baz": true},
+		want:       true,
+	}, {
+		desc:       "not of a parenthesized expression",
+		in:         "not(foo or bar) and baz",
+		wantString: "not (foo or bar) and baz",
+		enabled:    map[string]bool{"baz": true},
+		want:       true,
+	}, {
+		desc:       "redundant parentheses",
+		in:         "((foo)) or (bar and baz)",
+		wantString: "foo or bar and baz",
+		enabled:    map[string]bool{"foo": true},
+		want:       true,
+	}, {
+		desc:       "not not",
+		in:         "not not foo",
+		wantString: "not not foo",
+		enabled:    map[string]bool{"foo": true},
+		want:       true,
+	}, {
+		desc:             "empty expression",
+		in:               " ",
+		wantErrSubstring: "empty if-feature expression",
+	}, {
+		desc:             "missing operand",
+		in:               "foo and",
+		wantErrSubstring: "unexpected end of expression",
+	}, {
+		desc:             "unbalanced parentheses",
+		in:               "(foo or bar",
+		wantErrSubstring: `missing ")"`,
+	}, {
+		desc:             "extra close parenthesis",
+		in:               "foo)",
+		wantErrSubstring: `unexpected ")"`,
+	}, {
+		desc:             "missing operator",
+		in:               "foo bar",
+		wantErrSubstring: `unexpected "bar"`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			expr, err := ParseFeatureExpr(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("ParseFeatureExpr(%q): %s", tt.in, diff)
+			}
+			if err != nil {
+				return
+			}
+			if got := expr.String(); got != tt.wantString {
+				t.Errorf("ParseFeatureExpr(%q).String(): got %q, want %q", tt.in, got, tt.wantString)
+			}
+			if got := expr.Eval(tt.enabled); got != tt.want {
+				t.Errorf("ParseFeatureExpr(%q).Eval(%v): got %v, want %v", tt.in, tt.enabled, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryIfFeatureExpr(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			feature foo;
+			feature bar;
+			feature baz {
+				if-feature "foo or bar";
+			}
+
+			leaf plain { type string; }
+			leaf single {
+				if-feature foo;
+				type string;
+			}
+			leaf-list multiple {
+				if-feature "foo and (bar or baz)";
+				if-feature "not d:baz";
+				type string;
+			}
+
+			grouping g {
+				leaf grouped {
+					if-feature bar;
+					type string;
+				}
+			}
+			grouping outer {
+				uses g {
+					if-feature baz;
+				}
+			}
+			uses outer {
+				if-feature foo;
+			}
+			container target;
+			augment "/target" {
+				if-feature baz;
+				leaf augmented { type string; }
+				uses g;
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	dev := ToEntry(ms.Modules["dev"])
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"plain", ""},
+		{"single", "foo"},
+		{"multiple", "foo and (bar or baz) and not d:baz"},
+		{"grouped", "bar and foo and baz"},
+		{"target/augmented", "baz"},
+		{"target/grouped", "bar and baz"},
+	}
+	for _, tt := range tests {
+		expr, err := dev.Find(tt.name).IfFeatureExpr()
+		if err != nil {
+			t.Errorf("%s: IfFeatureExpr: %v", tt.name, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("%s: IfFeatureExpr: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	expr, err := dev.Dir["multiple"].IfFeatureExpr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !expr.Eval(map[string]bool{"foo": true, "bar": true}) {
+		t.Errorf("multiple is disabled with foo and bar enabled")
+	}
+	if expr.Eval(map[string]bool{"foo": true, "baz": true}) {
+		t.Errorf("multiple is enabled with baz enabled")
+	}
+
+	var baz *Feature
+	for _, f := range ms.Modules["dev"].Feature {
+		if f.Name == "baz" {
+			baz = f
+		}
+	}
+	fexpr, err := baz.IfFeatureExpr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fexpr.String(), "foo or bar"; got != want {
+		t.Errorf("feature baz: IfFeatureExpr: got %q, want %q", got, want)
+	}
+}
@@ -57,10 +57,11 @@ func PathsWithModules(root string) (paths []string, err error) {
 
 // AddPath adds the directories specified in p, a colon separated list
 // of directory names, to Path, if they are not already in Path. Using
-// multiple arguments is also supported.
+// multiple arguments is also supported.  An entry may also be an http:// or
+// https:// URL, in which case files are fetched from below it.
 func (ms *Modules) AddPath(paths ...string) {
 	for _, path := range paths {
-		for _, p := range strings.Split(path, ":") {
+		for _, p := range splitPath(path) {
 			if !ms.pathMap[p] {
 				ms.pathMap[p] = true
 				ms.Path = append(ms.Path, p)
@@ -69,6 +70,23 @@ func (ms *Modules) AddPath(paths ...string) {
 	}
 }
 
+// SourceFiles returns a map of the names of the modules and submodules in ms,
+// including the revision qualified names they are also stored under, to the
+// files they were read from.  The file of a module found by Read, or by
+// Process when resolving an import or include, is the path of the file found
+// in the current directory or on Path, or the URL it was fetched from.  The
+// file of a module added by Parse or read by Reader is "".  Modules imported
+// by ImportParsed have the files they had when exported.
+func (ms *Modules) SourceFiles() map[string]string {
+	files := map[string]string{}
+	for _, mods := range []map[string]*Module{ms.Modules, ms.SubModules} {
+		for name, m := range mods {
+			files[name] = ms.files[m]
+		}
+	}
+	return files
+}
+
 // readFile makes testing of findFile easier.
 var readFile = ioutil.ReadFile
 
@@ -84,7 +102,8 @@ var scanDir = findInDir
 // YYYY-MM-DD revision-date) of these will be selected.
 //
 // If a path has the form dir/... then dir and all direct or indirect
-// subdirectories of dir are searched.
+// subdirectories of dir are searched.  If a path is an http:// or https://
+// URL then the file is fetched from it, see fetchURL.
 //
 // The current directory (.) is always checked first, no matter the value of
 // Path.
@@ -107,7 +126,18 @@ func (ms *Modules) findFile(name string) (string, string, error) {
 		return "", "", fmt.Errorf("no such file: %s", name)
 	}
 
+	var urlErr error
 	for _, dir := range ms.Path {
+		if isURL(dir) {
+			n, data, err := ms.fetchURL(dir, name)
+			if err == nil {
+				return n, data, nil
+			}
+			if err != errURLNotFound && urlErr == nil {
+				urlErr = err
+			}
+			continue
+		}
 		var n string
 		if filepath.Base(dir) == "..." {
 			n = scanDir(filepath.Dir(dir), name, true)
@@ -121,6 +151,9 @@ func (ms *Modules) findFile(name string) (string, string, error) {
 			return n, string(data), nil
 		}
 	}
+	if urlErr != nil {
+		return "", "", fmt.Errorf("no such file: %s: %v", name, urlErr)
+	}
 	return "", "", fmt.Errorf("no such file: %s", name)
 }
 
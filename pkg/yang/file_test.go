@@ -21,10 +21,15 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestFindFile(t *testing.T) {
 	sep := string(os.PathSeparator)
+	defer func(rf func(string) ([]byte, error), sd func(string, string, bool) string) {
+		readFile, scanDir = rf, sd
+	}(readFile, scanDir)
 
 	for _, tt := range []struct {
 		name  string
@@ -164,3 +169,41 @@ func TestFindInDir(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goyang-source-files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, text := range map[string]string{
+		"a.yang":            "module a { prefix a; namespace urn:a; import b { prefix b; } include a-sub; }",
+		"a-sub.yang":        "submodule a-sub { belongs-to a { prefix a; } }",
+		"b@2021-01-01.yang": "module b { prefix b; namespace urn:b; revision 2021-01-01; }",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ms := NewModules()
+	ms.AddPath(dir)
+	if err := ms.Read("a"); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if err := ms.Parse("module c { prefix c; namespace urn:c; }", "c.yang"); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatalf("Process: %v", errs)
+	}
+	want := map[string]string{
+		"a":            filepath.Join(dir, "a.yang"),
+		"a-sub":        filepath.Join(dir, "a-sub.yang"),
+		"b":            filepath.Join(dir, "b@2021-01-01.yang"),
+		"b@2021-01-01": filepath.Join(dir, "b@2021-01-01.yang"),
+		"c":            "",
+	}
+	if diff := cmp.Diff(want, ms.SourceFiles()); diff != "" {
+		t.Errorf("SourceFiles (-want, +got):\n%s", diff)
+	}
+}
@@ -45,10 +45,13 @@ func trimLocalPrefix(n Node, name string) string {
 //
 // FindGrouping works by recursively looking through the context node's parent
 // nodes for grouping fields, or in included or imported submodules/modules for
-// externally-defined groupings. Note that any prefix in the name must match
+// externally-defined groupings. Within a submodule, the groupings of the
+// module it belongs to and of that module's other submodules are searched
+// last. Note that any prefix in the name must match
 // the module prefix of its import statement in the context node's module.
 func FindGrouping(n Node, name string, seen map[string]bool) *Grouping {
 	name = trimLocalPrefix(n, name)
+	start := n
 	for n != nil {
 		// Grab the Grouping field of the underlying structure.  n is
 		// always a pointer to a structure,
@@ -92,5 +95,17 @@ func FindGrouping(n Node, name string, seen map[string]bool) *Grouping {
 		}
 		n = n.ParentNode()
 	}
+	// The groupings of the module a submodule belongs to, and of that
+	// module's other submodules, are also visible within the submodule.
+	if start == nil {
+		return nil
+	}
+	if root := RootNode(start); root.Kind() == "submodule" && root.BelongsTo != nil && root.Modules != nil {
+		seen[root.Name] = true
+		if m := root.Modules.Modules[root.BelongsTo.Name]; m != nil && !seen[m.Name] {
+			seen[m.Name] = true
+			return FindGrouping(m, name, seen)
+		}
+	}
 	return nil
 }
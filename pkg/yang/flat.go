@@ -0,0 +1,79 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the flattening of a schema tree into a list of its
+// configuration leaves, as used to generate key=value configuration
+// templates.
+
+import "strings"
+
+// A FlatLeaf is a configuration leaf or leaf-list of a schema tree, as
+// returned by FlatLeaves.
+type FlatLeaf struct {
+	Node *Entry // the leaf or leaf-list
+	// Path is the path of Node relative to the entry FlatLeaves was
+	// called on, its data nodes separated by dots.  Each list is followed
+	// by a placeholder for its keys, e.g., server[<name>].port, or
+	// server[<host>,<port>].weight for a list with two keys.
+	Path        string
+	Type        *YangType // the type of Node
+	Default     []string  // the default values of Node, as returned by DefaultValues
+	Description string    // the description of Node, if any
+}
+
+// FlatLeaves returns the leaves and leaf-lists beneath e that are
+// configuration, in the order returned by LeafPaths.  Choice and case nodes
+// do not appear in the paths, and state data, operations and notifications
+// are omitted.  Unlike LeafPaths, the paths are relative to e and locate the
+// leaves in instance data, with a placeholder for the keys of each list.
+func (e *Entry) FlatLeaves() []FlatLeaf {
+	var leaves []FlatLeaf
+	var walk func(*Entry, string)
+	walk = func(e *Entry, prefix string) {
+		for _, c := range declaredChildren(e) {
+			if c.ReadOnly() || c.RPC != nil || c.Kind == NotificationEntry {
+				continue
+			}
+			path := prefix
+			if !c.IsChoice() && !c.IsCase() {
+				path += c.Name
+			}
+			switch {
+			case c.IsLeaf() || c.IsLeafList():
+				leaves = append(leaves, FlatLeaf{
+					Node:        c,
+					Path:        path,
+					Type:        c.Type,
+					Default:     c.DefaultValues(),
+					Description: c.Description,
+				})
+				continue
+			case c.IsList():
+				var keys []string
+				for _, k := range strings.Fields(c.Key) {
+					keys = append(keys, "<"+k+">")
+				}
+				path += "[" + strings.Join(keys, ",") + "]"
+			}
+			if path != prefix {
+				path += "."
+			}
+			walk(c, path)
+		}
+	}
+	walk(e, "")
+	return leaves
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFlatLeaves(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			grouping timers {
+				leaf interval { type uint32; }
+				leaf timeout { type uint32; }
+			}
+
+			container system {
+				leaf hostname {
+					type string;
+					default "router";
+					description "The name of the device.";
+				}
+				choice transport {
+					case tcp {
+						leaf port { type uint16; }
+					}
+					leaf-list udp-ports { type uint16; }
+				}
+				list server {
+					key "name";
+					leaf name { type string; }
+					uses timers;
+					list peer {
+						key "host port";
+						leaf host { type string; }
+						leaf port { type uint16; }
+						leaf weight { type uint8; default 1; }
+					}
+				}
+				container state {
+					config false;
+					leaf uptime { type uint64; }
+				}
+				action restart {
+					input { leaf delay { type uint32; } }
+				}
+			}
+			rpc reboot {
+				input { leaf delay { type uint32; } }
+			}
+			notification alarm {
+				leaf severity { type string; }
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	type flatLeaf struct {
+		Path        string
+		Type        string
+		Default     []string
+		Description string
+	}
+	flatten := func(leaves []FlatLeaf) []flatLeaf {
+		var fls []flatLeaf
+		for _, l := range leaves {
+			if l.Node.Type != l.Type {
+				t.Errorf("%s: Type is not the type of Node", l.Path)
+			}
+			fls = append(fls, flatLeaf{l.Path, l.Type.Name, l.Default, l.Description})
+		}
+		return fls
+	}
+
+	want := []flatLeaf{
+		{"system.hostname", "string", []string{"router"}, "The name of the device."},
+		{"system.port", "uint16", nil, ""},
+		{"system.udp-ports", "uint16", nil, ""},
+		{"system.server[<name>].name", "string", nil, ""},
+		{"system.server[<name>].interval", "uint32", nil, ""},
+		{"system.server[<name>].timeout", "uint32", nil, ""},
+		{"system.server[<name>].peer[<host>,<port>].host", "string", nil, ""},
+		{"system.server[<name>].peer[<host>,<port>].port", "uint16", nil, ""},
+		{"system.server[<name>].peer[<host>,<port>].weight", "uint8", []string{"1"}, ""},
+	}
+	if diff := cmp.Diff(want, flatten(mod.FlatLeaves())); diff != "" {
+		t.Errorf("FlatLeaves (-want, +got):\n%s", diff)
+	}
+
+	want = []flatLeaf{
+		{"host", "string", nil, ""},
+		{"port", "uint16", nil, ""},
+		{"weight", "uint8", []string{"1"}, ""},
+	}
+	if diff := cmp.Diff(want, flatten(mod.Find("system/server/peer").FlatLeaves())); diff != "" {
+		t.Errorf("FlatLeaves of peer (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements a ModuleReader of the YANG files shipped within Go
+// modules.
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// NewGoModuleReader returns a ModuleReader that reads modules and submodules
+// from the YANG files found in the directories of the Go modules or packages
+// named by importPaths, e.g., github.com/openconfig/public, and their
+// subdirectories.  Files are named as described for NewPathReader, and the
+// directories are searched in the order given.
+//
+// The directories are located by the go command, run in the current
+// directory, so a module is found at the version required by the current
+// module, normally in the module cache.  Nothing is downloaded: an error is
+// returned if the directory of an import path cannot be located, e.g.,
+// because it is not required by the current module or is not in the module
+// cache.
+func NewGoModuleReader(importPaths ...string) (ModuleReader, error) {
+	r := &goModuleReader{}
+	for _, p := range importPaths {
+		dir, err := goListDir(p)
+		if err != nil {
+			return nil, err
+		}
+		r.dirs = append(r.dirs, dir)
+	}
+	return r, nil
+}
+
+// A goModuleReader is the ModuleReader returned by NewGoModuleReader.
+type goModuleReader struct {
+	dirs []string
+}
+
+// ReadModule implements ModuleReader.
+func (r *goModuleReader) ReadModule(name, revision string) ([]byte, error) {
+	var names []string
+	if revision != "" {
+		names = append(names, name+"@"+revision+".yang")
+	}
+	names = append(names, name+".yang")
+	for _, n := range names {
+		for _, dir := range r.dirs {
+			if path := scanDir(dir, n, true); path != "" {
+				return readFile(path)
+			}
+		}
+	}
+	return nil, fmt.Errorf("no such file: %s.yang in %s", name, strings.Join(r.dirs, ", "))
+}
+
+// goListDir returns the directory of the Go module, or failing that the Go
+// package, named by importPath.  It is a variable to make testing easier.
+var goListDir = func(importPath string) (string, error) {
+	dir, err := goList("-m", "-f", "{{.Dir}}", importPath)
+	if err == nil && dir != "" {
+		return dir, nil
+	}
+	if pdir, perr := goList("-f", "{{.Dir}}", importPath); perr == nil && pdir != "" {
+		return pdir, nil
+	}
+	if err == nil {
+		// The module is known but not downloaded.
+		err = fmt.Errorf("module %s is not in the module cache", importPath)
+	}
+	return "", fmt.Errorf("cannot locate Go module %s: %v", importPath, err)
+}
+
+// goList returns the output of go list run with args, without the trailing
+// newline.  The error returned includes what the go command reported.
+func goList(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list"}, args...)...)
+	// Only locate what is already present rather than download it.
+	cmd.Env = append(os.Environ(), "GOPROXY=off")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestGoModuleReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goyang-gomod-reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, text := range map[string]string{
+		"models/yang/top.yang": `
+			module top {
+				prefix "t";
+				namespace "urn:t";
+				import dep { prefix d; revision-date 2021-02-01; }
+				include top-sub;
+				leaf a { type d:name; }
+			}`,
+		"models/yang/top-sub.yang": `
+			submodule top-sub {
+				belongs-to top { prefix t; }
+				leaf b { type string; }
+			}`,
+		"other/dep@2021-02-01.yang": `module dep { prefix "d"; namespace "urn:d"; revision 2021-02-01; typedef name { type string; } }`,
+		"other/dep@2020-01-01.yang": `module dep { prefix "d"; namespace "urn:d"; revision 2020-01-01; }`,
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer func(f func(string) (string, error)) { goListDir = f }(goListDir)
+	goListDir = func(importPath string) (string, error) {
+		switch importPath {
+		case "example.com/models":
+			return filepath.Join(dir, "models"), nil
+		case "example.com/other":
+			return filepath.Join(dir, "other"), nil
+		}
+		return "", fmt.Errorf("cannot locate Go module %s", importPath)
+	}
+
+	if _, err := NewGoModuleReader("example.com/models", "example.com/missing"); err == nil {
+		t.Errorf("NewGoModuleReader with a missing module: got no error")
+	}
+	r, err := NewGoModuleReader("example.com/models", "example.com/other")
+	if err != nil {
+		t.Fatalf("NewGoModuleReader: %v", err)
+	}
+	ms := NewModules()
+	ms.Reader = r
+	e, errs := ms.GetModule("top")
+	if len(errs) != 0 {
+		t.Fatalf("GetModule(top): %v", errs)
+	}
+	if e.Dir["a"] == nil || e.Dir["b"] == nil {
+		t.Errorf("top is missing leaves: got %v", e.Dir)
+	}
+
+	for _, tt := range []struct {
+		name, revision string
+		want           string
+		wantErr        string
+	}{
+		{name: "dep", want: "revision 2021-02-01"},
+		{name: "dep", revision: "2020-01-01", want: "revision 2020-01-01"},
+		{name: "dep", revision: "2019-01-01", want: "revision 2021-02-01"},
+		{name: "missing", wantErr: "no such file: missing.yang"},
+	} {
+		got, err := r.ReadModule(tt.name, tt.revision)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("ReadModule(%q, %q): %s", tt.name, tt.revision, diff)
+			continue
+		}
+		if !strings.Contains(string(got), tt.want) {
+			t.Errorf("ReadModule(%q, %q): got %q, want it to contain %q", tt.name, tt.revision, got, tt.want)
+		}
+	}
+}
+
+func TestGoListDir(t *testing.T) {
+	// The directory of the module this test is in is always known.
+	dir, err := goListDir("github.com/openconfig/goyang")
+	if err != nil {
+		t.Fatalf("goListDir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pkg", "yang", "gomod.go")); err != nil {
+		t.Errorf("goListDir returned %s, which is not the directory of this module: %v", dir, err)
+	}
+}
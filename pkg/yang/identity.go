@@ -16,6 +16,8 @@ package yang
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -163,6 +165,7 @@ func (ms *Modules) resolveIdentities() []error {
 				base, baseErr := root.findIdentityBase(b.asString())
 
 				if baseErr != nil {
+					ms.noteErrors(i.Identity, baseErr...)
 					errs = append(errs, baseErr...)
 					continue
 				}
@@ -184,3 +187,58 @@ func (ms *Modules) resolveIdentities() []error {
 
 	return errs
 }
+
+// ValidateIdentityref returns an error if t is not an identityref type or
+// value is not the name of an identity derived from the base of t.  value is
+// either the name of an identity or, as in RFC7951, the name qualified by the
+// name of the module defining it, e.g., "ietf-interfaces:ethernetCsmacd".  An
+// unqualified name must identify a single derived identity.  The base itself
+// is not a valid value.  If ms is not nil, the module named by a qualified
+// value must be one of ms.Modules.
+func (t *YangType) ValidateIdentityref(value string, ms *Modules) error {
+	_, err := t.findIdentity(value, ms)
+	return err
+}
+
+// findIdentity returns the identity derived from the base of the
+// identityref type t that value names, as described by ValidateIdentityref.
+func (t *YangType) findIdentity(value string, ms *Modules) (*Identity, error) {
+	if t.Kind != Yidentityref {
+		return nil, fmt.Errorf("type %s is not an identityref", t.Name)
+	}
+	if t.IdentityBase == nil {
+		return nil, fmt.Errorf("identityref type %s has no base", t.Name)
+	}
+	mod, name := getPrefix(value)
+	if mod != "" && ms != nil && ms.Modules[mod] == nil {
+		return nil, fmt.Errorf("%q is not a valid identity: no module %s", value, mod)
+	}
+	matches := func(id *Identity) bool {
+		if id.Name != name {
+			return false
+		}
+		m := module(id)
+		return mod == "" || (m != nil && m.Name == mod)
+	}
+
+	var found []*Identity
+	for _, id := range t.IdentityBase.Values {
+		if matches(id) {
+			found = append(found, id)
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1:
+		var names []string
+		for _, id := range found {
+			names = append(names, id.modulePrefixedName())
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%q is ambiguous, it may be any of %s", value, strings.Join(names, ", "))
+	case matches(t.IdentityBase):
+		return nil, fmt.Errorf("%q is not a valid identity: it is the base %s, not derived from it", value, t.IdentityBase.modulePrefixedName())
+	}
+	return nil, fmt.Errorf("%q is not a valid identity: it is not derived from %s", value, t.IdentityBase.modulePrefixedName())
+}
@@ -793,3 +793,96 @@ func TestIdentityTree(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateIdentityref(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"base.yang": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+				identity if-type;
+				identity ethernet { base if-type; }
+				identity gigabit { base ethernet; }
+				identity loopback { base if-type; }
+				identity other;
+				leaf t { type identityref { base if-type; } }
+				leaf s { type string; }
+			}`,
+		"ext.yang": `
+			module ext {
+				prefix "x";
+				namespace "urn:x";
+				import base { prefix b; }
+				identity loopback { base b:if-type; }
+			}`,
+	} {
+		if err := ms.Parse(text, name); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	e := ToEntry(ms.Modules["base"])
+	typ := e.Dir["t"].Type
+
+	for _, tt := range []struct {
+		desc    string
+		typ     *YangType
+		value   string
+		ms      *Modules
+		wantErr string
+	}{{
+		desc:  "derived identity",
+		value: "ethernet",
+	}, {
+		desc:  "qualified derived identity",
+		value: "base:ethernet",
+		ms:    ms,
+	}, {
+		desc:  "transitively derived identity",
+		value: "gigabit",
+	}, {
+		desc:  "qualified identity from another module",
+		value: "ext:loopback",
+		ms:    ms,
+	}, {
+		desc:    "ambiguous unqualified identity",
+		value:   "loopback",
+		wantErr: `"loopback" is ambiguous, it may be any of base:loopback, ext:loopback`,
+	}, {
+		desc:    "base identity",
+		value:   "if-type",
+		wantErr: `"if-type" is not a valid identity: it is the base base:if-type, not derived from it`,
+	}, {
+		desc:    "identity not derived from the base",
+		value:   "other",
+		wantErr: `"other" is not a valid identity: it is not derived from base:if-type`,
+	}, {
+		desc:    "unknown identity",
+		value:   "base:missing",
+		wantErr: `"base:missing" is not a valid identity: it is not derived from base:if-type`,
+	}, {
+		desc:    "unknown module",
+		value:   "missing:ethernet",
+		ms:      ms,
+		wantErr: `"missing:ethernet" is not a valid identity: no module missing`,
+	}, {
+		desc:    "not an identityref",
+		typ:     e.Dir["s"].Type,
+		value:   "ethernet",
+		wantErr: "type string is not an identityref",
+	}} {
+		t.Run(tt.desc, func(t *testing.T) {
+			ty := tt.typ
+			if ty == nil {
+				ty = typ
+			}
+			err := ty.ValidateIdentityref(tt.value, tt.ms)
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Errorf("ValidateIdentityref(%q): %s", tt.value, diff)
+			}
+		})
+	}
+}
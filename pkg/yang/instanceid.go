@@ -0,0 +1,233 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the parsing of instance-identifier values as defined
+// by RFC7950 section 9.13 and the instance-identifier rule of section 14.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// An InstanceIdentifierElem is one node of an instance-identifier path, such
+// as interface[name='eth0'] in /ex:interfaces/ex:interface[ex:name='eth0'].
+type InstanceIdentifierElem struct {
+	Prefix string // the prefix, or module name, of the node, "" if none
+	Name   string // the name of the node
+
+	// Keys maps the node identifier, as written, of each key-predicate to
+	// its value.  The value of a leaf-list-predicate is keyed by ".".
+	Keys map[string]string
+	// Position is the value of a position predicate, or 0 if there is
+	// none.
+	Position uint64
+}
+
+// ParseInstanceIdentifier parses path as an instance-identifier value and
+// returns its nodes.  An error is returned if path is not absolute or any of
+// its nodes or predicates are malformed.  The nodes are not resolved against
+// any schema.
+func ParseInstanceIdentifier(path string) ([]*InstanceIdentifierElem, error) {
+	p := &iidParser{s: path}
+	var elems []*InstanceIdentifierElem
+	if p.s == "" {
+		return nil, fmt.Errorf("instance-identifier %q: empty path", path)
+	}
+	for !p.done() {
+		if !p.consume('/') {
+			return nil, p.errorf("expected \"/\"")
+		}
+		elem := &InstanceIdentifierElem{}
+		var err error
+		if elem.Prefix, elem.Name, err = p.nodeIdentifier(); err != nil {
+			return nil, err
+		}
+		for p.peek() == '[' {
+			if err := p.predicate(elem); err != nil {
+				return nil, err
+			}
+		}
+		elems = append(elems, elem)
+	}
+	return elems, nil
+}
+
+// ValidateInstanceIdentifier returns an error if t is not the
+// instance-identifier type or path is not a well-formed value of it.  The
+// existence of the instance path refers to, which is required when
+// require-instance is true, is not checked as there is no data to check it
+// against.
+func (t *YangType) ValidateInstanceIdentifier(path string) error {
+	if t.Kind != YinstanceIdentifier {
+		return fmt.Errorf("type %s is not an instance-identifier", t.Name)
+	}
+	_, err := ParseInstanceIdentifier(path)
+	return err
+}
+
+// An iidParser parses an instance-identifier value.
+type iidParser struct {
+	s   string
+	pos int
+}
+
+// errorf returns an error describing a problem found at the current position.
+func (p *iidParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("instance-identifier %q: offset %d: %s", p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *iidParser) done() bool { return p.pos >= len(p.s) }
+
+// peek returns the next byte of the value, or 0 at the end.
+func (p *iidParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// consume skips the next byte if it is c and reports whether it did.
+func (p *iidParser) consume(c byte) bool {
+	if p.peek() != c {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// skipSpace skips any spaces and tabs.
+func (p *iidParser) skipSpace() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// identifier returns the YANG identifier at the current position.
+func (p *iidParser) identifier() (string, error) {
+	start := p.pos
+	for !p.done() {
+		c := p.s[p.pos]
+		isAlpha := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+		if p.pos == start && !isAlpha || !isAlpha && !(c >= '0' && c <= '9') && c != '-' && c != '.' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected an identifier")
+	}
+	return p.s[start:p.pos], nil
+}
+
+// nodeIdentifier returns the optional prefix and name of the node identifier
+// at the current position.
+func (p *iidParser) nodeIdentifier() (string, string, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return "", "", err
+	}
+	if !p.consume(':') {
+		return "", name, nil
+	}
+	prefix := name
+	if name, err = p.identifier(); err != nil {
+		return "", "", err
+	}
+	return prefix, name, nil
+}
+
+// predicate parses the predicate at the current position into elem.  Key
+// predicates may be repeated but may not be mixed with a leaf-list or
+// position predicate.
+func (p *iidParser) predicate(elem *InstanceIdentifierElem) error {
+	p.consume('[')
+	p.skipSpace()
+	if elem.Position != 0 {
+		return p.errorf("predicate following a position predicate")
+	}
+	if c := p.peek(); c >= '0' && c <= '9' {
+		start := p.pos
+		for c := p.peek(); c >= '0' && c <= '9'; c = p.peek() {
+			p.pos++
+		}
+		n, err := strconv.ParseUint(p.s[start:p.pos], 10, 64)
+		if err != nil || n == 0 || p.s[start] == '0' {
+			p.pos = start
+			return p.errorf("position must be a positive integer")
+		}
+		if len(elem.Keys) > 0 {
+			return p.errorf("position predicate following a key predicate")
+		}
+		elem.Position = n
+	} else {
+		var key string
+		if p.consume('.') {
+			key = "."
+		} else {
+			prefix, name, err := p.nodeIdentifier()
+			if err != nil {
+				return err
+			}
+			key = name
+			if prefix != "" {
+				key = prefix + ":" + name
+			}
+		}
+		if _, ok := elem.Keys["."]; ok || (key == "." && len(elem.Keys) > 0) {
+			return p.errorf("a leaf-list predicate must be the only predicate")
+		}
+		if _, ok := elem.Keys[key]; ok {
+			return p.errorf("duplicate predicate for %s", key)
+		}
+		p.skipSpace()
+		if !p.consume('=') {
+			return p.errorf("expected \"=\"")
+		}
+		p.skipSpace()
+		value, err := p.quotedString()
+		if err != nil {
+			return err
+		}
+		if elem.Keys == nil {
+			elem.Keys = map[string]string{}
+		}
+		elem.Keys[key] = value
+	}
+	p.skipSpace()
+	if !p.consume(']') {
+		return p.errorf("expected \"]\"")
+	}
+	return nil
+}
+
+// quotedString returns the contents of the single or double quoted string at
+// the current position.  XPath literals have no escapes, so the string ends
+// at the next matching quote.
+func (p *iidParser) quotedString() (string, error) {
+	q := p.peek()
+	if q != '\'' && q != '"' {
+		return "", p.errorf("expected a quoted string")
+	}
+	p.pos++
+	end := strings.IndexByte(p.s[p.pos:], q)
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	value := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	return value, nil
+}
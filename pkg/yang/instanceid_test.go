@@ -0,0 +1,136 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestParseInstanceIdentifier(t *testing.T) {
+	tests := []struct {
+		desc             string
+		in               string
+		want             []*InstanceIdentifierElem
+		wantErrSubstring string
+	}{{
+		desc: "simple path",
+		in:   "/ex:system/ex:hostname",
+		want: []*InstanceIdentifierElem{
+			{Prefix: "ex", Name: "system"},
+			{Prefix: "ex", Name: "hostname"},
+		},
+	}, {
+		desc: "key predicates",
+		in:   `/ex:interfaces/interface[name = 'eth0'][ex:unit="0"]/mtu`,
+		want: []*InstanceIdentifierElem{
+			{Prefix: "ex", Name: "interfaces"},
+			{Name: "interface", Keys: map[string]string{"name": "eth0", "ex:unit": "0"}},
+			{Name: "mtu"},
+		},
+	}, {
+		desc: "leaf-list and position predicates",
+		in:   "/ex:servers[.='a b']/ex:log[ 12 ]",
+		want: []*InstanceIdentifierElem{
+			{Prefix: "ex", Name: "servers", Keys: map[string]string{".": "a b"}},
+			{Prefix: "ex", Name: "log", Position: 12},
+		},
+	}, {
+		desc: "quotes in values",
+		in:   `/a[b="it's"][c='say "hi"']`,
+		want: []*InstanceIdentifierElem{
+			{Name: "a", Keys: map[string]string{"b": "it's", "c": `say "hi"`}},
+		},
+	}, {
+		desc:             "empty",
+		wantErrSubstring: "empty path",
+	}, {
+		desc:             "relative",
+		in:               "ex:system",
+		wantErrSubstring: `offset 0: expected "/"`,
+	}, {
+		desc:             "trailing slash",
+		in:               "/ex:system/",
+		wantErrSubstring: "offset 11: expected an identifier",
+	}, {
+		desc:             "bad identifier",
+		in:               "/1abc",
+		wantErrSubstring: "expected an identifier",
+	}, {
+		desc:             "missing value",
+		in:               "/a[b]",
+		wantErrSubstring: `expected "="`,
+	}, {
+		desc:             "unquoted value",
+		in:               "/a[b=c]",
+		wantErrSubstring: "expected a quoted string",
+	}, {
+		desc:             "unterminated value",
+		in:               "/a[b='c]",
+		wantErrSubstring: "unterminated string",
+	}, {
+		desc:             "unterminated predicate",
+		in:               "/a[b='c'",
+		wantErrSubstring: `expected "]"`,
+	}, {
+		desc:             "zero position",
+		in:               "/a[0]",
+		wantErrSubstring: "position must be a positive integer",
+	}, {
+		desc:             "duplicate key",
+		in:               "/a[b='1'][b='2']",
+		wantErrSubstring: "duplicate predicate for b",
+	}, {
+		desc:             "key and position",
+		in:               "/a[b='1'][2]",
+		wantErrSubstring: "position predicate following a key predicate",
+	}, {
+		desc:             "leaf-list and key",
+		in:               "/a[.='1'][b='2']",
+		wantErrSubstring: "a leaf-list predicate must be the only predicate",
+	}, {
+		desc:             "position and key",
+		in:               "/a[1][b='2']",
+		wantErrSubstring: "predicate following a position predicate",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := ParseInstanceIdentifier(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("ParseInstanceIdentifier(%q): %s", tt.in, diff)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseInstanceIdentifier(%q) (-want, +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
+func TestValidateInstanceIdentifier(t *testing.T) {
+	iid := &YangType{Name: "instance-identifier", Kind: YinstanceIdentifier}
+	if err := iid.ValidateInstanceIdentifier("/ex:a/b[c='d']"); err != nil {
+		t.Errorf("ValidateInstanceIdentifier: unexpected error: %v", err)
+	}
+	if err := iid.ValidateInstanceIdentifier("/ex:a/b[c]"); err == nil {
+		t.Error("ValidateInstanceIdentifier: got no error for a malformed path")
+	}
+	str := &YangType{Name: "string", Kind: Ystring}
+	if diff := errdiff.Substring(str.ValidateInstanceIdentifier("/ex:a"), "type string is not an instance-identifier"); diff != "" {
+		t.Errorf("ValidateInstanceIdentifier: %s", diff)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the check that lists representing configuration have
+// a key.
+
+// keylessListErrors returns an error for each list in the data trees of the
+// modules in ms that represents configuration but has no key statement, which
+// RFC7950 section 7.8.2 requires.  Lists that represent state data, and those
+// in the input or output of an operation or in a notification, need not have
+// a key; their Key is empty.  A keyless list in a grouping is reported once
+// however many times the grouping is used.
+func (ms *Modules) keylessListErrors() []error {
+	var errs []error
+	for _, name := range ms.moduleNames() {
+		m := ms.Modules[name]
+		var ie instanceErrors
+		addKeylessListErrors(&ie, ToEntry(m))
+		mErrs := ie.errors()
+		ms.noteErrors(m, mErrs...)
+		errs = append(errs, mErrs...)
+	}
+	return errs
+}
+
+// addKeylessListErrors adds the errors for the keyless configuration lists
+// found in e and its descendants to ie.
+func addKeylessListErrors(ie *instanceErrors, e *Entry) {
+	if e == nil || e.RPC != nil || e.Kind == NotificationEntry || e.ReadOnly() {
+		return
+	}
+	if e.IsList() && e.Key == "" {
+		ie.add(e.Node, "", "%s: list %s represents configuration and must have a key", Source(e.Node), e.Path())
+	}
+	for _, c := range e.Dir {
+		addKeylessListErrors(ie, c)
+	}
+}
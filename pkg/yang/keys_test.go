@@ -0,0 +1,118 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestKeylessLists(t *testing.T) {
+	tests := []struct {
+		desc             string
+		in               string
+		wantErrSubstring string
+	}{{
+		desc: "keyless state list",
+		in:   `container s { config false; list l { leaf v { type string; } } }`,
+	}, {
+		desc: "keyless config false list",
+		in:   `list l { config false; leaf v { type string; } }`,
+	}, {
+		desc: "keyless lists in an operation and a notification",
+		in: `
+			rpc r {
+				input { list l { leaf v { type string; } } }
+				output { list l { leaf v { type string; } } }
+			}
+			notification n { list l { leaf v { type string; } } }`,
+	}, {
+		desc: "keyless config list made state by a deviation",
+		in: `
+			list l { leaf v { type string; } }
+			deviation /l { deviate add { config false; } }`,
+	}, {
+		desc:             "keyless config list",
+		in:               `container c { list l { leaf v { type string; } } }`,
+		wantErrSubstring: "m.yang:1:57: list /m/c/l represents configuration and must have a key",
+	}, {
+		desc: "keyless config list in a grouping used three times",
+		in: `
+			grouping g { list l { leaf v { type string; } } }
+			container a { uses g; }
+			container b { uses g; }
+			container c { uses g; }`,
+		wantErrSubstring: "list /m/a/l represents configuration and must have a key (and 2 other uses)",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module m { prefix "m"; namespace "urn:m"; `+tt.in+` }`, "m.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Process: %s", diff)
+			}
+		})
+	}
+}
+
+func TestKeylessListEntry(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+			container s {
+				config false;
+				list l { leaf v { type string; mandatory true; } }
+			}
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["m"])
+	l := e.Dir["s"].Dir["l"]
+	if !l.IsList() || l.Key != "" {
+		t.Errorf("l: got IsList %t, Key %q, want a list with no key", l.IsList(), l.Key)
+	}
+	if k, ok := l.ListKeyName(); ok {
+		t.Errorf("l: ListKeyName: got %q, true, want false", k)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"m:s": {"l": [{"v": "a"}, {"v": "a"}, {}]}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, err := range e.Validate(data) {
+		got = append(got, err.Error())
+	}
+	if want := `/s/l[2]: missing mandatory leaf "v"`; len(got) != 1 || got[0] != want {
+		t.Errorf("Validate: got %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the resolution of leafref paths to the Entry they
+// reference.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A LeafrefEdge is a reference from a leaf or leaf-list of type leafref (or a
+// union containing a leafref) to the node named by its path statement.
+type LeafrefEdge struct {
+	Source *Entry    // the leaf or leaf-list with the leafref type
+	Type   *YangType // the leafref type, which may be a member of a union
+	Path   string    // the path statement of the leafref
+	Target *Entry    // the referenced node, nil if Err is set
+	Err    error     // non-nil if Path could not be resolved
+}
+
+// LeafrefEdges returns an edge for every leafref found in the schema trees of
+// the modules in ms.  Leafrefs whose path does not name a leaf or leaf-list
+// are returned with Err set rather than being omitted.  The edges are ordered
+// by module name and then by the path of their Source.  LeafrefEdges must be
+// called after a successful call to Process.
+func (ms *Modules) LeafrefEdges() []LeafrefEdge {
+	var edges []LeafrefEdge
+	for _, name := range ms.moduleNames() {
+		edges = appendLeafrefEdges(edges, ToEntry(ms.Modules[name]))
+	}
+	return edges
+}
+
+// appendLeafrefEdges appends the edges for the leafrefs found in e and its
+// descendants to edges, returning the result.
+func appendLeafrefEdges(edges []LeafrefEdge, e *Entry) []LeafrefEdge {
+	if e == nil {
+		return edges
+	}
+	if e.Type != nil {
+		for _, t := range leafrefTypes(e.Type) {
+			target, err := e.resolveLeafref(t.Path)
+			edges = append(edges, LeafrefEdge{
+				Source: e,
+				Type:   t,
+				Path:   t.Path,
+				Target: target,
+				Err:    err,
+			})
+		}
+	}
+	if e.RPC != nil {
+		edges = appendLeafrefEdges(edges, e.RPC.Input)
+		edges = appendLeafrefEdges(edges, e.RPC.Output)
+	}
+	var names []string
+	for name := range e.Dir {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		edges = appendLeafrefEdges(edges, e.Dir[name])
+	}
+	return edges
+}
+
+// leafrefTypes returns all leafref types found in y, including those that
+// are members of a union.
+func leafrefTypes(y *YangType) []*YangType {
+	switch y.Kind {
+	case Yleafref:
+		return []*YangType{y}
+	case Yunion:
+		var types []*YangType
+		for _, t := range y.Type {
+			types = append(types, leafrefTypes(t)...)
+		}
+		return types
+	}
+	return nil
+}
+
+// leafrefConfigErrors returns an error for each leafref in the data trees of
+// the modules in ms that represents configuration but refers to a node that
+// does not.  RFC7950 section 9.9 requires the target of such a leafref to
+// also be configuration unless require-instance is false.
+func (ms *Modules) leafrefConfigErrors() []error {
+	var errs []error
+	for _, edge := range ms.LeafrefEdges() {
+		switch {
+		case edge.Target == nil, edge.Type.OptionalInstance:
+		case !edge.Source.isDataNode(), edge.Source.ReadOnly():
+		case edge.Target.ReadOnly():
+			err := fmt.Errorf("%s: config true leafref %s refers to config false %s (path %q)", Source(edge.Source.Node), edge.Source.Path(), edge.Target.Path(), edge.Path)
+			ms.noteErrors(edge.Source.Node, err)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// isDataNode reports whether e is part of a data tree, rather than an
+// operation's input or output or a notification.
+func (e *Entry) isDataNode() bool {
+	for ; e != nil; e = e.Parent {
+		switch {
+		case e.RPC != nil, e.Kind == InputEntry, e.Kind == OutputEntry, e.Kind == NotificationEntry:
+			return false
+		}
+	}
+	return true
+}
+
+// ResolvedType returns the type of the values of the leaf or leaf-list e.  If
+// e is a leafref, the type of the node its path references is returned,
+// following a chain of leafrefs to the first node whose type is not a leafref.
+// An error is returned if a path in the chain cannot be resolved or the chain
+// leads back to a node already in it.  Leafrefs that are members of a union
+// are not followed.  The type of any other e, nil if it has none, is returned
+// as is.
+func (e *Entry) ResolvedType() (*YangType, error) {
+	seen := map[*Entry]bool{}
+	for cur := e; ; {
+		if cur.Type == nil || cur.Type.Kind != Yleafref {
+			return cur.Type, nil
+		}
+		if seen[cur] {
+			return nil, fmt.Errorf("%s: leafref %s refers to itself through %s", Source(e.Node), e.Path(), cur.Path())
+		}
+		seen[cur] = true
+		target, err := cur.resolveLeafref(cur.Type.Path)
+		if err != nil {
+			return nil, err
+		}
+		cur = target
+	}
+}
+
+// resolveLeafref returns the Entry referenced by the leafref path, evaluated
+// with e as the context node.  Choice and case nodes do not appear in the
+// data tree and so are skipped when walking path.  Predicates in path are
+// ignored as they do not change which schema node is referenced.
+func (e *Entry) resolveLeafref(path string) (*Entry, error) {
+	parts := strings.Split(stripPredicates(path), "/")
+	cur := e
+	if parts[0] == "" {
+		// An absolute path starts at the top of the module that the first
+		// element's prefix refers to, or the module e was defined in if
+		// there is no prefix.
+		parts = parts[1:]
+		prefix, _ := getPrefix(strings.TrimSpace(parts[0]))
+		m := FindModuleByPrefix(e.Node, prefix)
+		if m == nil {
+			return nil, fmt.Errorf("%s: leafref path %q: unknown prefix %q", Source(e.Node), path, prefix)
+		}
+		cur = ToEntry(module(m))
+	}
+	for _, part := range parts {
+		_, part = getPrefix(strings.TrimSpace(part))
+		switch part {
+		case "":
+			return nil, fmt.Errorf("%s: leafref path %q: empty path element", Source(e.Node), path)
+		case ".":
+		case "..":
+			cur = cur.Parent
+			for cur != nil && (cur.IsChoice() || cur.IsCase()) {
+				cur = cur.Parent
+			}
+			if cur == nil {
+				return nil, fmt.Errorf("%s: leafref path %q: too many \"..\" elements", Source(e.Node), path)
+			}
+		default:
+			next := cur.dataChild(part)
+			if next == nil {
+				return nil, fmt.Errorf("%s: leafref path %q: %s not found in %s", Source(e.Node), path, part, cur.Path())
+			}
+			cur = next
+		}
+	}
+	if !cur.IsLeaf() && !cur.IsLeafList() {
+		return nil, fmt.Errorf("%s: leafref path %q: %s is not a leaf or leaf-list", Source(e.Node), path, cur.Path())
+	}
+	return cur, nil
+}
+
+// dataChild returns the child of e named name as it would appear in the data
+// tree, looking through any choice and case nodes.  nil is returned if there
+// is no such child.
+func (e *Entry) dataChild(name string) *Entry {
+	if e.RPC != nil {
+		switch name {
+		case "input":
+			return e.RPC.Input
+		case "output":
+			return e.RPC.Output
+		}
+	}
+	if c := e.Dir[name]; c != nil && !c.IsChoice() && !c.IsCase() {
+		return c
+	}
+	for _, c := range e.Dir {
+		if c.IsChoice() || c.IsCase() {
+			if f := c.dataChild(name); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// stripPredicates returns path with all bracketed predicates removed.
+func stripPredicates(path string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range path {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,381 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestLeafrefEdges(t *testing.T) {
+	// edge is a summary of a LeafrefEdge that is easy to compare.
+	type edge struct {
+		source string
+		path   string
+		target string
+	}
+
+	tests := []struct {
+		desc             string
+		inModules        map[string]string
+		wantEdges        []edge
+		wantErrSubstring []string // one entry per edge with an unresolved path
+	}{{
+		desc: "relative and absolute leafrefs",
+		inModules: map[string]string{
+			"dev": `
+				module dev {
+					prefix "d";
+					namespace "urn:d";
+
+					container interfaces {
+						list interface {
+							key "name";
+							leaf name { type string; }
+						}
+					}
+
+					container refs {
+						leaf abs {
+							type leafref { path "/d:interfaces/d:interface/d:name"; }
+						}
+						leaf rel {
+							type leafref { path "../abs"; }
+						}
+						leaf pred {
+							type leafref {
+								path "/interfaces/interface[name = current()/../abs]/name";
+							}
+						}
+						leaf-list union {
+							type union {
+								type uint32;
+								type leafref { path "../rel"; }
+							}
+						}
+					}
+				}`,
+		},
+		wantEdges: []edge{
+			{"/dev/refs/abs", "/d:interfaces/d:interface/d:name", "/dev/interfaces/interface/name"},
+			{"/dev/refs/pred", "/interfaces/interface[name = current()/../abs]/name", "/dev/interfaces/interface/name"},
+			{"/dev/refs/rel", "../abs", "/dev/refs/abs"},
+			{"/dev/refs/union", "../rel", "/dev/refs/rel"},
+		},
+	}, {
+		desc: "leafrefs through choices and into another module",
+		inModules: map[string]string{
+			"target": `
+				module target {
+					prefix "t";
+					namespace "urn:t";
+
+					container system {
+						choice mode {
+							case a {
+								leaf hostname { type string; }
+							}
+						}
+					}
+				}`,
+			"source": `
+				module source {
+					prefix "s";
+					namespace "urn:s";
+
+					import target { prefix t; }
+
+					container c {
+						choice ch {
+							leaf sibling { type string; }
+						}
+						leaf remote {
+							type leafref { path "/t:system/t:hostname"; }
+						}
+						leaf local {
+							type leafref { path "../sibling"; }
+						}
+					}
+				}`,
+		},
+		wantEdges: []edge{
+			{"/source/c/local", "../sibling", "/source/c/ch/sibling/sibling"},
+			{"/source/c/remote", "/t:system/t:hostname", "/target/system/mode/a/hostname"},
+		},
+	}, {
+		desc: "dangling leafrefs",
+		inModules: map[string]string{
+			"dev": `
+				module dev {
+					prefix "d";
+					namespace "urn:d";
+
+					container c {
+						leaf missing {
+							type leafref { path "../nope"; }
+						}
+						leaf badprefix {
+							type leafref { path "/x:c/x:missing"; }
+						}
+						leaf notleaf {
+							type leafref { path "/c"; }
+						}
+					}
+				}`,
+		},
+		wantEdges: []edge{
+			{"/dev/c/badprefix", "/x:c/x:missing", ""},
+			{"/dev/c/missing", "../nope", ""},
+			{"/dev/c/notleaf", "/c", ""},
+		},
+		wantErrSubstring: []string{
+			`unknown prefix "x"`,
+			"nope not found in /dev/c",
+			"/dev/c is not a leaf or leaf-list",
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			for n, m := range tt.inModules {
+				if err := ms.Parse(m, n); err != nil {
+					t.Fatalf("cannot parse module %s, err: %v", n, err)
+				}
+			}
+			if errs := ms.Process(); len(errs) != 0 {
+				t.Fatalf("cannot process modules, got errors: %v", errs)
+			}
+
+			var got []edge
+			var errIdx int
+			for _, e := range ms.LeafrefEdges() {
+				got = append(got, edge{source: e.Source.Path(), path: e.Path, target: e.Target.Path()})
+				if e.Err == nil {
+					continue
+				}
+				if errIdx >= len(tt.wantErrSubstring) {
+					t.Errorf("%s: unexpected error: %v", e.Source.Path(), e.Err)
+					continue
+				}
+				if diff := errdiff.Substring(e.Err, tt.wantErrSubstring[errIdx]); diff != "" {
+					t.Errorf("%s: %s", e.Source.Path(), diff)
+				}
+				errIdx++
+			}
+			if errIdx != len(tt.wantErrSubstring) {
+				t.Errorf("got %d errors, want %d", errIdx, len(tt.wantErrSubstring))
+			}
+			if diff := cmp.Diff(tt.wantEdges, got, cmp.AllowUnexported(edge{})); diff != "" {
+				t.Errorf("LeafrefEdges (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLeafrefConfig(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inModule         string
+		wantErrSubstring string
+	}{{
+		desc: "config leafref to config",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf target { type string; }
+				leaf ref {
+					type leafref { path "../target"; }
+				}
+			}`,
+	}, {
+		desc: "config leafref to state",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				container state {
+					config false;
+					leaf target { type string; }
+				}
+				leaf ref {
+					type leafref { path "../state/target"; }
+				}
+			}`,
+		wantErrSubstring: "config true leafref /dev/ref refers to config false /dev/state/target",
+	}, {
+		desc: "config leafref to state in a union",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf target {
+					config false;
+					type string;
+				}
+				leaf ref {
+					type union {
+						type uint8;
+						type leafref { path "../target"; }
+					}
+				}
+			}`,
+		wantErrSubstring: "config true leafref /dev/ref refers to config false /dev/target",
+	}, {
+		desc: "require-instance false",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf target {
+					config false;
+					type string;
+				}
+				leaf ref {
+					type leafref {
+						path "../target";
+						require-instance false;
+					}
+				}
+			}`,
+	}, {
+		desc: "state leafref to state",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				container state {
+					config false;
+					leaf target { type string; }
+					leaf ref {
+						type leafref { path "../target"; }
+					}
+				}
+			}`,
+	}, {
+		desc: "rpc input leafref to state",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf target {
+					config false;
+					type string;
+				}
+				rpc r {
+					input {
+						leaf ref {
+							type leafref { path "/target"; }
+						}
+					}
+				}
+				notification n {
+					leaf ref {
+						type leafref { path "/target"; }
+					}
+				}
+			}`,
+	}, {
+		desc: "deviation makes the target state",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf target { type string; }
+				leaf ref {
+					type leafref { path "../target"; }
+				}
+				deviation /target {
+					deviate replace { config false; }
+				}
+			}`,
+		wantErrSubstring: "config true leafref /dev/ref refers to config false /dev/target",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(tt.inModule, "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Process: %s", diff)
+			}
+		})
+	}
+}
+
+func TestResolvedType(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module m {
+			prefix "m";
+			namespace "urn:m";
+
+			leaf a { type uint8 { range "1..10"; } }
+			leaf b { type leafref { path "../a"; } }
+			leaf c { type leafref { path "/m:b"; } }
+			leaf d { type union { type string; type leafref { path "../a"; } } }
+			leaf x { type leafref { path "../y"; } }
+			leaf y { type leafref { path "../x"; } }
+			leaf z { type leafref { path "../missing"; } }
+			container e { }
+		}`, "m.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["m"])
+
+	for _, tt := range []struct {
+		name             string
+		want             *YangType
+		wantErrSubstring string
+	}{
+		{name: "a", want: e.Dir["a"].Type},
+		{name: "b", want: e.Dir["a"].Type},
+		{name: "c", want: e.Dir["a"].Type},
+		{name: "d", want: e.Dir["d"].Type},
+		{name: "e"},
+		{name: "x", wantErrSubstring: "leafref /m/x refers to itself through /m/x"},
+		{name: "z", wantErrSubstring: `leafref path "../missing": missing not found in /m`},
+	} {
+		got, err := e.Dir[tt.name].ResolvedType()
+		if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+			t.Errorf("%s: %s", tt.name, diff)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got type %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -21,6 +21,7 @@ package yang
 //    tEOF         // end-of-file
 //    tString      // A de-quoted string (e.g., "\"bob\"" becomes "bob")
 //    tUnquoted    // An un-quoted string
+//    tComment     // A comment, only if the lexer keeps comments
 //    '{'
 //    ';'
 //    '}'
@@ -58,14 +59,15 @@ type lexer struct {
 	line  int    // the current line number (1's based)
 	col   int    // the current column number (0 based, add 1 before displaying)
 
-	debug     bool        // set to true to include internal debugging
-	inPattern bool        // set when parsing the argument to a pattern
-	items     chan *token // channel of scanned items.
-	tcol      int         // column with tabs expanded (for multi-line strings)
-	scol      int         // starting col of current token
-	sline     int         // starting line of current token
-	state     stateFn     // current state of the lexer
-	width     int         // width of last rune read from input.
+	debug        bool        // set to true to include internal debugging
+	inPattern    bool        // set when parsing the argument to a pattern
+	keepComments bool        // set to emit comments as tComment tokens
+	items        chan *token // channel of scanned items.
+	tcol         int         // column with tabs as 8 spaces (for multi-line strings)
+	scol         int         // starting col of current token
+	sline        int         // starting line of current token
+	state        stateFn     // current state of the lexer
+	width        int         // width of last rune read from input.
 }
 
 // A code is a token code.  Single character tokens (i.e., punctuation)
@@ -77,6 +79,7 @@ const (
 	tError                      // An error
 	tString                     // A dequoted string
 	tUnquoted                   // A non-quoted string
+	tComment                    // A comment, including its delimiters
 )
 
 // String returns c as a string.
@@ -88,6 +91,8 @@ func (c code) String() string {
 		return "String"
 	case tUnquoted:
 		return "Unquoted"
+	case tComment:
+		return "Comment"
 	}
 	if c < 0 || c > '~' {
 		return fmt.Sprintf("%d", c)
@@ -103,6 +108,7 @@ type token struct {
 	File string // the source file the token is from
 	Line int    // the source line number the token is from
 	Col  int    // the source column number the token is from (8 space tabs)
+	Pos  int    // the byte offset of the start of the token in the source
 }
 
 // Code returns the code of t.  If t is nil, tEOF is returned.
@@ -194,6 +200,7 @@ func (l *lexer) emitText(c code, text string) {
 		File: l.file,
 		Line: l.sline,
 		Col:  l.scol + 1,
+		Pos:  l.start,
 	}:
 	default:
 	}
@@ -206,14 +213,16 @@ func (l *lexer) consume() {
 }
 
 // backup steps back one rune.  It can be called only immediately after a call
-// of next.  Backing up over a tab will set tcol to the last position of the
-// tab, not where the tab started.  This is okay as when we call next again it
-// will move tcol back to where it was before backup was called.
+// of next.
 func (l *lexer) backup() {
 	l.pos -= l.width
 	if l.width > 0 {
 		l.col--
-		l.tcol--
+		if l.input[l.pos] == '\t' {
+			l.tcol -= 8
+		} else {
+			l.tcol--
+		}
 		if l.col < 0 {
 			// We must have backuped up over a newline.
 			// Don't bother to figure out the column number
@@ -249,7 +258,9 @@ func (l *lexer) next() (rune rune) {
 		l.col = 0
 		l.tcol = 0
 	case '\t':
-		l.tcol = (l.tcol + 8) & ^7
+		// RFC 7950 section 6.1.3 counts a tab as 8 spaces when
+		// trimming the indentation of a double quoted string.
+		l.tcol += 8
 		l.col++ // should this be l.width?
 	default:
 		l.tcol++
@@ -389,6 +400,9 @@ func lexGround(l *lexer) stateFn {
 				l.ErrorfAt(l.line, l.col-1, `lexer internal error: all lines should be newline-terminated.`)
 				return nil
 			}
+			if l.keepComments {
+				l.emitText(tComment, strings.TrimRight(l.input[l.start:l.pos], "\r"))
+			}
 			return lexGround
 		case '*':
 			// Start of a /* comment
@@ -399,6 +413,9 @@ func lexGround(l *lexer) stateFn {
 			// Now actually skip the */
 			l.next()
 			l.next()
+			if l.keepComments {
+				l.emit(tComment)
+			}
 			return lexGround
 		default:
 			return lexUnquoted
@@ -440,6 +457,7 @@ func lexQString(l *lexer) stateFn {
 
 	var text []byte
 	for {
+		start := l.tcol // the column of the next character
 		// l.next can return non-8bit unicode code points.
 		// c cannot be treated as only a single byte.
 		switch c := l.next(); c {
@@ -451,6 +469,12 @@ func lexQString(l *lexer) stateFn {
 
 			return lexGround
 		case '\n':
+			// The carriage return of a CRLF line break is kept,
+			// but is not part of the trailing white space.
+			cr := len(text) > 0 && text[len(text)-1] == '\r'
+			if cr {
+				text = text[:len(text)-1]
+			}
 		Loop:
 			// Trim trailing white space from the line.
 			for i := len(text); i > 0; {
@@ -462,6 +486,9 @@ func lexQString(l *lexer) stateFn {
 					break Loop
 				}
 			}
+			if cr {
+				text = append(text, '\r')
+			}
 			text = append(text, []byte(string(c))...)
 			over = false
 		case ' ', '\t':
@@ -469,6 +496,14 @@ func lexQString(l *lexer) stateFn {
 			if !over && l.tcol <= indent {
 				break
 			}
+			if !over && start < indent {
+				// This tab starts before our indent but ends
+				// after it.  Only the columns past our indent
+				// are kept, as spaces.
+				text = append(text, strings.Repeat(" ", l.tcol-indent)...)
+				over = true
+				break
+			}
 			over = true
 			text = append(text, []byte(string(c))...)
 		case '\\':
@@ -150,11 +150,12 @@ pattern '[a-zA-Z0-9!#$%&'+"'"+'*+/=?^_` + "`" + `{|}~-]+';
 			T(tString, "Broken\nline"),
 		}},
 		{line(), `
-// tab indent first line, spaces and tab second line
+// tab indent first line, spaces and tab second line, the tab counting as 8
+// spaces rather than as a tab stop
 	"Broken
     	 line"
 `, []*token{
-			T(tString, "Broken\nline"),
+			T(tString, "Broken\n    line"),
 		}},
 		{line(), `
 // tab indent first line, spaces second linfe
@@ -191,6 +192,45 @@ pattern '[a-zA-Z0-9!#$%&'+"'"+'*+/=?^_` + "`" + `{|}~-]+';
 `, []*token{
 			T(tString, "Broken\nspace with trailing space"),
 		}},
+		{line(), `
+// tab that crosses the indent, only the columns past the indent are kept
+  "Broken
+	line"
+`, []*token{
+			T(tString, "Broken\n     line"),
+		}},
+		{line(), `
+// tab after spaces that crosses the indent
+      "Broken
+    	line"
+`, []*token{
+			T(tString, "Broken\n     line"),
+		}},
+		{line(), `
+// mixed tab and space indentation, each tab counting as 8 spaces
+	  "Broken
+  	  line
+		indented"
+`, []*token{
+			T(tString, "Broken\n line\n     indented"),
+		}},
+		{line(), "// trailing space before a CRLF line break\n  \"Broken \t\r\n   line\"\n", []*token{
+			T(tString, "Broken\r\nline"),
+		}},
+		{line(), `
+// indented multi-line description
+  description
+    "The first line.
+     The second line.
+       An indented line.
+
+     After a blank line.
+   Less indented.";
+`, []*token{
+			T(tUnquoted, "description"),
+			T(tString, "The first line.\nThe second line.\n  An indented line.\n\nAfter a blank line.\nLess indented."),
+			T(';', ";"),
+		}},
 	} {
 		l := newLexer(tt.in, "")
 		// l.debug = true
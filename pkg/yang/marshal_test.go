@@ -122,7 +122,7 @@ func TestMarshalJSON(t *testing.T) {
 						Name: "union",
 						Type: []*YangType{{
 							Name:    "string",
-							Pattern: []string{"^a.*$"},
+							Pattern: []*YangPattern{{Expr: "^a.*$"}},
 							Kind:    Ystring,
 							Length: YangRange{{
 								Min: FromInt(10),
@@ -235,7 +235,9 @@ func TestMarshalJSON(t *testing.T) {
               }
             ],
             "Pattern": [
-              "^a.*$"
+              {
+                "Expr": "^a.*$"
+              }
             ]
           }
         ]
@@ -19,7 +19,10 @@ package yang
 // module into an Entry tree.
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -40,16 +43,49 @@ type Modules struct {
 	// ignored. The keys of the map are a string that is formed by concatenating
 	// the name of the including (sub)module and the included submodule.
 	mergedSubmodule map[string]bool
+	// failed holds the modules in which the last call to Process found
+	// errors, or which import such a module, and moduleErrs the errors
+	// found in each module.
+	failed     map[*Module]bool
+	moduleErrs map[*Module][]error
 	// ParseOptions sets the options for the current YANG module parsing. It can be
 	// directly set by the caller to influence how goyang will behave in the presence
 	// of certain exceptional cases.
 	ParseOptions Options
+	// DuplicatePolicy determines how a module or submodule is handled
+	// when one with the same name has already been read.
+	DuplicatePolicy DuplicatePolicy
 	// Path is the list of directories to look for .yang files in.
 	Path []string
+	// Reader, if not nil, is used instead of Path to read the modules and
+	// submodules named by import and include statements, and by
+	// GetModule, that have not already been read.
+	Reader ModuleReader
 	// pathMap is used to prevent adding dups in Path.
 	pathMap map[string]bool
+	// files holds the file each module or submodule read by Read was
+	// found in.
+	files map[*Module]string
 }
 
+// A DuplicatePolicy determines what happens when a module or submodule is
+// read into a Modules that already has one of the same name.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError, the default, reports an error for the second
+	// module, even if it has a different revision.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateKeepNewest keeps all the revisions read, each of which can
+	// be found by its full name (e.g., foo@2021-03-01), and uses the most
+	// recent revision for the name of the module itself.  A second module
+	// with the same revision as one already read is ignored.
+	DuplicateKeepNewest
+	// DuplicateKeepFirst ignores any module read after the first one of
+	// the same name.
+	DuplicateKeepFirst
+)
+
 // NewModules returns a newly created and initialized Modules.
 func NewModules() *Modules {
 	ms := &Modules{
@@ -60,7 +96,10 @@ func NewModules() *Modules {
 		typeDict:        newTypeDictionary(),
 		mergedSubmodule: map[string]bool{},
 		entryCache:      map[Node]*Entry{},
+		failed:          map[*Module]bool{},
+		moduleErrs:      map[*Module][]error{},
 		pathMap:         map[string]bool{},
+		files:           map[*Module]string{},
 	}
 	return ms
 }
@@ -74,7 +113,7 @@ func (ms *Modules) Read(name string) error {
 	if err != nil {
 		return err
 	}
-	return ms.Parse(data, name)
+	return ms.parse(data, name, name)
 }
 
 // Parse parses data as YANG source and adds it to ms.  The name should reflect
@@ -82,7 +121,13 @@ func (ms *Modules) Read(name string) error {
 // Note: If an error is returned, valid modules might still have been added to
 // the Modules cache.
 func (ms *Modules) Parse(data, name string) error {
-	ss, err := Parse(data, name)
+	return ms.parse(data, name, "")
+}
+
+// parse is Parse, recording file as the file the modules and submodules in
+// data were read from if it is not "".
+func (ms *Modules) parse(data, name, file string) error {
+	ss, err := parse(data, name, ms.ParseOptions)
 	if err != nil {
 		return err
 	}
@@ -94,6 +139,9 @@ func (ms *Modules) Parse(data, name string) error {
 		if err := ms.add(n); err != nil {
 			return err
 		}
+		if file != "" {
+			ms.files[n.(*Module)] = file
+		}
 	}
 	return nil
 }
@@ -107,7 +155,7 @@ func (ms *Modules) Parse(data, name string) error {
 // to calling GetModule.
 func (ms *Modules) GetModule(name string) (*Entry, []error) {
 	if ms.Modules[name] == nil {
-		if err := ms.Read(name); err != nil {
+		if err := ms.readModule(name, ""); err != nil {
 			return nil, []error{err}
 		}
 		if ms.Modules[name] == nil {
@@ -163,11 +211,22 @@ func (ms *Modules) add(n Node) error {
 
 	mod := n.(*Module)
 	fullName := mod.FullName()
-	mod.Modules = ms
 
-	if o := m[fullName]; o != nil {
-		return fmt.Errorf("duplicate %s %s at %s and %s", kind, fullName, Source(o), Source(n))
+	switch ms.DuplicatePolicy {
+	case DuplicateKeepFirst:
+		if m[name] != nil {
+			return nil
+		}
+	case DuplicateKeepNewest:
+		if m[fullName] != nil {
+			return nil
+		}
+	default:
+		if o := m[name]; o != nil {
+			return fmt.Errorf("duplicate %s %s at %s and %s", kind, name, Source(o), Source(n))
+		}
 	}
+	mod.Modules = ms
 	m[fullName] = mod
 	if fullName == name {
 		return nil
@@ -188,19 +247,22 @@ func (ms *Modules) add(n Node) error {
 func (ms *Modules) FindModule(n Node) *Module {
 	name := n.NName()
 	rev := name
+	var revision string
 	var m map[string]*Module
 
 	switch i := n.(type) {
 	case *Include:
 		m = ms.SubModules
 		if i.RevisionDate != nil {
-			rev = name + "@" + i.RevisionDate.Name
+			revision = i.RevisionDate.Name
+			rev = name + "@" + revision
 		}
 		// TODO(borman): we should check the BelongsTo field below?
 	case *Import:
 		m = ms.Modules
 		if i.RevisionDate != nil {
-			rev = name + "@" + i.RevisionDate.Name
+			revision = i.RevisionDate.Name
+			rev = name + "@" + revision
 		}
 	default:
 		return nil
@@ -212,12 +274,8 @@ func (ms *Modules) FindModule(n Node) *Module {
 		return n
 	}
 
-	// Try to read first a module by revision
-	if err := ms.Read(rev); err != nil {
-		// if failed, try to read a module by its bare name
-		if err := ms.Read(name); err != nil {
-			return nil
-		}
+	if err := ms.readModule(name, revision); err != nil {
+		return nil
 	}
 	if n := m[rev]; n != nil {
 		return n
@@ -256,6 +314,178 @@ func (ms *Modules) FindModuleByNamespace(ns string) (*Module, error) {
 	return found, nil
 }
 
+// namespaceErrors returns an error for each module in ms that declares the
+// same namespace as a module with a different name, which RFC7950 section
+// 7.1.3 does not allow.  The modules are taken in order of their names and
+// each module is reported against the first module declaring its namespace.
+// Different revisions of the same module may share a namespace.
+func (ms *Modules) namespaceErrors() []error {
+	var errs []error
+	first := map[string]*Module{}
+	for _, m := range sortedModules(ms.Modules) {
+		if m.Namespace == nil {
+			continue
+		}
+		ns := m.Namespace.Name
+		switch o := first[ns]; {
+		case o == nil:
+			first[ns] = m
+		case o.Name != m.Name:
+			err := fmt.Errorf("%s: module %s has the same namespace %q as module %s at %s", Source(m.Namespace), m.Name, ns, o.Name, Source(o.Namespace))
+			ms.noteErrors(m, err)
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Closure returns the module named module followed by the modules and
+// submodules it transitively imports or includes, i.e., the set of modules
+// needed to process module.  Each module appears once, in the order it is
+// first reached when following the import and include statements depth
+// first.  If an imported or included module is not already in ms then it is
+// searched for as it would be by Process.  An error is returned if module, or
+// any module it needs, cannot be found.
+func (ms *Modules) Closure(module string) ([]*Module, error) {
+	m := ms.Modules[module]
+	if m == nil {
+		return nil, fmt.Errorf("module not found: %s", module)
+	}
+	var mods []*Module
+	seen := map[*Module]bool{}
+	var walk func(*Module) error
+	walk = func(m *Module) error {
+		if seen[m] {
+			return nil
+		}
+		seen[m] = true
+		mods = append(mods, m)
+		for _, i := range m.Import {
+			im := i.Module
+			if im == nil {
+				if im = ms.FindModule(i); im == nil {
+					return fmt.Errorf("%s: no such module: %s", Source(i), i.Name)
+				}
+			}
+			if err := walk(im); err != nil {
+				return err
+			}
+		}
+		for _, i := range m.Include {
+			im := i.Module
+			if im == nil {
+				if im = ms.FindModule(i); im == nil {
+					return fmt.Errorf("%s: no such submodule: %s", Source(i), i.Name)
+				}
+			}
+			if err := walk(im); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(m); err != nil {
+		return nil, err
+	}
+	return mods, nil
+}
+
+// Notifications returns the notification entries found in the schema trees
+// of the modules in ms, including those nested within containers and lists
+// as allowed by YANG 1.1.  Each Entry holds the notification's payload in its
+// Dir.  The notifications are ordered by module name and then by path.
+// Notifications must be called after a successful call to Process.
+func (ms *Modules) Notifications() []*Entry {
+	var ns []*Entry
+	for _, name := range ms.moduleNames() {
+		ns = appendNotifications(ns, ToEntry(ms.Modules[name]))
+	}
+	return ns
+}
+
+// appendNotifications appends the notifications defined within e to ns,
+// returning the result.
+func appendNotifications(ns []*Entry, e *Entry) []*Entry {
+	var names []string
+	for name := range e.Dir {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch c := e.Dir[name]; {
+		case c.Kind == NotificationEntry:
+			ns = append(ns, c)
+		case c.RPC != nil:
+			// Operations cannot contain notifications.
+		default:
+			ns = appendNotifications(ns, c)
+		}
+	}
+	return ns
+}
+
+// Groupings returns an Entry for each grouping defined at the top level of
+// the modules and submodules in ms, keyed by the name of the module it is
+// defined in (the module a submodule belongs to), a colon and the name of
+// the grouping, e.g., "ietf-interfaces:interface-ref".  Each Entry is a
+// standalone copy of the grouping's schema tree, as it is before being
+// instantiated by a uses statement, with no Parent.  Groupings must be called
+// after a successful call to Process.
+func (ms *Modules) Groupings() map[string]*Entry {
+	gs := map[string]*Entry{}
+	for _, mods := range []map[string]*Module{ms.Modules, ms.SubModules} {
+		for name, m := range mods {
+			if name != m.Name {
+				// The revision qualified name of a module.
+				continue
+			}
+			owner := m.Name
+			if m.BelongsTo != nil {
+				owner = m.BelongsTo.Name
+			}
+			for _, g := range m.Grouping {
+				gs[owner+":"+g.Name] = ToEntry(g).dup()
+			}
+		}
+	}
+	return gs
+}
+
+// Augmenters returns the modules, other than the module named moduleName
+// itself, with an augment statement that was merged into the schema tree of
+// the module named moduleName, including into nodes added to that tree by
+// other augments.  An augment in a submodule is attributed to the module
+// the submodule belongs to.  The modules are sorted by name.  nil is
+// returned if there is no such module or it is not augmented.  Augmenters
+// must be called after a successful call to Process.
+func (ms *Modules) Augmenters(moduleName string) []*Module {
+	m := ms.Modules[moduleName]
+	if m == nil {
+		return nil
+	}
+	found := map[string]*Module{}
+	var walk func(*Entry)
+	walk = func(e *Entry) {
+		if e == nil {
+			return
+		}
+		for _, a := range e.Augmented {
+			if am := module(a.Node); am != nil && am != m {
+				found[am.Name] = am
+			}
+		}
+		if e.RPC != nil {
+			walk(e.RPC.Input)
+			walk(e.RPC.Output)
+		}
+		for _, c := range e.Dir {
+			walk(c)
+		}
+	}
+	walk(ToEntry(m))
+	return sortedModules(found)
+}
+
 // process satisfies all include and import statements and verifies that all
 // link ref paths reference a known node.  If an import or include references
 // a [sub]module that is not already known, Process will search for a .yang
@@ -280,12 +510,18 @@ func (ms *Modules) process() []error {
 		}
 	}
 
+	errs = append(errs, ms.namespaceErrors()...)
+
+	if ms.ParseOptions.Strict {
+		errs = append(errs, ms.extensionErrors()...)
+	}
+
 	// Resolve identities before resolving typedefs, otherwise when we resolve a
 	// typedef that has an identityref within it, then the identity dictionary
 	// has not yet been built.
 	errs = append(errs, ms.resolveIdentities()...)
 	// Append any errors found trying to resolve typedefs
-	errs = append(errs, ms.typeDict.resolveTypedefs()...)
+	errs = append(errs, ms.typeDict.resolveTypedefs(ms)...)
 
 	return errs
 }
@@ -301,6 +537,7 @@ func (ms *Modules) process() []error {
 // Process builds Entry trees for each modules and submodules in ms.  These
 // trees are accessed using the ToEntry function.  Process does augmentation
 // on Entry trees once all the modules and submodules in ms have been built.
+// An augment whose target is added by another augment is applied after it.
 // Following augmentation, Process inserts implied case statements.  I.e.,
 //
 //   choice interface-type {
@@ -315,93 +552,189 @@ func (ms *Modules) process() []error {
 //       }
 //   }
 //
+// Deviations are applied last, once every module has been loaded and
+// augmented, so the order in which modules were read does not matter.
+// Two modules declaring the same namespace are reported as an error.
+// Finally, leafrefs that represent configuration are checked to not refer to
+// nodes that do not, lists that represent configuration are checked to have
+// a key, and mandatory leaves are checked to not have a default.
+//
 // Process may return multiple errors if multiple errors were encountered
 // while processing.  Even though multiple errors may be returned, this does
 // not mean these are all the errors.  Process will terminate processing early
-// based on the type and location of the error.
+// based on the type and location of the error, unless
+// ParseOptions.ContinueOnError is set.  The errors found in each module are
+// returned by ModuleErrors.
 func (ms *Modules) Process() []error {
 	// Reset globals that may remain stale if multiple Process() calls are
 	// made by the same caller.
 	ms.mergedSubmodule = map[string]bool{}
 	ms.entryCache = map[Node]*Entry{}
+	ms.failed = map[*Module]bool{}
+	ms.moduleErrs = map[*Module][]error{}
 
 	errs := ms.process()
-	if len(errs) > 0 {
+	if len(errs) > 0 && !ms.ParseOptions.ContinueOnError {
 		return errorSort(errs)
 	}
+	errs = append(errs, ms.propagateFailures()...)
 
-	for _, m := range ms.Modules {
-		errs = append(errs, ToEntry(m).GetErrors()...)
-	}
-	for _, m := range ms.SubModules {
-		errs = append(errs, ToEntry(m).GetErrors()...)
+	for _, m := range ms.processable(append(sortedModules(ms.Modules), sortedModules(ms.SubModules)...)) {
+		entryErrs := ToEntry(m).GetErrors()
+		ms.noteErrors(m, entryErrs...)
+		errs = append(errs, entryErrs...)
 	}
 
-	if len(errs) > 0 {
+	if len(errs) > 0 && !ms.ParseOptions.ContinueOnError {
 		return errorSort(errs)
 	}
+	errs = append(errs, ms.propagateFailures()...)
 
-	// Now handle all the augments.  We don't have a good way to know
-	// what order to process them in, so repeat until no progress is made
-
-	mods := make([]*Module, 0, len(ms.Modules)+len(ms.SubModules))
-	for _, m := range ms.Modules {
-		mods = append(mods, m)
-	}
-	for _, m := range ms.SubModules {
-		mods = append(mods, m)
-	}
-	for len(mods) > 0 {
-		var processed int
-		for i := 0; i < len(mods); {
-			m := mods[i]
-			p, s := ToEntry(m).Augment(false)
-			processed += p
-			if s == 0 {
-				mods[i] = mods[len(mods)-1]
-				mods = mods[:len(mods)-1]
-				continue
-			}
-			i++
-		}
-		if processed == 0 {
-			break
+	// Now handle all the augments, applying those that target nodes added
+	// by other augments after them.
+	mods := ms.processable(append(sortedModules(ms.Modules), sortedModules(ms.SubModules)...))
+	processed := mods
+	applyAugments(mods)
+	var remaining []*Module
+	for _, m := range mods {
+		if len(ToEntry(m).Augments) > 0 {
+			remaining = append(remaining, m)
 		}
 	}
+	mods = remaining
 
 	// Now fix up all the choice statements to add in the missing case
 	// statements.
-	for _, m := range ms.Modules {
-		ToEntry(m).FixChoice()
-	}
-	for _, m := range ms.SubModules {
+	for _, m := range processed {
 		ToEntry(m).FixChoice()
 	}
 
-	// Go through any modules that have remaining augments and collect
-	// the errors.
+	// Go through any modules that have remaining augments to report them,
+	// and then collect the errors from all the modules, which include any
+	// nodes added by an augment that collide with nodes already present
+	// in its target.
 	for _, m := range mods {
 		ToEntry(m).Augment(true)
-		errs = append(errs, ToEntry(m).GetErrors()...)
+	}
+	seen := map[error]bool{}
+	for _, m := range processed {
+		for _, err := range ToEntry(m).GetErrors() {
+			if !seen[err] {
+				seen[err] = true
+				ms.noteErrors(m, err)
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	errs = append(errs, ms.applyDeviations()...)
+	if len(errs) == 0 || ms.ParseOptions.ContinueOnError {
+		// The config state of nodes is only final once deviations have
+		// been applied.
+		errs = append(errs, ms.propagateFailures()...)
+		errs = append(errs, ms.leafrefConfigErrors()...)
+		errs = append(errs, ms.uniqueErrors()...)
+		errs = append(errs, ms.keylessListErrors()...)
+		errs = append(errs, ms.mandatoryDefaultErrors()...)
 	}
 
-	// The deviation statement is only valid under a module or submodule,
-	// which allows us to avoid having to process it within ToEntry, and
-	// rather we can just walk all modules and submodules *after* entries
-	// are resolved. This means we do not need to concern ourselves that
-	// an entry does not exist.
+	return errorSort(errs)
+}
+
+// sortedModules returns the distinct modules in m ordered by the names they
+// are stored under.
+func sortedModules(m map[string]*Module) []*Module {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var mods []*Module
+	seen := map[*Module]bool{}
+	for _, name := range names {
+		if mod := m[name]; !seen[mod] {
+			seen[mod] = true
+			mods = append(mods, mod)
+		}
+	}
+	return mods
+}
+
+// applyDeviations applies the deviation statements of every module and
+// submodule in ms that has not failed to the Entry trees they target.
+//
+// The deviation statement is only valid under a module or submodule,
+// which allows us to avoid having to process it within ToEntry, and
+// rather we can just walk all modules and submodules *after* entries
+// are resolved and augmented. This means we do not need to concern ourselves
+// that an entry does not exist, or with the order in which the deviating and
+// deviated modules were read.
+func (ms *Modules) applyDeviations() []error {
+	var errs []error
 	dvP := map[string]bool{} // cache the modules we've handled since we have both modname and modname@revision-date
 	for _, devmods := range []map[string]*Module{ms.Modules, ms.SubModules} {
 		for _, m := range devmods {
+			if ms.hasFailed(m) {
+				continue
+			}
 			e := ToEntry(m)
 			if !dvP[e.Name] {
-				errs = append(errs, e.ApplyDeviate()...)
+				devErrs := e.ApplyDeviate()
+				ms.noteErrors(m, devErrs...)
+				errs = append(errs, devErrs...)
 				dvP[e.Name] = true
 			}
 		}
 	}
+	return errs
+}
 
-	return errorSort(errs)
+// ApplyDeviationModules reads the modules named by names, e.g., the
+// deviation modules of a vendor's profile, and applies their deviations to
+// the modules in ms they deviate, which are normally already read.  A name is
+// a module name or file name as accepted by Read; a module already in ms is
+// not read again.  Each of the modules named must have a deviation
+// statement.
+//
+// The modules of ms are then processed again as by Process, so the Entry
+// trees returned by ToEntry before ApplyDeviationModules was called no longer
+// reflect ms.  The error returned, if any, reports every error found while
+// processing, one per line; the errors of each module are returned by
+// ModuleErrors.
+func (ms *Modules) ApplyDeviationModules(names ...string) error {
+	for _, name := range names {
+		m := ms.Modules[name]
+		if m == nil {
+			before := map[*Module]bool{}
+			for _, m := range ms.Modules {
+				before[m] = true
+			}
+			if err := ms.Read(name); err != nil {
+				return err
+			}
+			for _, n := range ms.Modules {
+				if !before[n] {
+					m = n
+				}
+			}
+		}
+		switch {
+		case m == nil:
+			return fmt.Errorf("%s: not a module", name)
+		case len(m.Deviation) == 0:
+			return fmt.Errorf("%s: module %s has no deviation statements", Source(m), m.Name)
+		}
+	}
+
+	errs := ms.Process()
+	if len(errs) == 0 {
+		return nil
+	}
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return errors.New(strings.Join(msgs, "\n"))
 }
 
 // include resolves all the include and import statements for m.  It returns
@@ -417,7 +750,9 @@ func (ms *Modules) include(m *Module) error {
 	for _, i := range m.Include {
 		im := ms.FindModule(i)
 		if im == nil {
-			return fmt.Errorf("no such submodule: %s", i.Name)
+			err := fmt.Errorf("no such submodule: %s", i.Name)
+			ms.noteErrors(m, err)
+			return err
 		}
 		// Process the include statements in our included module.
 		if err := ms.include(im); err != nil {
@@ -431,7 +766,9 @@ func (ms *Modules) include(m *Module) error {
 	for _, i := range m.Import {
 		im := ms.FindModule(i)
 		if im == nil {
-			return fmt.Errorf("no such module: %s", i.Name)
+			err := fmt.Errorf("no such module: %s", i.Name)
+			ms.noteErrors(m, err)
+			return err
 		}
 		// Process the include statements in our included module.
 		if err := ms.include(im); err != nil {
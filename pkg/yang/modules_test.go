@@ -15,9 +15,14 @@
 package yang
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/openconfig/gnmi/errdiff"
 )
 
@@ -32,30 +37,104 @@ var testdataFindModulesText = map[string]string{
 }
 
 func TestDupModule(t *testing.T) {
+	const (
+		foo     = `module foo { prefix "foo"; namespace "urn:foo"; }`
+		fooOld  = `module foo { prefix "foo"; namespace "urn:foo"; revision 2020-01-01; }`
+		fooNew  = `module foo { prefix "foo"; namespace "urn:foo"; revision 2021-01-01; }`
+		fooSub  = `submodule foo-sub { belongs-to foo { prefix "foo"; } }`
+		fooSub2 = `submodule foo-sub { belongs-to foo { prefix "foo"; } revision 2021-01-01; }`
+	)
+
+	// module is the text of a module and the file it was read from.
+	type module struct {
+		file string
+		text string
+	}
+
 	tests := []struct {
-		desc      string
-		inModules map[string]string
-		wantErr   bool
+		desc             string
+		inPolicy         DuplicatePolicy
+		inModules        []module
+		wantErrSubstring string
+		wantFile         string // the file of the module or submodule found by name
+		wantRevisions    []string
 	}{{
-		desc: "two modules with the same name",
-		inModules: map[string]string{
-			"foo": `module foo { prefix "foo"; namespace "urn:foo"; }`,
-			"bar": `module foo { prefix "foo"; namespace "urn:foo"; }`,
-		},
-		wantErr: true,
+		desc:             "two modules with the same name",
+		inModules:        []module{{"foo", foo}, {"bar", foo}},
+		wantErrSubstring: "duplicate module foo at foo:1:1 and bar:1:1",
+	}, {
+		desc:             "the same revision of a module twice",
+		inModules:        []module{{"old", fooOld}, {"old2", fooOld}},
+		wantErrSubstring: "duplicate module foo at old:1:1 and old2:1:1",
+	}, {
+		desc:             "two revisions of a module",
+		inModules:        []module{{"new", fooNew}, {"old", fooOld}},
+		wantErrSubstring: "duplicate module foo at new:1:1 and old:1:1",
+	}, {
+		desc:             "two revisions of a submodule",
+		inModules:        []module{{"sub", fooSub}, {"sub2", fooSub2}},
+		wantErrSubstring: "duplicate submodule foo-sub at sub:1:1 and sub2:1:1",
+	}, {
+		desc:             "the same submodule twice",
+		inModules:        []module{{"sub", fooSub}, {"sub1", fooSub}},
+		wantErrSubstring: "duplicate submodule foo-sub at sub:1:1 and sub1:1:1",
+	}, {
+		desc:          "keep newest revision",
+		inPolicy:      DuplicateKeepNewest,
+		inModules:     []module{{"old", fooOld}, {"new", fooNew}, {"old2", fooOld}},
+		wantFile:      "new",
+		wantRevisions: []string{"foo@2020-01-01", "foo@2021-01-01"},
+	}, {
+		desc:          "keep newest revision read first",
+		inPolicy:      DuplicateKeepNewest,
+		inModules:     []module{{"new", fooNew}, {"old", fooOld}},
+		wantFile:      "new",
+		wantRevisions: []string{"foo@2020-01-01", "foo@2021-01-01"},
+	}, {
+		desc:      "keep newest with the same revision",
+		inPolicy:  DuplicateKeepNewest,
+		inModules: []module{{"foo", foo}, {"bar", foo}},
+		wantFile:  "foo",
+	}, {
+		desc:          "keep first",
+		inPolicy:      DuplicateKeepFirst,
+		inModules:     []module{{"old", fooOld}, {"new", fooNew}, {"bar", foo}},
+		wantFile:      "old",
+		wantRevisions: []string{"foo@2020-01-01"},
 	}}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			ms := NewModules()
+			ms.DuplicatePolicy = tt.inPolicy
 			var err error
-			for name, modtext := range tt.inModules {
-				if err = ms.Parse(modtext, name+".yang"); err != nil {
+			for _, m := range tt.inModules {
+				if err = ms.Parse(m.text, m.file); err != nil {
 					break
 				}
 			}
-			if gotErr := err != nil; gotErr != tt.wantErr {
-				t.Fatalf("wantErr: %v, got error: %v", tt.wantErr, err)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("Parse: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			m := ms.Modules["foo"]
+			if m == nil {
+				m = ms.SubModules["foo-sub"]
+			}
+			if got := m.Source.file; got != tt.wantFile {
+				t.Errorf("got module from file %q, want %q", got, tt.wantFile)
+			}
+			var got []string
+			for name := range ms.Modules {
+				if name != "foo" {
+					got = append(got, name)
+				}
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tt.wantRevisions, got); diff != "" {
+				t.Errorf("revisions (-want, +got):\n%s", diff)
 			}
 		})
 	}
@@ -68,11 +147,16 @@ func testModulesForTestdataModulesText(t *testing.T) *Modules {
 			t.Fatalf("error importing testdataFindModulesText[%q]: %v", name, err)
 		}
 	}
-	if errs := ms.Process(); errs != nil {
+	// The modules sharing a namespace are the only ones with an error.
+	errs := ms.Process()
+	if len(errs) != 1 {
 		for _, err := range errs {
 			t.Errorf("error: %v", err)
 		}
-		t.Fatalf("fatal error(s) calling Process()")
+		t.Fatalf("got %d errors calling Process(), want 1", len(errs))
+	}
+	if diff := errdiff.Substring(errs[0], `module dup-ns-two has the same namespace "urn:duplicate" as module dup-ns-one`); diff != "" {
+		t.Fatalf("Process: %s", diff)
 	}
 	return ms
 }
@@ -128,6 +212,58 @@ func TestModulesFindByNamespace(t *testing.T) {
 	}
 }
 
+func TestNamespaceErrors(t *testing.T) {
+	tests := []struct {
+		desc     string
+		inPolicy DuplicatePolicy
+		inFiles  map[string]string
+		wantErrs []string
+	}{{
+		desc: "distinct namespaces",
+		inFiles: map[string]string{
+			"a.yang": `module a { prefix "a"; namespace "urn:a"; }`,
+			"b.yang": `module b { prefix "b"; namespace "urn:b"; }`,
+		},
+	}, {
+		desc: "cloned module",
+		inFiles: map[string]string{
+			"a.yang":      `module a { prefix "a"; namespace "urn:a"; }`,
+			"a-copy.yang": `module a-copy { prefix "a"; namespace "urn:a"; }`,
+			"b.yang":      `module b { prefix "b"; namespace "urn:a"; }`,
+		},
+		wantErrs: []string{
+			`a-copy.yang:1:29: module a-copy has the same namespace "urn:a" as module a at a.yang:1:24`,
+			`b.yang:1:24: module b has the same namespace "urn:a" as module a at a.yang:1:24`,
+		},
+	}, {
+		desc:     "revisions of a module",
+		inPolicy: DuplicateKeepNewest,
+		inFiles: map[string]string{
+			"old.yang": `module a { prefix "a"; namespace "urn:a"; revision 2020-01-01; }`,
+			"new.yang": `module a { prefix "a"; namespace "urn:a"; revision 2021-01-01; }`,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			ms.DuplicatePolicy = tt.inPolicy
+			for name, text := range tt.inFiles {
+				if err := ms.Parse(text, name); err != nil {
+					t.Fatalf("cannot parse %s: %v", name, err)
+				}
+			}
+			var got []string
+			for _, err := range ms.Process() {
+				got = append(got, err.Error())
+			}
+			if diff := cmp.Diff(tt.wantErrs, got); diff != "" {
+				t.Errorf("Process (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestModuleLinkage(t *testing.T) {
 	tests := []struct {
 		desc          string
@@ -412,3 +548,759 @@ func TestModulesTotalProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviationReadOrder(t *testing.T) {
+	target := `
+		module target {
+			prefix "t";
+			namespace "urn:t";
+
+			container c {
+				leaf removed { type string; }
+				leaf replaced { type string; }
+			}
+		}`
+	deviations := `
+		module deviations {
+			prefix "d";
+			namespace "urn:d";
+
+			import target { prefix t; }
+
+			deviation /t:c/t:removed {
+				deviate not-supported;
+			}
+
+			deviation /t:c/t:replaced {
+				deviate replace {
+					type uint16;
+				}
+			}
+		}`
+
+	tests := []struct {
+		desc      string
+		inModules []string // in the order they are parsed
+	}{{
+		desc:      "target module read first",
+		inModules: []string{"target", "deviations"},
+	}, {
+		desc:      "deviation module read first",
+		inModules: []string{"deviations", "target"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			for _, name := range tt.inModules {
+				text := map[string]string{"target": target, "deviations": deviations}[name]
+				if err := ms.Parse(text, name+".yang"); err != nil {
+					t.Fatalf("cannot parse module %s: %v", name, err)
+				}
+			}
+			if errs := ms.Process(); len(errs) != 0 {
+				t.Fatalf("cannot process modules: %v", errs)
+			}
+
+			c := ToEntry(ms.Modules["target"]).Dir["c"]
+			if c == nil {
+				t.Fatalf("container c not found in target")
+			}
+			if e := c.Dir["removed"]; e != nil {
+				t.Errorf("leaf removed was not removed by deviation, got: %v", e)
+			}
+			e := c.Dir["replaced"]
+			if e == nil {
+				t.Fatalf("leaf replaced not found in target")
+			}
+			if got, want := e.Type.Kind, Yuint16; got != want {
+				t.Errorf("leaf replaced has type %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestModulesClosure(t *testing.T) {
+	inModules := map[string]string{
+		"top": `
+			module top {
+				prefix "t";
+				namespace "urn:t";
+				import mid { prefix m; }
+				import shared { prefix s; }
+				include top-sub;
+			}`,
+		"top-sub": `
+			submodule top-sub {
+				belongs-to top { prefix t; }
+				import leaf-mod { prefix l; }
+			}`,
+		"mid": `
+			module mid {
+				prefix "m";
+				namespace "urn:m";
+				import shared { prefix s; }
+			}`,
+		"shared": `
+			module shared {
+				prefix "s";
+				namespace "urn:s";
+			}`,
+		"leaf-mod": `
+			module leaf-mod {
+				prefix "l";
+				namespace "urn:l";
+			}`,
+		"unused": `
+			module unused {
+				prefix "u";
+				namespace "urn:u";
+				import shared { prefix s; }
+			}`,
+		"broken": `
+			module broken {
+				prefix "b";
+				namespace "urn:b";
+				import missing { prefix x; }
+			}`,
+	}
+
+	tests := []struct {
+		desc             string
+		inModule         string
+		wantModules      []string
+		wantErrSubstring string
+	}{{
+		desc:        "imports and includes",
+		inModule:    "top",
+		wantModules: []string{"top", "mid", "shared", "top-sub", "leaf-mod"},
+	}, {
+		desc:        "module without dependents",
+		inModule:    "shared",
+		wantModules: []string{"shared"},
+	}, {
+		desc:             "unknown module",
+		inModule:         "nope",
+		wantErrSubstring: "module not found: nope",
+	}, {
+		desc:             "missing import",
+		inModule:         "broken",
+		wantErrSubstring: "no such module: missing",
+	}}
+
+	ms := NewModules()
+	for name, text := range inModules {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			mods, err := ms.Closure(tt.inModule)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("Closure(%s): %s", tt.inModule, diff)
+			}
+			var got []string
+			for _, m := range mods {
+				got = append(got, m.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantModules, ",") {
+				t.Errorf("Closure(%s): got %v, want %v", tt.inModule, got, tt.wantModules)
+			}
+		})
+	}
+}
+
+func TestModulesNotifications(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"alpha": `
+			module alpha {
+				yang-version 1.1;
+				prefix "a";
+				namespace "urn:a";
+
+				notification top {
+					leaf reason { type string; }
+				}
+				container system {
+					list interface {
+						key "name";
+						leaf name { type string; }
+						notification link-down {
+							leaf at { type string; }
+						}
+					}
+				}
+				rpc reset {
+					input { leaf delay { type uint32; } }
+				}
+			}`,
+		"beta": `
+			module beta {
+				yang-version 1.1;
+				prefix "b";
+				namespace "urn:b";
+
+				import alpha { prefix a; }
+
+				augment /a:system {
+					notification rebooted;
+				}
+				notification alarm;
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	var got []string
+	for _, n := range ms.Notifications() {
+		got = append(got, n.Path())
+	}
+	want := []string{
+		"/alpha/system/interface/link-down",
+		"/alpha/system/rebooted",
+		"/alpha/top",
+		"/beta/alarm",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Notifications (-want, +got):\n%s", diff)
+	}
+
+	for _, n := range ms.Notifications() {
+		if n.Name == "top" && n.Dir["reason"] == nil {
+			t.Errorf("notification %s is missing its payload", n.Path())
+		}
+	}
+}
+
+func TestModuleRevisions(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			revision 2021-03-01 {
+				description "Add the interfaces container.";
+				reference "RFC 9999";
+			}
+			revision 2020-06-15 {
+				description "Initial revision.";
+			}
+			revision 2020-11-30;
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	m := ms.Modules["dev"]
+
+	want := []*RevisionInfo{
+		{Date: "2021-03-01", Description: "Add the interfaces container.", Reference: "RFC 9999"},
+		{Date: "2020-06-15", Description: "Initial revision."},
+		{Date: "2020-11-30"},
+	}
+	if diff := cmp.Diff(want, m.Revisions()); diff != "" {
+		t.Errorf("Revisions (-want, +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct {
+		date string
+		want string
+	}{
+		{"2022-01-01", "2021-03-01"},
+		{"2021-03-01", "2020-11-30"},
+		{"2020-12-01", "2020-11-30"},
+		{"2020-11-30", "2020-06-15"},
+		{"2020-06-15", ""},
+	} {
+		var got string
+		if r := m.RevisionBefore(tt.date); r != nil {
+			got = r.Name
+		}
+		if got != tt.want {
+			t.Errorf("RevisionBefore(%q): got %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestModulesGroupings(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"alpha": `
+			module alpha {
+				prefix "a";
+				namespace "urn:a";
+
+				include alpha-sub;
+
+				grouping address {
+					leaf ip { type string; }
+					uses port;
+				}
+				grouping port {
+					leaf port { type uint16; }
+				}
+				container server {
+					uses address;
+					grouping local {
+						leaf unused { type string; }
+					}
+				}
+			}`,
+		"alpha-sub": `
+			submodule alpha-sub {
+				belongs-to alpha { prefix "a"; }
+
+				grouping counters {
+					leaf in { type uint64; }
+				}
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	got := map[string][]string{}
+	for name, g := range ms.Groupings() {
+		if g.Parent != nil {
+			t.Errorf("%s: got Parent %s, want nil", name, g.Parent.Path())
+		}
+		got[name] = g.LeafPaths()
+	}
+	want := map[string][]string{
+		"alpha:address":  {"/address/ip", "/address/port"},
+		"alpha:port":     {"/port/port"},
+		"alpha:counters": {"/counters/in"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Groupings (-want, +got):\n%s", diff)
+	}
+
+	// Changing a returned grouping must not change the schema tree.
+	ms.Groupings()["alpha:address"].Dir["ip"].Description = "changed"
+	if d := ToEntry(ms.Modules["alpha"]).Dir["server"].Dir["ip"].Description; d != "" {
+		t.Errorf("server/ip description: got %q, want \"\"", d)
+	}
+}
+
+func TestAugmenters(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"base": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+
+				container system {}
+				rpc reboot { input {} }
+				augment /b:system {
+					leaf self { type string; }
+				}
+			}`,
+		"ext-a": `
+			module ext-a {
+				prefix "a";
+				namespace "urn:a";
+
+				include ext-a-sub;
+			}`,
+		"ext-a-sub": `
+			submodule ext-a-sub {
+				belongs-to ext-a { prefix "a"; }
+
+				import base { prefix b; }
+
+				augment /b:system {
+					container ntp {}
+				}
+			}`,
+		"ext-b": `
+			module ext-b {
+				prefix "x";
+				namespace "urn:x";
+
+				import base { prefix b; }
+				import ext-a { prefix a; }
+
+				augment /b:system/a:ntp {
+					leaf server { type string; }
+				}
+				augment /b:reboot/b:input {
+					leaf delay { type uint32; }
+				}
+			}`,
+		"other": `
+			module other {
+				prefix "o";
+				namespace "urn:o";
+
+				import base { prefix b; }
+
+				leaf ref { type string; }
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	names := func(mods []*Module) []string {
+		var names []string
+		for _, m := range mods {
+			names = append(names, m.Name)
+		}
+		return names
+	}
+	for _, tt := range []struct {
+		module string
+		want   []string
+	}{
+		{"base", []string{"ext-a", "ext-b"}},
+		{"ext-a", nil},
+		{"other", nil},
+		{"missing", nil},
+	} {
+		if diff := cmp.Diff(tt.want, names(ms.Augmenters(tt.module))); diff != "" {
+			t.Errorf("Augmenters(%q) (-want, +got):\n%s", tt.module, diff)
+		}
+	}
+}
+
+func TestImportIncludeDescription(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"dev": `
+			module dev {
+				yang-version 1.1;
+				prefix "d";
+				namespace "urn:d";
+
+				import base {
+					prefix b;
+					description "Base definitions.";
+					reference "RFC 0000";
+				}
+				include dev-sub {
+					revision-date 2021-01-01;
+					description "The types.";
+					reference "Section 1";
+				}
+			}`,
+		"dev-sub": `
+			submodule dev-sub {
+				yang-version 1.1;
+				belongs-to dev { prefix d; }
+
+				revision 2021-01-01;
+			}`,
+		"base": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	m := ms.Modules["dev"]
+	for _, tt := range []struct {
+		desc string
+		got  *Value
+		want string
+	}{
+		{"import description", m.Import[0].Description, "Base definitions."},
+		{"import reference", m.Import[0].Reference, "RFC 0000"},
+		{"include description", m.Include[0].Description, "The types."},
+		{"include reference", m.Include[0].Reference, "Section 1"},
+	} {
+		if got := tt.got.asString(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixMap(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"dev": `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				import base { prefix b; }
+				include dev-sub;
+			}`,
+		"dev-sub": `
+			submodule dev-sub {
+				belongs-to dev { prefix dv; }
+
+				import base { prefix base; }
+				import other { prefix o; }
+			}`,
+		"base": `
+			module base {
+				prefix "b";
+				namespace "urn:b";
+			}`,
+		"other": `
+			module other {
+				prefix "o";
+				namespace "urn:o";
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	names := func(m map[string]*Module) map[string]string {
+		n := map[string]string{}
+		for p, mod := range m {
+			n[p] = mod.Name
+		}
+		return n
+	}
+	for _, tt := range []struct {
+		module *Module
+		want   map[string]string
+	}{
+		{ms.Modules["dev"], map[string]string{"d": "dev", "b": "base"}},
+		{ms.SubModules["dev-sub"], map[string]string{"dv": "dev", "base": "base", "o": "other"}},
+		{ms.Modules["base"], map[string]string{"b": "base"}},
+	} {
+		if diff := cmp.Diff(tt.want, names(tt.module.PrefixMap())); diff != "" {
+			t.Errorf("%s: PrefixMap (-want, +got):\n%s", tt.module.Name, diff)
+		}
+	}
+}
+
+func TestContinueOnError(t *testing.T) {
+	modules := map[string]string{
+		// a imports a module that does not exist, and b imports a.
+		"a": `module a { prefix a; namespace urn:a; import missing { prefix m; } leaf x { type string; } }`,
+		"b": `module b { prefix b; namespace urn:b; import a { prefix a; } leaf y { type string; } }`,
+		// c has a typedef that does not resolve.
+		"c": `module c { prefix c; namespace urn:c; typedef t { type unknown; } leaf z { type t; } }`,
+		// d and e are independent of the others and are valid.
+		"d": `module d { prefix d; namespace urn:d; container top { leaf l { type string; } } }`,
+		"e": `module e {
+			prefix e;
+			namespace urn:e;
+			import d { prefix d; }
+			augment /d:top { leaf m { type string; } }
+			deviation /d:top/d:l { deviate add { default "v"; } }
+		}`,
+	}
+	parse := func(t *testing.T, ms *Modules) {
+		t.Helper()
+		for name, text := range modules {
+			if err := ms.Parse(text, name+".yang"); err != nil {
+				t.Fatalf("cannot parse %s: %v", name, err)
+			}
+		}
+	}
+
+	ms := NewModules()
+	parse(t, ms)
+	errs := ms.Process()
+	if len(errs) == 0 {
+		t.Fatalf("Process without ContinueOnError: got no errors, want errors")
+	}
+	if ms.entryCache[ms.Modules["d"]] != nil {
+		t.Errorf("Process without ContinueOnError: d was processed")
+	}
+
+	ms = NewModules()
+	ms.ParseOptions.ContinueOnError = true
+	parse(t, ms)
+	var got []string
+	for _, err := range ms.Process() {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		"b.yang:1:39: b not processed: imported module a has errors",
+		"c.yang:1:51: unknown type: c:unknown",
+		"no such module: missing",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Process errors (-want, +got):\n%s", diff)
+	}
+
+	gotByModule := map[string][]string{}
+	for name, errs := range ms.ModuleErrors() {
+		for _, err := range errs {
+			gotByModule[name] = append(gotByModule[name], err.Error())
+		}
+	}
+	wantByModule := map[string][]string{
+		"a": {"no such module: missing"},
+		"b": {"b.yang:1:39: b not processed: imported module a has errors"},
+		"c": {"c.yang:1:51: unknown type: c:unknown"},
+	}
+	if diff := cmp.Diff(wantByModule, gotByModule); diff != "" {
+		t.Errorf("ModuleErrors (-want, +got):\n%s", diff)
+	}
+
+	top := ToEntry(ms.Modules["d"]).Dir["top"]
+	if top.Dir["m"] == nil {
+		t.Errorf("augment of d by e not applied")
+	}
+	if got := top.Dir["l"].DefaultValues(); len(got) != 1 || got[0] != "v" {
+		t.Errorf("deviation of d by e not applied: got default %v, want [v]", got)
+	}
+	if diff := cmp.Diff([]string{"d", "e"}, ms.moduleNames()); diff != "" {
+		t.Errorf("moduleNames (-want, +got):\n%s", diff)
+	}
+}
+
+func TestApplyDeviationModules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goyang-deviation-modules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, text := range map[string]string{
+		"vendor-deviations.yang": `
+			module vendor-deviations {
+				prefix "v";
+				namespace "urn:v";
+				import target { prefix t; }
+				deviation /t:c/t:removed { deviate not-supported; }
+				deviation /t:c/t:replaced { deviate replace { type uint16; } }
+			}`,
+		"bad-deviations.yang": `
+			module bad-deviations {
+				prefix "b";
+				namespace "urn:b";
+				import target { prefix t; }
+				deviation /t:c/t:missing { deviate not-supported; }
+			}`,
+		"no-deviations.yang": `module no-deviations { prefix "n"; namespace "urn:n"; }`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	newModules := func(t *testing.T) *Modules {
+		t.Helper()
+		ms := NewModules()
+		ms.AddPath(dir)
+		if err := ms.Parse(`
+			module target {
+				prefix "t";
+				namespace "urn:t";
+				container c {
+					leaf removed { type string; }
+					leaf replaced { type string; }
+				}
+			}`, "target.yang"); err != nil {
+			t.Fatal(err)
+		}
+		if errs := ms.Process(); len(errs) != 0 {
+			t.Fatalf("cannot process modules: %v", errs)
+		}
+		return ms
+	}
+
+	ms := newModules(t)
+	if err := ms.ApplyDeviationModules("vendor-deviations"); err != nil {
+		t.Fatalf("ApplyDeviationModules: %v", err)
+	}
+	c := ToEntry(ms.Modules["target"]).Dir["c"]
+	if e := c.Dir["removed"]; e != nil {
+		t.Errorf("leaf removed was not removed by the deviation")
+	}
+	if e := c.Dir["replaced"]; e == nil || e.Type.Kind != Yuint16 {
+		t.Errorf("leaf replaced was not replaced by the deviation: got %v", e)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		wantErr string
+	}{
+		{"bad-deviations", "cannot find target node to deviate, /t:c/t:missing"},
+		{"no-deviations", "no-deviations.yang:1:1: module no-deviations has no deviation statements"},
+		{"missing", "no such file: missing.yang"},
+	} {
+		err := newModules(t).ApplyDeviationModules(tt.name)
+		if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+			t.Errorf("ApplyDeviationModules(%q): %s", tt.name, diff)
+		}
+	}
+}
+
+func TestSubmoduleUnprefixedReferences(t *testing.T) {
+	ms := NewModules()
+	for name, src := range map[string]string{
+		"m.yang": `module m {
+			yang-version 1.1;
+			prefix "m";
+			namespace "urn:m";
+			include s1;
+			include s2;
+			typedef mt { type string; }
+			identity base;
+			container c { uses s2g; }
+		}`,
+		// In YANG 1.1 a submodule may refer to the definitions of the
+		// module and its other submodules without including them, and
+		// unprefixed nodes are those of the module.
+		"s1.yang": `submodule s1 {
+			yang-version 1.1;
+			belongs-to m { prefix mm; }
+			typedef s1t { type mt; }
+			identity s1id { base base; }
+			leaf b { type s1t; }
+			leaf i { type identityref { base base; } default s1id; }
+			leaf r { type leafref { path "/c/x"; } }
+			augment /c { leaf a { type s1t; } }
+		}`,
+		"s2.yang": `submodule s2 {
+			yang-version 1.1;
+			belongs-to m { prefix m2; }
+			grouping s2g { leaf x { type s1t; } leaf y { type m2:mt; } }
+			deviation /c/y { deviate add { default "d"; } }
+		}`,
+	} {
+		if err := ms.Parse(src, name); err != nil {
+			t.Fatalf("cannot parse %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	c := ToEntry(ms.Modules["m"]).Dir["c"]
+	for _, name := range []string{"a", "x", "y"} {
+		if c.Dir[name] == nil {
+			t.Errorf("/m/c/%s not found", name)
+		}
+	}
+	if got, want := c.Dir["a"].Type.Name, "s1t"; got != want {
+		t.Errorf("/m/c/a: got type %s, want %s", got, want)
+	}
+	if diff := cmp.Diff([]string{"d"}, c.Dir["y"].Default); diff != "" {
+		t.Errorf("/m/c/y default (-want, +got):\n%s", diff)
+	}
+}
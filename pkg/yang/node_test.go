@@ -536,7 +536,7 @@ func TestModulesFindByPrefix(t *testing.T) {
 	modules := map[string]string{
 		"foo": `module foo { prefix "foo"; namespace "urn:foo"; include bar; leaf leafref { type leafref { path "../foo:leaf"; } } uses foo:lg; }`,
 		"bar": `submodule bar { belongs-to foo { prefix "bar"; } container c { uses bar:lg; } grouping lg { leaf leaf { type string; } } }`,
-		"baz": `module baz { prefix "foo"; namespace "urn:foo"; import foo { prefix f; } extension e; uses f:lg; foo:e; }`,
+		"baz": `module baz { prefix "foo"; namespace "urn:baz"; import foo { prefix f; } extension e; uses f:lg; foo:e; }`,
 	}
 
 	ms := NewModules()
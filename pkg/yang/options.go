@@ -14,6 +14,8 @@
 
 package yang
 
+import "time"
+
 // Options defines the options that should be used when parsing YANG modules,
 // including specific overrides for potentially problematic YANG constructs.
 type Options struct {
@@ -27,4 +29,34 @@ type Options struct {
 	// generated within the schema to store the logical grouping from which it
 	// is derived.
 	StoreUses bool
+	// URLCacheDir, if set, is the directory in which module files fetched
+	// from http:// and https:// entries in Path are cached.  A file found in
+	// the cache is used without being fetched again.
+	URLCacheDir string
+	// URLTimeout limits the time taken to fetch a module file from an
+	// http:// or https:// entry in Path.  If zero, DefaultURLTimeout is
+	// used.
+	URLTimeout time.Duration
+	// Strict, if true, causes Process to report an error for each
+	// extension statement whose prefix does not name a loaded module or
+	// whose keyword is not defined by an extension statement in that
+	// module.  Unknown statements without a prefix are always errors.
+	Strict bool
+	// PreserveComments, if true, causes the comments in the modules read
+	// to be kept in the Comments and EndComments of the Statement that
+	// follows or encloses them.  Comments are discarded by default.
+	PreserveComments bool
+	// KeepSource, if true, causes the text of each statement in the
+	// modules read to be kept, along with its byte offset in its file,
+	// for the Raw and Span methods of Statement.  The text is discarded
+	// by default.
+	KeepSource bool
+	// ContinueOnError, if true, causes Process to continue processing the
+	// modules not affected by an error, rather than stopping once errors
+	// are found.  A module is affected if an error is found in it, or one
+	// of its submodules, or if it imports an affected module.  An affected
+	// module is not augmented, deviated or checked further, and is
+	// omitted by the methods of Modules that walk the schema trees, such
+	// as Warnings.
+	ContinueOnError bool
 }
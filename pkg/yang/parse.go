@@ -42,6 +42,14 @@ type parser struct {
 	// hitBrace is updated with the file, line, and column of the brace's
 	// location.
 	hitBrace *Statement
+
+	// comments are the comments read since the last statement was
+	// started, if the lexer keeps comments.
+	comments []string
+
+	// source is the input being parsed if the text of each statement is
+	// to be kept, and "" otherwise.
+	source string
 }
 
 // Statement is a generic YANG statement that may have sub-statements.
@@ -57,9 +65,23 @@ type Statement struct {
 	Argument    string
 	statements  []*Statement
 
+	// Comments are the comments that precede the statement and
+	// EndComments those that follow its last substatement, before its
+	// closing brace.  Each includes its delimiters ("//" or "/*" and
+	// "*/").  They are only set if comments were kept when parsing (see
+	// Options.PreserveComments).
+	Comments    []string `json:",omitempty"`
+	EndComments []string `json:",omitempty"`
+
 	file string
 	line int // 1's based line number
 	col  int // 1's based column number
+
+	// raw is the text of the statement in its file and offset the byte
+	// offset of that text.  They are only set if the text was kept when
+	// parsing (see Options.KeepSource).
+	raw    string
+	offset int
 }
 
 func (s *Statement) NName() string         { return s.Argument }
@@ -72,6 +94,22 @@ func (s *Statement) Exts() []*Statement    { return nil }
 // argument.
 func (s *Statement) Arg() (string, bool) { return s.Argument, s.HasArgument }
 
+// Raw returns the text of s as it appears in its file, from the start of its
+// keyword through the ";" or "}" that ends it, including the text of its
+// substatements.  The comments preceding s are not included.  Raw returns ""
+// unless the text was kept when s was parsed (see Options.KeepSource).
+func (s *Statement) Raw() string { return s.raw }
+
+// Span returns the byte offsets in its file of the start and end of the
+// text returned by Raw, i.e., Raw is file[start:end].  Both are -1 if the
+// text was not kept.
+func (s *Statement) Span() (start, end int) {
+	if s.raw == "" {
+		return -1, -1
+	}
+	return s.offset, s.offset + len(s.raw)
+}
+
 // SubStatements returns a slice of Statements found in s.
 func (s *Statement) SubStatements() []*Statement { return s.statements }
 
@@ -92,7 +130,8 @@ func (s *Statement) Location() string {
 // Write writes the tree in s to w, each line indented by ident.  Children
 // nodes are indented further by a tab.  Typically indent is "" at the top
 // level.  Write is intended to display the contents of Statement, but
-// not necessarily reproduce the input of Statement.
+// not necessarily reproduce the input of Statement.  Any Comments and
+// EndComments are written on lines of their own.
 func (s *Statement) Write(w io.Writer, indent string) error {
 	if s.Keyword == "" {
 		// We are just a collection of statements at the top level.
@@ -103,6 +142,9 @@ func (s *Statement) Write(w io.Writer, indent string) error {
 		}
 		return nil
 	}
+	if err := writeComments(w, indent, s.Comments); err != nil {
+		return err
+	}
 
 	parts := []string{fmt.Sprintf("%s%s", indent, s.Keyword)}
 	if s.HasArgument {
@@ -126,7 +168,7 @@ func (s *Statement) Write(w io.Writer, indent string) error {
 		}
 	}
 
-	if len(s.statements) == 0 {
+	if len(s.statements) == 0 && len(s.EndComments) == 0 {
 		_, err := fmt.Fprintf(w, "%s;\n", strings.Join(parts, ""))
 		return err
 	}
@@ -138,12 +180,27 @@ func (s *Statement) Write(w io.Writer, indent string) error {
 			return err
 		}
 	}
+	if err := writeComments(w, indent+"\t", s.EndComments); err != nil {
+		return err
+	}
 	if _, err := fmt.Fprintf(w, "%s}\n", indent); err != nil {
 		return err
 	}
 	return nil
 }
 
+// writeComments writes each of comments to w on a line of its own, indented
+// by indent.  The lines following the first of a multi-line comment are
+// written as they were found.
+func writeComments(w io.Writer, indent string, comments []string) error {
+	for _, c := range comments {
+		if _, err := fmt.Fprintf(w, "%s%s\n", indent, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ignoreMe is an error recovery token used by the parser in order
 // to continue processing for other errors in the file.
 var ignoreMe = &Statement{}
@@ -152,8 +209,16 @@ var ignoreMe = &Statement{}
 // The path parameter should be the source name where input was read from (e.g.,
 // the file name the input was read from).  If one more more errors are
 // encountered, nil and an error are returned.  The error's text includes all
-// errors encountered.
+// errors encountered.  Comments and the text of the statements are discarded.
 func Parse(input, path string) ([]*Statement, error) {
+	return parse(input, path, Options{})
+}
+
+// parse is Parse, setting the Comments and EndComments of the statements
+// parsed if opts.PreserveComments is set and the text of the statements if
+// opts.KeepSource is set.  Comments following the last statement at the top
+// level are discarded.
+func parse(input, path string, opts Options) ([]*Statement, error) {
 	var statements []*Statement
 	p := &parser{
 		lex:      newLexer(input, path),
@@ -161,6 +226,10 @@ func Parse(input, path string) ([]*Statement, error) {
 		hitBrace: &Statement{},
 	}
 	p.lex.errout = p.errout
+	p.lex.keepComments = opts.PreserveComments
+	if opts.KeepSource {
+		p.source = input
+	}
 Loop:
 	for {
 		switch ns := p.nextStatement(); ns {
@@ -204,10 +273,15 @@ func (p *parser) next() *token {
 	if t := p.pop(); t != nil {
 		return t
 	}
-	// next returns the next unprocessed lexer token.
+	// next returns the next unprocessed lexer token, saving any comments
+	// found before it.
 	next := func() *token {
 		for {
-			if t := p.lex.NextToken(); t.Code() != tError {
+			switch t := p.lex.NextToken(); t.Code() {
+			case tError:
+			case tComment:
+				p.comments = append(p.comments, t.Text)
+			default:
 				return t
 			}
 		}
@@ -264,6 +338,7 @@ func (p *parser) nextStatement() *Statement {
 		p.hitBrace.file = t.File
 		p.hitBrace.line = t.Line
 		p.hitBrace.col = t.Col
+		p.hitBrace.offset = t.Pos
 		return p.hitBrace
 	case tUnquoted:
 	default:
@@ -273,10 +348,12 @@ func (p *parser) nextStatement() *Statement {
 	// Invariant: t represents a keyword token.
 
 	s := &Statement{
-		Keyword: t.Text,
-		file:    t.File,
-		line:    t.Line,
-		col:     t.Col,
+		Keyword:  t.Text,
+		Comments: p.takeComments(),
+		file:     t.File,
+		line:     t.Line,
+		col:      t.Col,
+		offset:   t.Pos,
 	}
 
 	// The keyword "pattern" must be treated specially. When
@@ -297,6 +374,7 @@ func (p *parser) nextStatement() *Statement {
 		fmt.Fprintf(p.errout, "%s: unexpected EOF\n", s.file)
 		return nil
 	case ';':
+		p.setRaw(s, t.Pos)
 		return s
 	case '{':
 		p.statementDepth += 1
@@ -306,6 +384,8 @@ func (p *parser) nextStatement() *Statement {
 				// Signal EOF reached.
 				return nil
 			case p.hitBrace:
+				s.EndComments = p.takeComments()
+				p.setRaw(s, p.hitBrace.offset)
 				return s
 			default:
 				s.statements = append(s.statements, ns)
@@ -317,6 +397,21 @@ func (p *parser) nextStatement() *Statement {
 	}
 }
 
+// setRaw sets the text of s, which ends with the ";" or "}" at offset end,
+// if the text of statements is being kept.
+func (p *parser) setRaw(s *Statement, end int) {
+	if p.source != "" {
+		s.raw = p.source[s.offset : end+1]
+	}
+}
+
+// takeComments returns the comments read since it was last called.
+func (p *parser) takeComments() []string {
+	c := p.comments
+	p.comments = nil
+	return c
+}
+
 // checkStatementDepthIsZero checks that we aren't missing closing
 // braces. Note: the parser will error out for the case where we
 // start with an unmatched close brace, i.e. depth < 0
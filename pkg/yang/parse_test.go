@@ -16,7 +16,10 @@ package yang
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func (s1 *Statement) equal(s2 *Statement) bool {
@@ -537,3 +540,144 @@ module base {
 		}
 	}
 }
+
+func TestParseComments(t *testing.T) {
+	in := `// Copyright notice.
+module base {
+	/* The namespace
+	   of the module. */
+	namespace "urn:mod"; // same line
+	prefix "base";
+
+	container c { // opens c
+		leaf l { type string; }
+		// end of c
+	}
+	container empty {
+		// nothing here
+	}
+}
+// trailing
+`
+	ss, err := parse(in, "test.yang", Options{PreserveComments: true})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	m := ss[0]
+	for _, tt := range []struct {
+		desc string
+		got  []string
+		want []string
+	}{
+		{"module", m.Comments, []string{"// Copyright notice."}},
+		{"namespace", m.statements[0].Comments, []string{"/* The namespace\n\t   of the module. */"}},
+		{"prefix", m.statements[1].Comments, []string{"// same line"}},
+		{"container c", m.statements[2].Comments, nil},
+		{"leaf l", m.statements[2].statements[0].Comments, []string{"// opens c"}},
+		{"end of container c", m.statements[2].EndComments, []string{"// end of c"}},
+		{"end of container empty", m.statements[3].EndComments, []string{"// nothing here"}},
+		{"end of module", m.EndComments, nil},
+	} {
+		if diff := cmp.Diff(tt.want, tt.got); diff != "" {
+			t.Errorf("%s: comments (-want, +got):\n%s", tt.desc, diff)
+		}
+	}
+
+	// Writing the statements, and parsing and writing them again, must
+	// not lose any comments.
+	var want bytes.Buffer
+	for _, s := range ss {
+		s.Write(&want, "")
+	}
+	ss, err = parse(want.String(), "test.yang", Options{PreserveComments: true})
+	if err != nil {
+		t.Fatalf("parse of written statements: %v", err)
+	}
+	var got bytes.Buffer
+	for _, s := range ss {
+		s.Write(&got, "")
+	}
+	if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+		t.Errorf("written statements (-want, +got):\n%s", diff)
+	}
+	if !strings.Contains(got.String(), "\tcontainer \"empty\" {\n\t\t// nothing here\n\t}\n") {
+		t.Errorf("container empty not written with its comment:\n%s", got.String())
+	}
+
+	ss, err = Parse(in, "test.yang")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if c := ss[0].Comments; c != nil {
+		t.Errorf("Parse kept comments %q, want none", c)
+	}
+
+	ms := NewModules()
+	ms.ParseOptions.PreserveComments = true
+	if err := ms.Parse(in, "test.yang"); err != nil {
+		t.Fatalf("Modules.Parse: %v", err)
+	}
+	if diff := cmp.Diff([]string{"// Copyright notice."}, ms.Modules["base"].Source.Comments); diff != "" {
+		t.Errorf("Modules.Parse with PreserveComments (-want, +got):\n%s", diff)
+	}
+}
+
+func TestParseKeepSource(t *testing.T) {
+	in := `// Copyright notice.
+module base {
+	namespace "urn:mod";
+	prefix "base";
+
+	container c {
+		description
+		  "multi-line " +
+		  "description";
+		leaf l { type string; }
+	}
+}`
+	ss, err := parse(in, "test.yang", Options{KeepSource: true})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	m := ss[0]
+	c := m.statements[2]
+	for _, tt := range []struct {
+		desc string
+		s    *Statement
+		want string
+	}{
+		{"module", m, in[strings.Index(in, "module"):]},
+		{"namespace", m.statements[0], `namespace "urn:mod";`},
+		{"container c", c, in[strings.Index(in, "container") : strings.LastIndex(in, "\t}")+2]},
+		{"description", c.statements[0], "description\n\t\t  \"multi-line \" +\n\t\t  \"description\";"},
+		{"leaf l", c.statements[1], "leaf l { type string; }"},
+		{"type", c.statements[1].statements[0], "type string;"},
+	} {
+		if got := tt.s.Raw(); got != tt.want {
+			t.Errorf("%s: got Raw %q, want %q", tt.desc, got, tt.want)
+		}
+		if start, end := tt.s.Span(); in[start:end] != tt.want {
+			t.Errorf("%s: got Span %d, %d (%q), want %q", tt.desc, start, end, in[start:end], tt.want)
+		}
+	}
+
+	ss, err = Parse(in, "test.yang")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if raw := ss[0].Raw(); raw != "" {
+		t.Errorf("Parse kept text %q, want none", raw)
+	}
+	if start, end := ss[0].Span(); start != -1 || end != -1 {
+		t.Errorf("Parse got Span %d, %d, want -1, -1", start, end)
+	}
+
+	ms := NewModules()
+	ms.ParseOptions.KeepSource = true
+	if err := ms.Parse(in, "test.yang"); err != nil {
+		t.Fatalf("Modules.Parse: %v", err)
+	}
+	if got, want := ms.Modules["base"].Container[0].Leaf[0].Statement().Raw(), "leaf l { type string; }"; got != want {
+		t.Errorf("Modules.Parse with KeepSource: got leaf text %q, want %q", got, want)
+	}
+}
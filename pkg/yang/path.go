@@ -0,0 +1,277 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the normalization of schema paths written in the
+// various styles in common use and their resolution to an Entry.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// NormalizePath returns the schema path p in a canonical form: absolute,
+// without module prefixes or list key predicates and without empty or
+// trailing elements.  For example, both "/oc-if:interfaces/interface[name=eth0]/"
+// and "interfaces/oc-if:interface" normalize to "/interfaces/interface".
+func NormalizePath(p string) string {
+	var parts []string
+	for _, part := range strings.Split(stripPredicates(p), "/") {
+		if _, name := getPrefix(strings.TrimSpace(part)); name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// ResolvePath returns the Entry named by the absolute schema path p.  p may
+// include list key predicates, which are ignored, and its elements may be
+// unprefixed or prefixed by either a module name, as in RFC7951 and gNMI
+// paths, or a module's prefix.  The first element of p may also be the name
+// of a module, as in the paths returned by Entry.Path, unless it is also the
+// name of a top level node, which takes precedence.  Choice and case
+// nodes may be included or omitted, and the input and output of RPCs and
+// actions are named "input" and "output".  An error is returned if p does not
+// name exactly one node.  ResolvePath must be called after a successful call
+// to Process.
+func (ms *Modules) ResolvePath(p string) (*Entry, error) {
+	var parts []string
+	for _, part := range strings.Split(stripPredicates(p), "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%s: empty path", p)
+	}
+
+	var matches []*Entry
+	prefix, name := getPrefix(parts[0])
+	for _, mname := range ms.moduleNames() {
+		m := ms.Modules[mname]
+		if prefix != "" && prefix != m.Name && prefix != m.GetPrefix() {
+			continue
+		}
+		if e := ToEntry(m).schemaChild(name); e != nil {
+			matches = append(matches, e)
+		}
+	}
+	if len(matches) == 0 && prefix == "" {
+		// The module name form used by Entry.Path.
+		if m := ms.Modules[name]; m != nil {
+			if len(parts) == 1 {
+				return ToEntry(m), nil
+			}
+			_, child := getPrefix(parts[1])
+			if e := ToEntry(m).schemaChild(child); e != nil {
+				matches = append(matches, e)
+				parts = parts[1:]
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%s: %s not found", p, parts[0])
+	case 1:
+	default:
+		var paths []string
+		for _, e := range matches {
+			paths = append(paths, e.Path())
+		}
+		return nil, fmt.Errorf("%s: %s is ambiguous, matching %s", p, parts[0], strings.Join(paths, ", "))
+	}
+
+	e := matches[0]
+	for _, part := range parts[1:] {
+		_, name := getPrefix(part)
+		next := e.schemaChild(name)
+		if next == nil {
+			return nil, fmt.Errorf("%s: %s not found in %s", p, part, e.Path())
+		}
+		e = next
+	}
+	return e, nil
+}
+
+// schemaChild returns the child of e named name.  The child may be a direct
+// child, or the input or output of an RPC, or a data node found through any
+// choice and case nodes.  nil is returned if there is no such child.
+func (e *Entry) schemaChild(name string) *Entry {
+	if c := e.Dir[name]; c != nil {
+		return c
+	}
+	return e.dataChild(name)
+}
+
+// LeafPaths returns the paths of all the leaf and leaf-list entries beneath
+// e, including those in the input and output of RPCs and actions.  The paths
+// have the form returned by Path except that choice and case nodes, which do
+// not appear in the data tree, are omitted.  Siblings are returned in the
+// order they were declared, i.e., ordered by the source location of the
+// statements that define them.
+func (e *Entry) LeafPaths() []string {
+	var paths []string
+	var walk func(*Entry)
+	walk = func(e *Entry) {
+		for _, c := range declaredChildren(e) {
+			if c.IsLeaf() || c.IsLeafList() {
+				paths = append(paths, c.dataPath())
+			}
+			walk(c)
+		}
+	}
+	walk(e)
+	return paths
+}
+
+// dataPath returns the path to e, as returned by Path, with any choice and
+// case nodes omitted.
+func (e *Entry) dataPath() string {
+	if e == nil {
+		return ""
+	}
+	if e.IsChoice() || e.IsCase() {
+		return e.Parent.dataPath()
+	}
+	return e.Parent.dataPath() + "/" + e.Name
+}
+
+// NearestList returns the closest ancestor of e that is a list, i.e., the list
+// whose keys select the instance of e in the data tree, or nil if e is not
+// beneath a list.  e itself is not considered.
+func (e *Entry) NearestList() *Entry {
+	for p := e.Parent; p != nil; p = p.Parent {
+		if p.IsList() {
+			return p
+		}
+	}
+	return nil
+}
+
+// declaredChildren returns the children of e, and the input and output of e
+// if it is an RPC or action, ordered by the source location of the statements
+// that define them.  A child instantiated from a grouping is ordered by the
+// location of the uses statement and then by its location in the grouping.
+// Children defined in different files are ordered by file name and children
+// without a location by name.
+func declaredChildren(e *Entry) []*Entry {
+	var children []*Entry
+	if e.RPC != nil {
+		for _, c := range []*Entry{e.RPC.Input, e.RPC.Output} {
+			if c != nil {
+				children = append(children, c)
+			}
+		}
+	}
+	for _, c := range e.Dir {
+		children = append(children, c)
+	}
+	positions := make(map[*Entry][]*Statement, len(children))
+	for _, c := range children {
+		var pos []*Statement
+		if e.Node != nil && c.Node != nil {
+			pos = declaredPosition(e.Node, c.Node)
+		}
+		if pos == nil {
+			pos = []*Statement{statementOf(c)}
+		}
+		positions[c] = pos
+	}
+	sort.Slice(children, func(i, j int) bool {
+		pi, pj := positions[children[i]], positions[children[j]]
+		for k := 0; k < len(pi) && k < len(pj); k++ {
+			a, b := pi[k], pj[k]
+			switch {
+			case a.file != b.file:
+				return a.file < b.file
+			case a.line != b.line:
+				return a.line < b.line
+			case a.col != b.col:
+				return a.col < b.col
+			}
+		}
+		if len(pi) != len(pj) {
+			return len(pi) < len(pj)
+		}
+		return children[i].Name < children[j].Name
+	})
+	return children
+}
+
+// declaredPosition returns the statements locating the definition of n within
+// that of parent: the uses statements that n is instantiated through, as
+// returned by usesPath, followed by the statement of n.  It returns nil if n
+// is not defined within parent.
+func declaredPosition(parent, n Node) []*Statement {
+	uses, ok := usesPath(parent, n)
+	if !ok {
+		return nil
+	}
+	var pos []*Statement
+	for _, u := range uses {
+		pos = append(pos, statementOrEmpty(u))
+	}
+	return append(pos, statementOrEmpty(n))
+}
+
+// usesPath returns the uses statements through which n is instantiated within
+// parent: a uses statement of parent whose grouping contains n, followed by
+// the uses statements through which n is instantiated within that grouping.
+// The path is empty if n is a substatement of parent.  ok is false if n is
+// not defined within parent.
+func usesPath(parent, n Node) (path []*Uses, ok bool) {
+	if n.ParentNode() == parent {
+		return nil, true
+	}
+	v := reflect.ValueOf(parent)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+	f := v.Elem().FieldByName("Uses")
+	if !f.IsValid() {
+		return nil, false
+	}
+	uses, _ := f.Interface().([]*Uses)
+	for _, u := range uses {
+		g := FindGrouping(u, u.Name, map[string]bool{})
+		if g == nil {
+			continue
+		}
+		if path, ok := usesPath(g, n); ok {
+			return append([]*Uses{u}, path...), true
+		}
+	}
+	return nil, false
+}
+
+// statementOf returns the statement defining e, or an empty statement if it
+// is not known.
+func statementOf(e *Entry) *Statement {
+	if e.Node != nil {
+		return statementOrEmpty(e.Node)
+	}
+	return &Statement{}
+}
+
+// statementOrEmpty returns the statement defining n, or an empty statement if
+// it is not known.
+func statementOrEmpty(n Node) *Statement {
+	if s := n.Statement(); s != nil {
+		return s
+	}
+	return &Statement{}
+}
@@ -0,0 +1,311 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/interfaces/interface", "/interfaces/interface"},
+		{"interfaces/interface/", "/interfaces/interface"},
+		{"/oc-if:interfaces/oc-if:interface[name=eth0]/config", "/interfaces/interface/config"},
+		{"/a[k1=x][k2=y]//b", "/a/b"},
+		{"/a[name='x/y']/b", "/a/b"},
+	}
+	for _, tt := range tests {
+		if got := NormalizePath(tt.in); got != tt.want {
+			t.Errorf("NormalizePath(%q): got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	ms := NewModules()
+	for name, text := range map[string]string{
+		"interfaces": `
+			module interfaces {
+				prefix "if";
+				namespace "urn:if";
+
+				container interfaces {
+					list interface {
+						key "name";
+						leaf name { type string; }
+						choice mode {
+							case routed {
+								leaf address { type string; }
+							}
+						}
+					}
+				}
+				rpc clear {
+					input { leaf name { type string; } }
+				}
+			}`,
+		"system": `
+			module system {
+				prefix "sys";
+				namespace "urn:sys";
+
+				import interfaces { prefix if; }
+
+				container system {
+					leaf hostname { type string; }
+				}
+				augment /if:interfaces/if:interface {
+					leaf mtu { type uint16; }
+				}
+			}`,
+		"other": `
+			module other {
+				prefix "o";
+				namespace "urn:o";
+
+				container system;
+			}`,
+	} {
+		if err := ms.Parse(text, name+".yang"); err != nil {
+			t.Fatalf("cannot parse module %s: %v", name, err)
+		}
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+
+	tests := []struct {
+		desc             string
+		in               string
+		want             string
+		wantErrSubstring string
+	}{{
+		desc: "unprefixed",
+		in:   "/interfaces/interface/name",
+		want: "/interfaces/interfaces/interface/name",
+	}, {
+		desc: "prefixed with module prefix and keyed",
+		in:   "/if:interfaces/if:interface[name=eth0]/if:name",
+		want: "/interfaces/interfaces/interface/name",
+	}, {
+		desc: "prefixed with module name",
+		in:   "/interfaces:interfaces/interface[name=eth0]/system:mtu",
+		want: "/interfaces/interfaces/interface/mtu",
+	}, {
+		desc: "through choice and case",
+		in:   "/interfaces/interface/address",
+		want: "/interfaces/interfaces/interface/mode/routed/address",
+	}, {
+		desc: "including choice and case",
+		in:   "/interfaces/interface/mode/routed/address",
+		want: "/interfaces/interfaces/interface/mode/routed/address",
+	}, {
+		desc: "rpc input",
+		in:   "/if:clear/input/name",
+		want: "/interfaces/clear/input/name",
+	}, {
+		desc: "module name form",
+		in:   "/other/system",
+		want: "/other/system",
+	}, {
+		desc: "top level node named like a module",
+		in:   "/interfaces/",
+		want: "/interfaces/interfaces",
+	}, {
+		desc: "module",
+		in:   "/other",
+		want: "/other",
+	}, {
+		desc: "disambiguated by prefix",
+		in:   "/sys:system/hostname",
+		want: "/system/system/hostname",
+	}, {
+		desc:             "ambiguous",
+		in:               "/system",
+		wantErrSubstring: "system is ambiguous, matching /other/system, /system/system",
+	}, {
+		desc:             "missing child",
+		in:               "/interfaces/interface/nope",
+		wantErrSubstring: "nope not found in /interfaces/interfaces/interface",
+	}, {
+		desc:             "missing top level node",
+		in:               "/nope",
+		wantErrSubstring: "nope not found",
+	}, {
+		desc:             "unknown prefix",
+		in:               "/x:interfaces",
+		wantErrSubstring: "x:interfaces not found",
+	}, {
+		desc:             "empty",
+		in:               "/",
+		wantErrSubstring: "empty path",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := ms.ResolvePath(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("ResolvePath(%q): %s", tt.in, diff)
+			}
+			if got.Path() != tt.want {
+				t.Errorf("ResolvePath(%q): got %s, want %s", tt.in, got.Path(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLeafPaths(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			grouping h {
+				leaf h1 { type string; }
+			}
+			grouping g {
+				leaf g1 { type string; }
+				container g2 {
+					leaf g3 { type string; }
+					uses h;
+				}
+			}
+
+			container system {
+				leaf hostname { type string; }
+				choice transport {
+					case tcp {
+						leaf port { type uint16; }
+					}
+					leaf-list udp-ports { type uint16; }
+				}
+				list server {
+					key "name";
+					leaf name { type string; }
+					container timers {
+						leaf retry { type uint8; }
+					}
+					leaf address { type string; }
+				}
+				leaf domain { type string; }
+			}
+			rpc reboot {
+				input { leaf delay { type uint32; } }
+				output { leaf status { type string; } }
+			}
+			leaf top { type string; }
+			container ordered {
+				leaf first { type string; }
+				uses g;
+				leaf last { type string; }
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	want := []string{
+		"/dev/system/hostname",
+		"/dev/system/port",
+		"/dev/system/udp-ports",
+		"/dev/system/server/name",
+		"/dev/system/server/timers/retry",
+		"/dev/system/server/address",
+		"/dev/system/domain",
+		"/dev/reboot/input/delay",
+		"/dev/reboot/output/status",
+		"/dev/top",
+		"/dev/ordered/first",
+		"/dev/ordered/g1",
+		"/dev/ordered/g2/g3",
+		"/dev/ordered/g2/h1",
+		"/dev/ordered/last",
+	}
+	if diff := cmp.Diff(want, mod.LeafPaths()); diff != "" {
+		t.Errorf("LeafPaths (-want, +got):\n%s", diff)
+	}
+
+	want = []string{
+		"/dev/system/server/name",
+		"/dev/system/server/timers/retry",
+		"/dev/system/server/address",
+	}
+	if diff := cmp.Diff(want, mod.Dir["system"].Dir["server"].LeafPaths()); diff != "" {
+		t.Errorf("LeafPaths of server (-want, +got):\n%s", diff)
+	}
+}
+
+func TestNearestList(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			list outer {
+				key "name";
+				leaf name { type string; }
+				container c {
+					list inner {
+						key "id";
+						leaf id { type uint8; }
+						choice ch { leaf x { type string; } }
+						action reset { input { leaf force { type boolean; } } }
+					}
+					leaf y { type string; }
+				}
+			}
+			container top { leaf z { type string; } }
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dev"])
+
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"outer", ""},
+		{"outer/name", "/dev/outer"},
+		{"outer/c/y", "/dev/outer"},
+		{"outer/c/inner", "/dev/outer"},
+		{"outer/c/inner/ch/x/x", "/dev/outer/c/inner"},
+		{"outer/c/inner/reset/input/force", "/dev/outer/c/inner"},
+		{"top/z", ""},
+	} {
+		e := mod.Find(tt.path)
+		if e == nil {
+			t.Fatalf("%s not found", tt.path)
+		}
+		if got := e.NearestList().Path(); got != tt.want {
+			t.Errorf("%s: NearestList: got %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
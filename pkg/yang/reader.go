@@ -0,0 +1,81 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the resolution of module and submodule names to their
+// source, either by searching Path or through a ModuleReader supplied by the
+// caller, e.g., one reading from a database or a version control system.
+
+// A ModuleReader returns the YANG source of modules and submodules.
+type ModuleReader interface {
+	// ReadModule returns the source of the module or submodule named
+	// name.  If revision is not "" the source of that revision is
+	// preferred, but the source of another revision may be returned if
+	// the requested one is not available.  ReadModule returns an error if
+	// there is no module or submodule named name.
+	ReadModule(name, revision string) ([]byte, error)
+}
+
+// NewPathReader returns a ModuleReader that searches for the files of modules
+// and submodules as Read does: in the current directory and then in the
+// directories and URLs of ms.Path.  A module is found in the file named by its
+// name and revision (e.g., foo@2021-03-01.yang), if there is one, and
+// otherwise in the file with its name or the latest name@revision-date.yang.
+// It is the reader ms uses when ms.Reader is nil, and may be used by other
+// ModuleReaders to fall back to searching the path.
+func NewPathReader(ms *Modules) ModuleReader {
+	return pathReader{ms}
+}
+
+// A pathReader is the ModuleReader returned by NewPathReader.
+type pathReader struct {
+	ms *Modules
+}
+
+// ReadModule implements ModuleReader.
+func (r pathReader) ReadModule(name, revision string) ([]byte, error) {
+	if revision != "" {
+		if _, data, err := r.ms.findFile(name + "@" + revision); err == nil {
+			return []byte(data), nil
+		}
+	}
+	_, data, err := r.ms.findFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// readModule reads the module or submodule named name, preferring revision if
+// it is not "", into ms using ms.Reader.  The source of a module read using
+// ms.Reader is reported as name.yang.  If ms.Reader is nil the path is
+// searched as by NewPathReader, and the source is the file found.
+func (ms *Modules) readModule(name, revision string) error {
+	if ms.Reader == nil {
+		// Try to read first a module by revision
+		if revision != "" {
+			if err := ms.Read(name + "@" + revision); err == nil {
+				return nil
+			}
+		}
+		// if failed, try to read a module by its bare name
+		return ms.Read(name)
+	}
+	data, err := ms.Reader.ReadModule(name, revision)
+	if err != nil {
+		return err
+	}
+	return ms.Parse(string(data), name+".yang")
+}
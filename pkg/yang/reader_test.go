@@ -0,0 +1,136 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mapReader is a ModuleReader of the modules in a map keyed by name or
+// name@revision.
+type mapReader struct {
+	modules map[string]string
+	reads   []string
+}
+
+func (r *mapReader) ReadModule(name, revision string) ([]byte, error) {
+	r.reads = append(r.reads, name+"@"+revision)
+	if revision != "" {
+		if m, ok := r.modules[name+"@"+revision]; ok {
+			return []byte(m), nil
+		}
+	}
+	if m, ok := r.modules[name]; ok {
+		return []byte(m), nil
+	}
+	return nil, fmt.Errorf("%s not in the database", name)
+}
+
+func TestModuleReader(t *testing.T) {
+	r := &mapReader{modules: map[string]string{
+		"top": `
+			module top {
+				prefix "t";
+				namespace "urn:t";
+				import dep { prefix d; revision-date 2021-02-01; }
+				include top-sub;
+				leaf a { type d:name; }
+			}`,
+		"top-sub": `
+			submodule top-sub {
+				belongs-to top { prefix t; }
+				leaf b { type string; }
+			}`,
+		"dep@2021-02-01": `
+			module dep {
+				prefix "d";
+				namespace "urn:d";
+				revision 2021-02-01;
+				typedef name { type string; }
+			}`,
+	}}
+	ms := NewModules()
+	ms.Reader = r
+	e, errs := ms.GetModule("top")
+	if len(errs) != 0 {
+		t.Fatalf("GetModule(top): %v", errs)
+	}
+	if e.Dir["a"] == nil || e.Dir["b"] == nil {
+		t.Errorf("top is missing leaves: got %v", e.Dir)
+	}
+	if diff := cmp.Diff([]string{"top@", "top-sub@", "dep@2021-02-01"}, r.reads); diff != "" {
+		t.Errorf("modules read (-want, +got):\n%s", diff)
+	}
+	if got, want := ms.Modules["dep"].Source.Location(), "dep.yang:2:4"; got != want {
+		t.Errorf("dep location: got %q, want %q", got, want)
+	}
+
+	ms = NewModules()
+	ms.Reader = r
+	if err := ms.Parse(`module bad { prefix "b"; namespace "urn:b"; import missing { prefix m; } }`, "bad.yang"); err != nil {
+		t.Fatal(err)
+	}
+	errs = ms.Process()
+	if len(errs) != 1 || errs[0].Error() != "no such module: missing" {
+		t.Errorf("Process with a missing import: got %v, want [no such module: missing]", errs)
+	}
+}
+
+func TestPathReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goyang-path-reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, text := range map[string]string{
+		"a.yang":            "module a { prefix a; namespace urn:a; }",
+		"b@2020-01-01.yang": "module b { prefix b; namespace urn:b; revision 2020-01-01; }",
+		"b@2021-01-01.yang": "module b { prefix b; namespace urn:b; revision 2021-01-01; }",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ms := NewModules()
+	ms.AddPath(dir)
+	r := NewPathReader(ms)
+
+	for _, tt := range []struct {
+		name, revision string
+		want           string
+		wantErr        bool
+	}{
+		{name: "a", want: "module a { prefix a; namespace urn:a; }"},
+		{name: "a", revision: "2020-01-01", want: "module a { prefix a; namespace urn:a; }"},
+		{name: "b", want: "module b { prefix b; namespace urn:b; revision 2021-01-01; }"},
+		{name: "b", revision: "2020-01-01", want: "module b { prefix b; namespace urn:b; revision 2020-01-01; }"},
+		{name: "c", wantErr: true},
+	} {
+		got, err := r.ReadModule(tt.name, tt.revision)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ReadModule(%q, %q): got error %v, want error %v", tt.name, tt.revision, err, tt.wantErr)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("ReadModule(%q, %q): got %q, want %q", tt.name, tt.revision, got, tt.want)
+		}
+	}
+}
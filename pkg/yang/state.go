@@ -0,0 +1,76 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the separation of a schema tree into its
+// configuration and its state (config false) data.
+
+import "strings"
+
+// StateOnly returns a copy of e pruned to the state data beneath it, i.e.,
+// the nodes for which ReadOnly returns true and the ancestors needed to
+// reach them.  The keys of a list that is kept only because it has state
+// descendants are also kept, as the entries of the list cannot be
+// identified without them.  RPCs, actions and notifications are omitted,
+// as are choice and case nodes with no remaining children.  The Parent of
+// the copy is that of e, so Path and ReadOnly return the same values for
+// the copies as for the originals, but the copy is not added to the Dir of
+// its parent.  nil is returned if there is no state data beneath e.
+func (e *Entry) StateOnly() *Entry {
+	return e.filterConfig(true)
+}
+
+// ConfigOnly returns a copy of e pruned to the configuration data beneath
+// it, i.e., the nodes for which ReadOnly returns false.  RPCs, actions and
+// notifications are omitted, as are choice and case nodes with no remaining
+// children.  The Parent of the copy is that of e, as with StateOnly.  nil is
+// returned if e is not configuration.
+func (e *Entry) ConfigOnly() *Entry {
+	return e.filterConfig(false)
+}
+
+// filterConfig returns a copy of e with only the nodes for which ReadOnly
+// returns state and their ancestors, or nil if there are none.
+func (e *Entry) filterConfig(state bool) *Entry {
+	if e.RPC != nil || e.Kind == NotificationEntry {
+		return nil
+	}
+	ne := *e
+	if e.Dir != nil {
+		ne.Dir = map[string]*Entry{}
+	}
+	for name, c := range e.Dir {
+		if fc := c.filterConfig(state); fc != nil {
+			fc.Parent = &ne
+			ne.Dir[name] = fc
+		}
+	}
+
+	switch {
+	case len(ne.Dir) > 0:
+	case e.IsChoice(), e.IsCase(), e.ReadOnly() != state:
+		return nil
+	}
+	if e.ReadOnly() != state && e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			if kc := e.Dir[k]; kc != nil && ne.Dir[k] == nil {
+				kc = kc.dup()
+				kc.Parent = &ne
+				ne.Dir[k] = kc
+			}
+		}
+	}
+	return &ne
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStateOnlyConfigOnly(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			container interfaces {
+				list interface {
+					key "name";
+					leaf name { type string; }
+					leaf mtu { type uint16; }
+					container state {
+						config false;
+						leaf counter { type uint64; }
+						leaf-list addr { type string; }
+					}
+					action reset;
+				}
+			}
+			container system {
+				leaf hostname { type string; }
+				choice mode {
+					leaf auto { type empty; }
+					case manual {
+						leaf detected {
+							config false;
+							type string;
+						}
+					}
+				}
+			}
+			container config-only {
+				leaf a { type string; }
+			}
+			leaf uptime {
+				config false;
+				type uint32;
+			}
+			rpc reboot {
+				input { leaf delay { type uint32; } }
+			}
+			notification restarted {
+				leaf reason { type string; }
+			}
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["dev"])
+
+	state := e.StateOnly()
+	if diff := cmp.Diff([]string{
+		"/dev/interfaces/interface/name",
+		"/dev/interfaces/interface/state/counter",
+		"/dev/interfaces/interface/state/addr",
+		"/dev/system/detected",
+		"/dev/uptime",
+	}, state.LeafPaths()); diff != "" {
+		t.Errorf("StateOnly (-want, +got):\n%s", diff)
+	}
+	if state.Dir["system"].Dir["mode"].Dir["auto"] != nil {
+		t.Errorf("StateOnly kept the configuration case auto")
+	}
+
+	config := e.ConfigOnly()
+	if diff := cmp.Diff([]string{
+		"/dev/interfaces/interface/name",
+		"/dev/interfaces/interface/mtu",
+		"/dev/system/hostname",
+		"/dev/system/auto",
+		"/dev/config-only/a",
+	}, config.LeafPaths()); diff != "" {
+		t.Errorf("ConfigOnly (-want, +got):\n%s", diff)
+	}
+	if config.Dir["system"].Dir["mode"].Dir["manual"] != nil {
+		t.Errorf("ConfigOnly kept the state only case manual")
+	}
+
+	// The original must not be changed.
+	if got := len(e.LeafPaths()); got != 11 {
+		t.Errorf("original has %d leaves after pruning, want 11", got)
+	}
+
+	for _, tt := range []struct {
+		desc string
+		got  *Entry
+	}{
+		{"StateOnly of a configuration subtree", e.Dir["config-only"].StateOnly()},
+		{"ConfigOnly of a state leaf", e.Dir["uptime"].ConfigOnly()},
+		{"StateOnly of an rpc", e.Dir["reboot"].StateOnly()},
+	} {
+		if tt.got != nil {
+			t.Errorf("%s: got %s, want nil", tt.desc, tt.got.Path())
+		}
+	}
+
+	if got := e.Dir["system"].StateOnly(); got.Parent != e || got.Path() != "/dev/system" {
+		t.Errorf("StateOnly of /dev/system: got parent %v and path %s, want the module and /dev/system", got.Parent, got.Path())
+	}
+}
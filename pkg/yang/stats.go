@@ -0,0 +1,97 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the computation of size and complexity metrics of an
+// Entry tree.
+
+// EntryStats holds the number of nodes of each kind beneath an Entry.
+type EntryStats struct {
+	Containers    int
+	Lists         int
+	Leaves        int
+	LeafLists     int
+	Choices       int
+	Cases         int
+	AnyData       int // anydata and anyxml nodes
+	RPCs          int // RPCs and actions
+	Notifications int
+	// Nodes is the total number of nodes, of all kinds, including the
+	// input and output of RPCs and actions.
+	Nodes int
+	// MaxDepth is the largest number of data tree levels beneath the
+	// Entry.  Choice and case nodes do not appear in the data tree and so
+	// do not add a level, while the input and output of an operation do.
+	MaxDepth int
+}
+
+// Stats returns the number of nodes of each kind beneath e, including those in
+// RPCs, actions and notifications, and the depth of the tree beneath e.  e
+// itself is not counted.
+func (e *Entry) Stats() EntryStats {
+	var s EntryStats
+	s.MaxDepth = e.addStats(&s, 0)
+	return s
+}
+
+// addStats adds the nodes beneath e to s.  depth is the data tree depth of e
+// beneath the Entry Stats was called on.  The largest depth of the nodes
+// beneath e is returned.
+func (e *Entry) addStats(s *EntryStats, depth int) int {
+	max := depth
+	note := func(d int) {
+		if d > max {
+			max = d
+		}
+	}
+	for _, c := range e.Dir {
+		s.Nodes++
+		d := depth + 1
+		switch {
+		case c.IsChoice():
+			s.Choices++
+			d = depth
+		case c.IsCase():
+			s.Cases++
+			d = depth
+		case c.RPC != nil:
+			s.RPCs++
+		case c.Kind == NotificationEntry:
+			s.Notifications++
+		case c.IsList():
+			s.Lists++
+		case c.IsLeafList():
+			s.LeafLists++
+		case c.IsLeaf():
+			s.Leaves++
+		case c.Kind == AnyDataEntry, c.Kind == AnyXMLEntry:
+			s.AnyData++
+		case c.IsContainer():
+			s.Containers++
+		}
+		note(d)
+		note(c.addStats(s, d))
+		if c.RPC != nil {
+			for _, io := range []*Entry{c.RPC.Input, c.RPC.Output} {
+				if io != nil {
+					s.Nodes++
+					note(d + 1)
+					note(io.addStats(s, d+1))
+				}
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStats(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module s {
+			yang-version 1.1;
+			prefix "s";
+			namespace "urn:s";
+
+			container top {
+				leaf a { type string; }
+				leaf-list b { type string; }
+				list l {
+					key k;
+					leaf k { type string; }
+					choice ch {
+						case one {
+							container deep {
+								leaf d { type string; }
+							}
+						}
+						leaf two { type string; }
+					}
+					action reset {
+						input { leaf force { type boolean; } }
+					}
+				}
+				anydata blob;
+			}
+			rpc ping {
+				output { leaf ok { type boolean; } }
+			}
+			notification alarm {
+				leaf text { type string; }
+			}
+		}`, "s.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+
+	want := EntryStats{
+		Containers:    2, // top and deep
+		Lists:         1,
+		Leaves:        7, // a, k, d, two, force, ok and text
+		LeafLists:     1,
+		Choices:       1,
+		Cases:         2,
+		AnyData:       1,
+		RPCs:          2, // reset and ping
+		Notifications: 1,
+		// The above, the input of reset and the output of ping.
+		Nodes: 20,
+		// top/l/deep/d and top/l/reset/input/force.
+		MaxDepth: 5,
+	}
+	if diff := cmp.Diff(want, ToEntry(ms.Modules["s"]).Stats()); diff != "" {
+		t.Errorf("Stats (-want, +got):\n%s", diff)
+	}
+
+	leaf := ToEntry(ms.Modules["s"]).Dir["top"].Dir["a"]
+	if diff := cmp.Diff(EntryStats{}, leaf.Stats()); diff != "" {
+		t.Errorf("Stats of a leaf (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the checks made by Process when Options.Strict is set.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// extensionErrors returns an error for each extension statement in the
+// modules and submodules of ms that is not backed by an extension definition
+// in the module its prefix refers to.
+func (ms *Modules) extensionErrors() []error {
+	var errs []error
+	mods := append(sortedModules(ms.Modules), sortedModules(ms.SubModules)...)
+	for _, m := range mods {
+		errs = appendExtensionErrors(errs, m, m.Source)
+	}
+	return errs
+}
+
+// appendExtensionErrors appends an error to errs for each undefined extension
+// statement found in s and its substatements, resolving prefixes relative to
+// the module or submodule m, and returns the result.
+func appendExtensionErrors(errs []error, m *Module, s *Statement) []error {
+	if s == nil {
+		return errs
+	}
+	if parts := strings.Split(s.Keyword, ":"); len(parts) == 2 {
+		switch em := FindModuleByPrefix(m, parts[0]); {
+		case em == nil:
+			errs = append(errs, fmt.Errorf("%s: unknown prefix %q in extension statement %s", s.Location(), parts[0], s.Keyword))
+		case !hasExtension(em, parts[1], map[*Module]bool{}):
+			errs = append(errs, fmt.Errorf("%s: extension %s is not defined in module %s", s.Location(), s.Keyword, em.Name))
+		}
+	}
+	for _, ss := range s.SubStatements() {
+		errs = appendExtensionErrors(errs, m, ss)
+	}
+	return errs
+}
+
+// hasExtension reports whether the extension name is defined by the module m
+// or one of the submodules it includes.  seen holds the modules already
+// searched so circular includes are not followed forever.
+func hasExtension(m *Module, name string, seen map[*Module]bool) bool {
+	if seen[m] {
+		return false
+	}
+	seen[m] = true
+	for _, e := range m.Extension {
+		if e.Name == name {
+			return true
+		}
+	}
+	for _, i := range m.Include {
+		if i.Module != nil && hasExtension(i.Module, name, seen) {
+			return true
+		}
+	}
+	return false
+}
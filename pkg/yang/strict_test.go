@@ -0,0 +1,142 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestStrict(t *testing.T) {
+	const ext = `
+		module ext {
+			prefix "x";
+			namespace "urn:x";
+
+			include ext-sub;
+
+			extension known { argument value; }
+		}`
+	const extSub = `
+		submodule ext-sub {
+			belongs-to ext { prefix "x"; }
+
+			extension sub-known;
+		}`
+
+	tests := []struct {
+		desc             string
+		inModule         string
+		inStrict         bool
+		wantErrSubstring []string
+	}{{
+		desc: "defined extensions",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				import ext { prefix x; }
+
+				extension local;
+
+				leaf a {
+					type string;
+					x:known "value" {
+						x:sub-known;
+					}
+					d:local;
+				}
+			}`,
+		inStrict: true,
+	}, {
+		desc: "undefined extensions are tolerated by default",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				import ext { prefix x; }
+
+				leaf a {
+					type string;
+					x:unknown;
+					y:other;
+				}
+			}`,
+	}, {
+		desc: "undefined extensions in strict mode",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				import ext { prefix x; }
+
+				leaf a {
+					type string;
+					x:known "value" {
+						x:unknown;
+					}
+					y:other;
+				}
+				d:local;
+			}`,
+		inStrict: true,
+		wantErrSubstring: []string{
+			"extension x:unknown is not defined in module ext",
+			`unknown prefix "y" in extension statement y:other`,
+			"extension d:local is not defined in module dev",
+		},
+	}, {
+		desc: "unknown statement without a prefix",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf a {
+					type string;
+					descripton "typo";
+				}
+			}`,
+		wantErrSubstring: []string{"unknown leaf field: descripton"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			ms.ParseOptions.Strict = tt.inStrict
+			var errs []error
+			for n, m := range map[string]string{"ext": ext, "ext-sub": extSub, "dev": tt.inModule} {
+				if err := ms.Parse(m, n+".yang"); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if len(errs) == 0 {
+				errs = ms.Process()
+			}
+			if len(errs) != len(tt.wantErrSubstring) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.wantErrSubstring), errs)
+			}
+			for i, err := range errs {
+				if diff := errdiff.Substring(err, tt.wantErrSubstring[i]); diff != "" {
+					t.Errorf("%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -100,15 +100,17 @@ func (d *typeDictionary) addTypedefs(t Typedefer) {
 
 // resolveTypedefs is called after all of modules and submodules have been read,
 // as well as their imports and includes.  It resolves all typedefs found in all
-// modules and submodules read in.
-func (d *typeDictionary) resolveTypedefs() []error {
+// modules and submodules read in, noting the errors found in each in ms.
+func (d *typeDictionary) resolveTypedefs(ms *Modules) []error {
 	var errs []error
 
 	// When resolve typedefs, we may need to look up other typedefs.
 	// We gather all typedefs into a slice so we don't deadlock on
 	// typeDict.
 	for _, td := range d.typedefs() {
-		errs = append(errs, td.resolve(d)...)
+		tdErrs := td.resolve(d)
+		ms.noteErrors(td, tdErrs...)
+		errs = append(errs, tdErrs...)
 	}
 	return errs
 }
@@ -157,6 +159,34 @@ func (t *Typedef) resolve(d *typeDictionary) []error {
 	return nil
 }
 
+// typedefScope returns the modules and submodules, other than root itself,
+// whose top level typedefs are visible within root without an import: the
+// submodules root includes, directly or through other submodules, and, if
+// root is a submodule, the module it belongs to and all of that module's
+// submodules.
+func typedefScope(root *Module) []*Module {
+	seen := map[*Module]bool{root: true}
+	var scope []*Module
+	var add func(m *Module)
+	add = func(m *Module) {
+		if m == nil || seen[m] {
+			return
+		}
+		seen[m] = true
+		scope = append(scope, m)
+		for _, in := range m.Include {
+			add(in.Module)
+		}
+	}
+	for _, in := range root.Include {
+		add(in.Module)
+	}
+	if root.Kind() == "submodule" && root.BelongsTo != nil && root.Modules != nil {
+		add(root.Modules.Modules[root.BelongsTo.Name])
+	}
+	return scope
+}
+
 // resolve resolves Type t, as well as the underlying typedef for t.  If t
 // cannot be resolved then one or more errors are returned.
 func (t *Type) resolve(d *typeDictionary) (errs []error) {
@@ -187,18 +217,19 @@ check:
 				break check
 			}
 		}
-		// We need to check our sub-modules as well
-		for _, in := range root.Include {
-			if td = d.find(in.Module, name); td != nil {
+		// We need to check our sub-modules as well, and the module
+		// we belong to if we are a sub-module.
+		for _, m := range typedefScope(root) {
+			if td = d.find(m, name); td != nil {
 				break check
 			}
 		}
 		var pname string
 		switch {
-		case prefix == "", prefix == root.Prefix.Name:
-			pname = root.Prefix.Name + ":" + t.Name
+		case prefix == "", prefix == rootPrefix:
+			pname = rootPrefix + ":" + t.Name
 		default:
-			pname = fmt.Sprintf("%s[%s]:%s", prefix, root.Prefix.Name, t.Name)
+			pname = fmt.Sprintf("%s[%s]:%s", prefix, rootPrefix, t.Name)
 		}
 
 		return []error{fmt.Errorf("%s: unknown type: %s", Source(t), pname)}
@@ -288,7 +319,7 @@ check:
 	}
 
 	if t.Range != nil {
-		yr, err := y.Range.parseChildRanges(t.Range.Name, isDecimal64, uint8(y.FractionDigits))
+		yr, err := y.Range.parseRangeStatement(t.Range.Name, isDecimal64, uint8(y.FractionDigits))
 		switch {
 		case err != nil:
 			errs = append(errs, fmt.Errorf("%s: bad range: %v", Source(t.Range), err))
@@ -303,7 +334,7 @@ check:
 		if y.Length != nil {
 			parentRange = y.Length
 		}
-		yr, err := parentRange.parseChildRanges(t.Length.Name, false, 0)
+		yr, err := parentRange.parseRangeStatement(t.Length.Name, false, 0)
 		switch {
 		case err != nil:
 			errs = append(errs, fmt.Errorf("%s: bad length: %v", Source(t.Length), err))
@@ -340,7 +371,9 @@ check:
 			if err := set(enum, e.Name, e.Value); err != nil {
 				errs = append(errs, fmt.Errorf("%s: %v", Source(e), err))
 			}
+			enum.setDescription(e.Name, e.Description)
 		}
+		enum.index()
 		y.Enum = enum
 	}
 
@@ -350,17 +383,33 @@ check:
 			if err := set(bit, e.Name, e.Position); err != nil {
 				errs = append(errs, fmt.Errorf("%s: %v", Source(e), err))
 			}
+			bit.setDescription(e.Name, e.Description)
 		}
+		bit.index()
 		y.Bit = bit
 	}
 
+	// The built-in enumeration, bits and union types must be restricted by
+	// at least one enum, bit or type statement, respectively, see RFC7950
+	// sections 9.6.4, 9.7.4 and 9.12.
+	if source == "builtin" {
+		switch {
+		case y.Kind == Yenum && len(t.Enum) == 0:
+			errs = append(errs, fmt.Errorf("%s: enumeration type has no enum statements", Source(t)))
+		case y.Kind == Ybits && len(t.Bit) == 0:
+			errs = append(errs, fmt.Errorf("%s: bits type has no bit statements", Source(t)))
+		case y.Kind == Yunion && len(t.Type) == 0:
+			errs = append(errs, fmt.Errorf("%s: union type has no member types", Source(t)))
+		}
+	}
+
 	// Append any newly found patterns to the end of the list of patterns.
 	// Patterns are ANDed according to section 9.4.6.  If all the patterns
 	// declared by t were also declared by the type t is based on, then
 	// no patterns are added.
-	seenPatterns := map[string]bool{}
+	seenPatterns := map[YangPattern]bool{}
 	for _, p := range y.Pattern {
-		seenPatterns[p] = true
+		seenPatterns[*p] = true
 	}
 	seenPOSIXPatterns := map[string]bool{}
 	for _, p := range y.POSIXPattern {
@@ -370,9 +419,21 @@ check:
 	// First parse out the pattern statements.
 	// These patterns are not checked because there is no support for W3C regexes by Go.
 	for _, pv := range t.Pattern {
-		if !seenPatterns[pv.Name] {
-			seenPatterns[pv.Name] = true
-			y.Pattern = append(y.Pattern, pv.Name)
+		p := YangPattern{
+			Expr:         pv.Name,
+			ErrorMessage: pv.ErrorMessage.asString(),
+			ErrorAppTag:  pv.ErrorAppTag.asString(),
+			Description:  pv.Description.asString(),
+		}
+		if pv.Modifier != nil {
+			if pv.Modifier.Name != "invert-match" {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern modifier %q, must be invert-match", Source(pv.Modifier), pv.Modifier.Name))
+			}
+			p.InvertMatch = true
+		}
+		if !seenPatterns[p] {
+			seenPatterns[p] = true
+			y.Pattern = append(y.Pattern, &p)
 		}
 	}
 
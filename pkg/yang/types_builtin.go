@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -243,10 +244,8 @@ func (r YangRange) Validate() error {
 	case !r[0].Valid():
 		return errors.New("invalid number")
 	}
-	p := r[0]
-
-	for _, n := range r[1:] {
-		if n.Min.Less(p.Max) {
+	for i := 1; i < len(r); i++ {
+		if !r[i-1].Max.Less(r[i].Min) {
 			return errors.New("overlapping ranges")
 		}
 	}
@@ -297,6 +296,158 @@ func (r YangRange) Contains(s YangRange) bool {
 	return true
 }
 
+// Union returns the values that are in either r or s, sorted and coalesced.
+// As for Contains, an empty range has every value, so the union of an empty
+// range with any other is empty.  Neither r nor s is changed.
+func (r YangRange) Union(s YangRange) YangRange {
+	if len(r) == 0 || len(s) == 0 {
+		return nil
+	}
+	u := append(append(YangRange{}, r...), s...)
+	u.Sort()
+	return coalesce(u)
+}
+
+// Intersect returns the values that are in both r and s, sorted and
+// coalesced.  As for Contains, an empty range has every value, so the
+// intersection of an empty range with another is the other.  An error is
+// returned if r and s have no values in common, or if one is a decimal64
+// range and the other is not.  Neither r nor s is changed.
+func (r YangRange) Intersect(s YangRange) (YangRange, error) {
+	switch {
+	case len(r) == 0:
+		return append(YangRange{}, s...), nil
+	case len(s) == 0:
+		return append(YangRange{}, r...), nil
+	case r[0].Min.IsDecimal() != s[0].Min.IsDecimal():
+		return nil, fmt.Errorf("cannot intersect %v and %v: mix of integer and decimal ranges", r, s)
+	}
+	a := append(YangRange{}, r...)
+	b := append(YangRange{}, s...)
+	a.Sort()
+	b.Sort()
+	a, b = coalesce(a), coalesce(b)
+
+	var out YangRange
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		min, max := a[i].Min, a[i].Max
+		if min.Less(b[j].Min) {
+			min = b[j].Min
+		}
+		if b[j].Max.Less(max) {
+			max = b[j].Max
+		}
+		if !max.Less(min) {
+			out = append(out, YRange{min, max})
+		}
+		// Advance past whichever range ends first.
+		if a[i].Max.Less(b[j].Max) {
+			i++
+		} else {
+			j++
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%v and %v have no values in common", r, s)
+	}
+	return coalesce(out), nil
+}
+
+// bounds returns the smallest and largest values in r, considering all of its
+// sub-ranges.  ok is false if r is empty.
+func (r YangRange) bounds() (min, max Number, ok bool) {
+	if len(r) == 0 {
+		return Number{}, Number{}, false
+	}
+	min, max = r[0].Min, r[0].Max
+	for _, yr := range r[1:] {
+		if yr.Min.Less(min) {
+			min = yr.Min
+		}
+		if max.Less(yr.Max) {
+			max = yr.Max
+		}
+	}
+	return min, max, true
+}
+
+// Min returns the overall minimum of the integer range r across all of its
+// sub-ranges.  false is returned if r is empty, is a decimal range, or its
+// minimum does not fit in an int64.
+func (r YangRange) Min() (int64, bool) {
+	min, _, ok := r.bounds()
+	if !ok || min.IsDecimal() {
+		return 0, false
+	}
+	i, err := min.Int()
+	return i, err == nil
+}
+
+// Max returns the overall maximum of the integer range r across all of its
+// sub-ranges.  false is returned if r is empty, is a decimal range, or its
+// maximum does not fit in an int64 (e.g., the range of a uint64).
+func (r YangRange) Max() (int64, bool) {
+	_, max, ok := r.bounds()
+	if !ok || max.IsDecimal() {
+		return 0, false
+	}
+	i, err := max.Int()
+	return i, err == nil
+}
+
+// UintMin returns the overall minimum of the unsigned integer range r across
+// all of its sub-ranges.  false is returned if r is empty, is a decimal range,
+// or its minimum is negative.
+func (r YangRange) UintMin() (uint64, bool) {
+	min, _, ok := r.bounds()
+	if !ok || min.IsDecimal() || (min.Negative && min.Value != 0) {
+		return 0, false
+	}
+	return min.Value, true
+}
+
+// UintMax returns the overall maximum of the unsigned integer range r across
+// all of its sub-ranges.  false is returned if r is empty, is a decimal range,
+// or its maximum is negative.
+func (r YangRange) UintMax() (uint64, bool) {
+	_, max, ok := r.bounds()
+	if !ok || max.IsDecimal() || (max.Negative && max.Value != 0) {
+		return 0, false
+	}
+	return max.Value, true
+}
+
+// DecimalMin returns the overall minimum of r across all of its sub-ranges as
+// a float64.  It is intended for decimal64 ranges, but may be used with
+// integer ranges, in which case precision may be lost for large values.  false
+// is returned if r is empty.
+func (r YangRange) DecimalMin() (float64, bool) {
+	min, _, ok := r.bounds()
+	if !ok {
+		return 0, false
+	}
+	return min.float(), true
+}
+
+// DecimalMax returns the overall maximum of r across all of its sub-ranges as
+// a float64.  It is intended for decimal64 ranges, but may be used with
+// integer ranges, in which case precision may be lost for large values.  false
+// is returned if r is empty.
+func (r YangRange) DecimalMax() (float64, bool) {
+	_, max, ok := r.bounds()
+	if !ok {
+		return 0, false
+	}
+	return max.float(), true
+}
+
+// float returns n as the nearest float64.
+func (n Number) float() float64 {
+	// String produces a valid decimal so ParseFloat cannot fail.
+	f, _ := strconv.ParseFloat(n.String(), 64)
+	return f
+}
+
 // FromInt creates a Number from an int64.
 func FromInt(i int64) Number {
 	if i < 0 {
@@ -439,6 +590,23 @@ func ParseRangesDecimal(s string, fracDigRequired uint8) (YangRange, error) {
 	return YangRange{}.parseChildRanges(s, true, fracDigRequired)
 }
 
+// ParseRestriction parses s, the argument of a range or length statement that
+// restricts a type whose range is r, into a series of ranges.  The min and
+// max keywords in s refer to the lowest and highest values in r, e.g.,
+// Uint64Range.ParseRestriction("8..max") is 8..18446744073709551615.  An
+// error is returned if s is invalid or is not within r.  Values in s are
+// parsed as decimal64 numbers with the fraction digits of r if r is a
+// decimal64 range.  The output range is sorted and coalesced.
+func (r YangRange) ParseRestriction(s string) (YangRange, error) {
+	if len(r) == 0 {
+		return nil, errors.New("cannot restrict an empty YangRange")
+	}
+	if r[0].Min.IsDecimal() {
+		return r.parseChildRanges(s, true, r[0].Min.FractionDigits)
+	}
+	return r.parseChildRanges(s, false, 0)
+}
+
 // parseChildRanges parses a child ranges statement 's' into a series of ranges
 // based on an already-parsed parent YangRange. Each individual range is in s
 // is separated by the pipe character (|). The min and max value of a range are
@@ -447,6 +615,52 @@ func ParseRangesDecimal(s string, fracDigRequired uint8) (YangRange, error) {
 // (rfc7950#section-9.2.5). The output range is sorted and coalesced.
 // fracDigRequired is ignored when decimal=false.
 func (y YangRange) parseChildRanges(s string, decimal bool, fracDigRequired uint8) (YangRange, error) {
+	r, err := y.parseRangeParts(s, decimal, fracDigRequired)
+	if err != nil {
+		return nil, err
+	}
+	return y.restrict(r, s)
+}
+
+// rangeLiteral matches the integer and decimal numbers allowed as range and
+// length boundaries (rfc7950#section-14).
+var rangeLiteral = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// parseRangeStatement is parseChildRanges for the argument s of a range or
+// length statement, which must also list its parts in ascending order
+// without overlaps (rfc7950#section-9.2.4).
+func (y YangRange) parseRangeStatement(s string, decimal bool, fracDigRequired uint8) (YangRange, error) {
+	r, err := y.parseRangeParts(s, decimal, fracDigRequired)
+	if err != nil {
+		return nil, err
+	}
+	// strconv also accepts signs, prefixes and leading zeros YANG does
+	// not, and only the parsed value of each literal is retained.
+	for _, part := range strings.Split(s, "|") {
+		for _, v := range strings.Split(part, "..") {
+			v = strings.TrimSpace(v)
+			if v != "min" && v != "max" && !rangeLiteral.MatchString(v) {
+				return nil, fmt.Errorf("invalid number %q in range part %s", v, strings.TrimSpace(part))
+			}
+		}
+	}
+	for i := 1; i < len(r); i++ {
+		p, n := r[i-1], r[i]
+		switch {
+		case p.Max.Less(n.Min):
+		case n.Max.Less(p.Min):
+			return nil, fmt.Errorf("range parts out of order (%s before %s): %s", p, n, s)
+		default:
+			return nil, fmt.Errorf("overlapping range parts (%s and %s): %s", p, n, s)
+		}
+	}
+	return y.restrict(r, s)
+}
+
+// parseRangeParts parses s into the ranges separated by pipes in s, in the
+// order they appear in s.  An error is returned if a range is invalid.  The
+// min and max keywords refer to the lowest and highest values in y.
+func (y YangRange) parseRangeParts(s string, decimal bool, fracDigRequired uint8) (YangRange, error) {
 	parseNumber := func(s string) (Number, error) {
 		switch {
 		case s == "max":
@@ -487,13 +701,19 @@ func (y YangRange) parseChildRanges(s string, decimal bool, fracDigRequired uint
 				return nil, err
 			}
 		default:
-			return nil, fmt.Errorf("too many '..' in %s", s)
+			return nil, fmt.Errorf("too many '..' in %s", strings.TrimSpace(s))
 		}
 		if max.Less(min) {
-			return nil, fmt.Errorf("range boundaries out of order (%s less than %s): %s", max, min, s)
+			return nil, fmt.Errorf("range boundaries out of order (%s less than %s): %s", max, min, strings.TrimSpace(s))
 		}
 		r[i] = YRange{min, max}
 	}
+	return r, nil
+}
+
+// restrict returns r, the ranges parsed from s, sorted and coalesced.  An
+// error is returned if r is not within y.
+func (y YangRange) restrict(r YangRange, s string) (YangRange, error) {
 	r.Sort()
 	r = coalesce(r)
 
@@ -574,6 +794,15 @@ type EnumType struct {
 	unique   bool  // numeric values must be unique (enums)
 	toString map[int64]string
 	toInt    map[string]int64
+	// descriptions maps names to the description given in their enum or
+	// bit statement, if any.
+	descriptions map[string]string
+	// names and values are the sorted names and values of e, which are
+	// computed by index so that large enumerations need not be sorted on
+	// every call to Names and Values.  They are nil if e has changed since
+	// index was last called.
+	names  []string
+	values []int64
 }
 
 // NewEnumType returns an initialized EnumType.
@@ -588,24 +817,23 @@ func NewEnumType() *EnumType {
 	}
 }
 
-// NewBitfield returns an EnumType initialized as a bitfield.  Multiple string
-// values may map to the same numeric values.  Numeric values must be small
-// non-negative integers.
+// NewBitfield returns an EnumType initialized as a bitfield.  The numeric
+// values are bit positions, which must be unique and in the range 0 to
+// MaxBitfieldSize-1 (RFC7950 section 9.7.4.2).  A bit without an explicit
+// position is assigned one greater than the largest position assigned so far.
 func NewBitfield() *EnumType {
 	return &EnumType{
 		last:     -1, // +1 will start at 0
 		min:      0,
 		max:      MaxBitfieldSize - 1,
+		unique:   true,
 		toString: map[int64]string{},
 		toInt:    map[string]int64{},
 	}
 }
 
 // Set sets name in e to the provided value.  Set returns an error if the value
-// is invalid, name is already signed, or when used as an enum rather than a
-// bitfield, the value has previousl been used.  When two different names are
-// assigned to the same value, the conversion from value to name will result in
-// the most recently assigned name.
+// is invalid, name is already assigned, or the value has previously been used.
 func (e *EnumType) Set(name string, value int64) error {
 	if _, ok := e.toInt[name]; ok {
 		return fmt.Errorf("field %s already assigned", name)
@@ -621,6 +849,7 @@ func (e *EnumType) Set(name string, value int64) error {
 	}
 	e.toString[value] = name
 	e.toInt[name] = value
+	e.names, e.values = nil, nil
 	if value >= e.last {
 		e.last = value
 	}
@@ -651,8 +880,43 @@ func (e *EnumType) IsDefined(name string) bool {
 	return defined
 }
 
+// Description returns the description of name in e, or the empty string if
+// name has no description or is not defined in e.
+func (e *EnumType) Description(name string) string { return e.descriptions[name] }
+
+// DescriptionMap returns a map of names to their descriptions.  Only names
+// that have a description are included.
+func (e *EnumType) DescriptionMap() map[string]string {
+	m := make(map[string]string, len(e.descriptions))
+	for k, v := range e.descriptions {
+		m[k] = v
+	}
+	return m
+}
+
+// setDescription records desc as the description of name in e.
+func (e *EnumType) setDescription(name string, desc *Value) {
+	if desc == nil {
+		return
+	}
+	if e.descriptions == nil {
+		e.descriptions = map[string]string{}
+	}
+	e.descriptions[name] = desc.Name
+}
+
+// index computes the sorted names and values returned by Names and Values.
+// It is called once all the names of e have been set.
+func (e *EnumType) index() {
+	e.names, e.values = nil, nil
+	e.names, e.values = e.Names(), e.Values()
+}
+
 // Names returns the sorted list of enum string names.
 func (e *EnumType) Names() []string {
+	if e.names != nil {
+		return append(make([]string, 0, len(e.names)), e.names...)
+	}
 	names := make([]string, len(e.toInt))
 	i := 0
 	for name := range e.toInt {
@@ -671,6 +935,9 @@ func (p int64Slice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
 // Values returns the sorted list of enum values.
 func (e *EnumType) Values() []int64 {
+	if e.values != nil {
+		return append(make([]int64, 0, len(e.values)), e.values...)
+	}
 	values := make([]int64, len(e.toInt))
 	i := 0
 	for _, value := range e.toInt {
@@ -681,6 +948,17 @@ func (e *EnumType) Values() []int64 {
 	return values
 }
 
+// MaxValue returns the largest value in e, e.g., the highest bit position of a
+// bitfield, which is one less than the number of bits needed to hold all of its
+// values.  false is returned if e has no values.
+func (e *EnumType) MaxValue() (int64, bool) {
+	values := e.Values()
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[len(values)-1], true
+}
+
 // NameMap returns a map of names to values.
 func (e *EnumType) NameMap() map[string]int64 {
 	m := make(map[string]int64, len(e.toInt))
@@ -15,6 +15,8 @@
 package yang
 
 import (
+	"math"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -244,6 +246,116 @@ func TestRangeContains(t *testing.T) {
 	}
 }
 
+func TestRangeUnion(t *testing.T) {
+	tests := []struct {
+		desc string
+		inR  YangRange
+		inS  YangRange
+		want YangRange
+	}{{
+		desc: "empty ranges",
+	}, {
+		desc: "empty range has every value",
+		inR:  YangRange{R(1, 2)},
+		want: nil,
+	}, {
+		desc: "disjoint ranges",
+		inR:  YangRange{R(10, 20)},
+		inS:  YangRange{R(1, 5)},
+		want: YangRange{R(1, 5), R(10, 20)},
+	}, {
+		desc: "adjacent ranges are coalesced",
+		inR:  YangRange{R(1, 5)},
+		inS:  YangRange{R(6, 10)},
+		want: YangRange{R(1, 10)},
+	}, {
+		desc: "overlapping ranges",
+		inR:  YangRange{R(1, 5), R(20, 30)},
+		inS:  YangRange{R(3, 8), R(25, 40)},
+		want: YangRange{R(1, 8), R(20, 40)},
+	}, {
+		desc: "decimal ranges",
+		inR:  YangRange{Rf(10, 25, 1)},
+		inS:  YangRange{Rf(26, 30, 1)},
+		want: YangRange{Rf(10, 30, 1)},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, tt.inR.Union(tt.inS)); diff != "" {
+				t.Errorf("Union (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRangeIntersect(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inR              YangRange
+		inS              YangRange
+		want             YangRange
+		wantErrSubstring string
+	}{{
+		desc: "empty ranges",
+		want: YangRange{},
+	}, {
+		desc: "empty range has every value",
+		inS:  YangRange{R(1, 2)},
+		want: YangRange{R(1, 2)},
+	}, {
+		desc: "subset",
+		inR:  YangRange{R(1, 10)},
+		inS:  YangRange{R(3, 5)},
+		want: YangRange{R(3, 5)},
+	}, {
+		desc: "overlapping ranges",
+		inR:  YangRange{R(1, 10), R(20, 30)},
+		inS:  YangRange{R(5, 25)},
+		want: YangRange{R(5, 10), R(20, 25)},
+	}, {
+		desc: "several sub-ranges",
+		inR:  YangRange{R(0, 0), R(2, 4), R(6, 8)},
+		inS:  YangRange{R(1, 2), R(4, 7)},
+		want: YangRange{R(2, 2), R(4, 4), R(6, 7)},
+	}, {
+		desc: "single values",
+		inR:  YangRange{R(5, 5)},
+		inS:  YangRange{R(1, 5)},
+		want: YangRange{R(5, 5)},
+	}, {
+		desc: "decimal ranges",
+		inR:  YangRange{Rf(10, 25, 1)},
+		inS:  YangRange{Rf(20, 30, 1)},
+		want: YangRange{Rf(20, 25, 1)},
+	}, {
+		desc:             "disjoint ranges",
+		inR:              YangRange{R(1, 5)},
+		inS:              YangRange{R(6, 10)},
+		wantErrSubstring: "1..5 and 6..10 have no values in common",
+	}, {
+		desc:             "integer and decimal ranges",
+		inR:              YangRange{R(1, 5)},
+		inS:              YangRange{Rf(10, 20, 1)},
+		wantErrSubstring: "mix of integer and decimal ranges",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.inR.Intersect(tt.inS)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("Intersect: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Intersect (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestParseRangesInt(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -419,6 +531,81 @@ func TestYangRangeSort(t *testing.T) {
 	}
 }
 
+func TestYangRangeBounds(t *testing.T) {
+	// bounds holds the results of each of the bound accessors.
+	type bounds struct {
+		Min, Max               int64
+		MinOK, MaxOK           bool
+		UintMin, UintMax       uint64
+		UintMinOK, UintMaxOK   bool
+		DecimalMin, DecimalMax float64
+		DecimalMinOK           bool
+		DecimalMaxOK           bool
+	}
+
+	tests := []struct {
+		desc string
+		in   YangRange
+		want bounds
+	}{{
+		desc: "empty range",
+		in:   YangRange{},
+	}, {
+		desc: "single range",
+		in:   YangRange{R(1, 4)},
+		want: bounds{
+			Min: 1, Max: 4, MinOK: true, MaxOK: true,
+			UintMin: 1, UintMax: 4, UintMinOK: true, UintMaxOK: true,
+			DecimalMin: 1, DecimalMax: 4, DecimalMinOK: true, DecimalMaxOK: true,
+		},
+	}, {
+		desc: "unsorted multiple ranges",
+		in:   YangRange{R(20, 30), R(-5, 0), R(7, 7)},
+		want: bounds{
+			Min: -5, Max: 30, MinOK: true, MaxOK: true,
+			UintMax: 30, UintMaxOK: true,
+			DecimalMin: -5, DecimalMax: 30, DecimalMinOK: true, DecimalMaxOK: true,
+		},
+	}, {
+		desc: "uint64 range",
+		in:   Uint64Range,
+		want: bounds{
+			Min: 0, MinOK: true,
+			UintMin: 0, UintMax: math.MaxUint64, UintMinOK: true, UintMaxOK: true,
+			DecimalMin: 0, DecimalMax: math.MaxUint64, DecimalMinOK: true, DecimalMaxOK: true,
+		},
+	}, {
+		desc: "int64 range",
+		in:   Int64Range,
+		want: bounds{
+			Min: math.MinInt64, Max: math.MaxInt64, MinOK: true, MaxOK: true,
+			UintMax: math.MaxInt64, UintMaxOK: true,
+			DecimalMin: math.MinInt64, DecimalMax: math.MaxInt64, DecimalMinOK: true, DecimalMaxOK: true,
+		},
+	}, {
+		desc: "decimal ranges",
+		in:   YangRange{Rf(100, 250, 2), Rf(-1234, -10, 2)},
+		want: bounds{
+			DecimalMin: -12.34, DecimalMax: 2.5, DecimalMinOK: true, DecimalMaxOK: true,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got bounds
+			got.Min, got.MinOK = tt.in.Min()
+			got.Max, got.MaxOK = tt.in.Max()
+			got.UintMin, got.UintMinOK = tt.in.UintMin()
+			got.UintMax, got.UintMaxOK = tt.in.UintMax()
+			got.DecimalMin, got.DecimalMinOK = tt.in.DecimalMin()
+			got.DecimalMax, got.DecimalMaxOK = tt.in.DecimalMax()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("bounds of %v (-want, +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
 func TestParseRangesDecimal(t *testing.T) {
 	rangeMax := mustParseRangesDecimal("-922337203685477580.8..922337203685477580.7", 1)
 	rangeRestricted := mustParseRangesDecimal("-42..42|100", 5)
@@ -574,6 +761,62 @@ func TestParseRangesDecimal(t *testing.T) {
 	}
 }
 
+func TestParseRestriction(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inRange          YangRange
+		in               string
+		want             YangRange
+		wantErrSubstring string
+	}{{
+		desc:    "length with max",
+		inRange: Uint64Range,
+		in:      "8..max",
+		want:    YangRange{YRange{FromInt(8), FromUint(maxUint64)}},
+	}, {
+		desc:    "range with min",
+		inRange: Int8Range,
+		in:      "min..100",
+		want:    YangRange{R(minInt8, 100)},
+	}, {
+		desc:    "min and max alone",
+		inRange: Uint8Range,
+		in:      "min | max",
+		want:    YangRange{R(0, 0), R(maxUint8, maxUint8)},
+	}, {
+		desc:    "restricted parent",
+		inRange: YangRange{R(1, 4), R(10, 20)},
+		in:      "min..3 | 12..max",
+		want:    YangRange{R(1, 3), R(12, 20)},
+	}, {
+		desc:    "decimal64",
+		inRange: mustParseRangesDecimal("-10..10", 2),
+		in:      "min..1.5",
+		want:    YangRange{Rf(-1000, 150, 2)},
+	}, {
+		desc:             "not within parent",
+		inRange:          Int8Range,
+		in:               "min..1000",
+		wantErrSubstring: "not within",
+	}, {
+		desc:             "empty parent",
+		in:               "min..max",
+		wantErrSubstring: "cannot restrict an empty YangRange",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.inRange.ParseRestriction(tt.in)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("ParseRestriction(%q): %s", tt.in, diff)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseRestriction(%q) (-want, +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
 func TestAdd(t *testing.T) {
 	tests := []struct {
 		desc  string
@@ -815,3 +1058,78 @@ func TestNumberString(t *testing.T) {
 		})
 	}
 }
+
+func TestEnumTypeIndex(t *testing.T) {
+	e := NewEnumType()
+	for _, n := range []string{"c", "a", "b"} {
+		if err := e.SetNext(n); err != nil {
+			t.Fatalf("SetNext(%q): %v", n, err)
+		}
+	}
+	e.index()
+	if diff := cmp.Diff([]string{"a", "b", "c"}, e.Names()); diff != "" {
+		t.Errorf("Names (-want, +got):\n%s", diff)
+	}
+
+	// The slices returned must not share the cached ones.
+	names := e.Names()
+	names[0] = "z"
+	values := e.Values()
+	values[0] = 100
+	if got := e.Names()[0]; got != "a" {
+		t.Errorf("Names()[0] after modifying a returned slice: got %q, want %q", got, "a")
+	}
+	if got := e.Values()[0]; got != 0 {
+		t.Errorf("Values()[0] after modifying a returned slice: got %d, want 0", got)
+	}
+
+	// Setting a name after indexing must be reflected.
+	if err := e.Set("aa", -1); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "aa", "b", "c"}, e.Names()); diff != "" {
+		t.Errorf("Names after Set (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int64{-1, 0, 1, 2}, e.Values()); diff != "" {
+		t.Errorf("Values after Set (-want, +got):\n%s", diff)
+	}
+}
+
+// BenchmarkEnumType measures lookups in an enumeration with 5000 values, as
+// found in some IANA modules.
+func BenchmarkEnumType(b *testing.B) {
+	const size = 5000
+	e := NewEnumType()
+	for i := 0; i < size; i++ {
+		if err := e.SetNext("value-" + strconv.Itoa(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	e.index()
+
+	b.Run("Names", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.Names()
+		}
+	})
+	b.Run("Values", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.Values()
+		}
+	})
+	b.Run("Value", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.Value("value-4999")
+		}
+	})
+	b.Run("Name", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.Name(size - 1)
+		}
+	})
+	b.Run("IsDefined", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.IsDefined("value-2500")
+		}
+	})
+}
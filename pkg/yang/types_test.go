@@ -64,6 +64,60 @@ func TestTypeResolve(t *testing.T) {
 			Range: &Range{Name: "-42..forty-two"},
 		},
 		err: `unknown: bad range: strconv.ParseUint: parsing "forty-two": invalid syntax`,
+	}, {
+		desc: "int32 with an inverted range",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "1 | 10..5"},
+		},
+		err: "unknown: bad range: range boundaries out of order (5 less than 10): 10..5",
+	}, {
+		desc: "int32 with too many boundaries in a range",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "1..2..3"},
+		},
+		err: "unknown: bad range: too many '..' in 1..2..3",
+	}, {
+		desc: "int32 with overlapping ranges",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "1..5 | 3..7"},
+		},
+		err: "unknown: bad range: overlapping range parts (1..5 and 3..7): 1..5 | 3..7",
+	}, {
+		desc: "int32 with ranges out of order",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "10..20 | 1..5"},
+		},
+		err: "unknown: bad range: range parts out of order (10..20 before 1..5): 10..20 | 1..5",
+	}, {
+		desc: "int32 with adjacent ranges",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "1..5 | 6..7 | 9"},
+		},
+		out: &YangType{
+			Name:  "int32",
+			Kind:  Yint32,
+			Range: YangRange{{Min: FromInt(1), Max: FromInt(7)}, {Min: FromInt(9), Max: FromInt(9)}},
+		},
+	}, {
+		desc: "int32 with a hexadecimal range boundary",
+		in: &Type{
+			Name:  "int32",
+			Range: &Range{Name: "1..0x10"},
+		},
+		err: `unknown: bad range: invalid number "0x10" in range part 1..0x10`,
+	}, {
+		desc: "decimal64 with overlapping ranges",
+		in: &Type{
+			Name:           "decimal64",
+			FractionDigits: &Value{Name: "2"},
+			Range:          &Range{Name: "1.5..2.5|2.25"},
+		},
+		err: "unknown: bad range: overlapping range parts (1.50..2.50 and 2.25): 1.5..2.5|2.25",
 	}, {
 		desc: "basic string with a length",
 		in: &Type{
@@ -82,6 +136,20 @@ func TestTypeResolve(t *testing.T) {
 			Length: &Length{Name: "-42..42"},
 		},
 		err: `unknown: bad length: -42..42 not within 0..18446744073709551615`,
+	}, {
+		desc: "string with overlapping lengths",
+		in: &Type{
+			Name:   "string",
+			Length: &Length{Name: "1..10|5"},
+		},
+		err: "unknown: bad length: overlapping range parts (1..10 and 5): 1..10|5",
+	}, {
+		desc: "string with an inverted length",
+		in: &Type{
+			Name:   "string",
+			Length: &Length{Name: "10..1"},
+		},
+		err: "unknown: bad length: range boundaries out of order (1 less than 10): 10..1",
 	}, {
 		desc: "basic binary with a length",
 		in: &Type{
@@ -1008,7 +1076,7 @@ func TestPattern(t *testing.T) {
 			}
 		} // end module`,
 		wantType: &YangType{
-			Pattern: []string{"charlie"},
+			Pattern: []*YangPattern{{Expr: "charlie"}},
 		},
 	}, {
 		desc: "Only posix patterns",
@@ -1051,7 +1119,7 @@ func TestPattern(t *testing.T) {
 			}
 		} // end module`,
 		wantType: &YangType{
-			Pattern:      []string{"alpha"},
+			Pattern:      []*YangPattern{{Expr: "alpha"}},
 			POSIXPattern: []string{"bravo", "charlie", "delta"},
 		},
 	}, {
@@ -1078,7 +1146,7 @@ func TestPattern(t *testing.T) {
 			}
 		} // end module`,
 		wantType: &YangType{
-			Pattern:      []string{"alpha", "bravo", "charlie"},
+			Pattern:      []*YangPattern{{Expr: "alpha"}, {Expr: "bravo"}, {Expr: "charlie"}},
 			POSIXPattern: []string{"delta", "echo", "foxtrot"},
 		},
 	}, {
@@ -1106,7 +1174,7 @@ func TestPattern(t *testing.T) {
 		} // end module`,
 		wantType: &YangType{
 			Type: []*YangType{{
-				Pattern:      []string{"alpha", "bravo", "charlie"},
+				Pattern:      []*YangPattern{{Expr: "alpha"}, {Expr: "bravo"}, {Expr: "charlie"}},
 				POSIXPattern: []string{"delta", "echo", "foxtrot"},
 			}, {
 				Pattern:      nil,
@@ -1131,7 +1199,7 @@ func TestPattern(t *testing.T) {
 		} // end module`,
 		wantType: &YangType{
 			Type: []*YangType{{
-				Pattern:      []string{"alpha"},
+				Pattern:      []*YangPattern{{Expr: "alpha"}},
 				POSIXPattern: []string{"alpha"},
 			}},
 		},
@@ -1151,9 +1219,9 @@ func TestPattern(t *testing.T) {
 		} // end module`,
 		wantType: &YangType{
 			Type: []*YangType{{
-				Pattern: []string{"alpha"},
+				Pattern: []*YangPattern{{Expr: "alpha"}},
 			}, {
-				Pattern: []string{"bravo"},
+				Pattern: []*YangPattern{{Expr: "bravo"}},
 			}},
 		},
 	}, {
@@ -1201,7 +1269,7 @@ func TestPattern(t *testing.T) {
 			}
 		} // end module`,
 		wantType: &YangType{
-			Pattern:      []string{"alpha", "bravo", "charlie"},
+			Pattern:      []*YangPattern{{Expr: "alpha"}, {Expr: "bravo"}, {Expr: "charlie"}},
 			POSIXPattern: []string{"delta", "echo", "foxtrot"},
 		},
 	}, {
@@ -1218,6 +1286,67 @@ func TestPattern(t *testing.T) {
 			}
 		} // end module`,
 		wantErrSubstr: "bad pattern",
+	}, {
+		desc: "pattern substatements",
+		leafNode: `
+			leaf test-leaf {
+				type string {
+					pattern '[a-z]+' {
+						error-message "lower case letters only";
+						error-app-tag "bad-name";
+						description "names are lower case";
+					}
+					pattern 'x.*' {
+						modifier invert-match;
+						error-message "names cannot start with x";
+					}
+				}
+			}
+		} // end module`,
+		wantType: &YangType{
+			Pattern: []*YangPattern{{
+				Expr:         "[a-z]+",
+				ErrorMessage: "lower case letters only",
+				ErrorAppTag:  "bad-name",
+				Description:  "names are lower case",
+			}, {
+				Expr:         "x.*",
+				InvertMatch:  true,
+				ErrorMessage: "names cannot start with x",
+			}},
+		},
+	}, {
+		desc: "inverted pattern in a derived type",
+		leafNode: `
+			leaf test-leaf {
+				type leaf-type {
+					pattern 'alpha' {
+						modifier invert-match;
+					}
+				}
+			}
+
+			typedef leaf-type {
+				type string {
+					pattern 'alpha';
+				}
+			}
+		} // end module`,
+		wantType: &YangType{
+			Pattern: []*YangPattern{{Expr: "alpha"}, {Expr: "alpha", InvertMatch: true}},
+		},
+	}, {
+		desc: "invalid modifier",
+		leafNode: `
+			leaf test-leaf {
+				type string {
+					pattern 'alpha' {
+						modifier match;
+					}
+				}
+			}
+		} // end module`,
+		wantErrSubstr: `invalid pattern modifier "match", must be invert-match`,
 	}}
 
 	getTestLeaf := func(ms *Modules) (*YangType, error) {
@@ -1372,6 +1501,95 @@ func TestTypeLengthRange(t *testing.T) {
 			Name:  "bravo",
 			Range: YangRange{R(1, 3), R(12, 20)},
 		},
+	}, {
+		desc: "min and max through a chain of typedefs",
+		leafNode: `
+			typedef alpha {
+				type int8 {
+					range "min..10";
+				}
+			}
+			typedef bravo {
+				type alpha {
+					range "min..5 | 7..max";
+				}
+			}
+			leaf test-leaf {
+				type bravo;
+			}
+		} // end module`,
+		wantType: &testRangeTypeStruct{
+			Name:  "bravo",
+			Range: YangRange{R(minInt8, 5), R(7, 10)},
+		},
+	}, {
+		desc: "full uint64 range",
+		leafNode: `
+			typedef alpha {
+				type uint64 {
+					range "0..18446744073709551615";
+				}
+			}
+			leaf test-leaf {
+				type alpha;
+			}
+		} // end module`,
+		wantType: &testRangeTypeStruct{
+			Name:  "alpha",
+			Range: YangRange{{Min: FromUint(0), Max: FromUint(18446744073709551615)}},
+		},
+	}, {
+		desc: "uint64 range restricted near its max",
+		leafNode: `
+			typedef alpha {
+				type uint64 {
+					range "9223372036854775808..max";
+				}
+			}
+			typedef bravo {
+				type alpha {
+					range "min..9223372036854775809 | 18446744073709551614..max";
+				}
+			}
+			leaf test-leaf {
+				type bravo;
+			}
+		} // end module`,
+		wantType: &testRangeTypeStruct{
+			Name: "bravo",
+			Range: YangRange{
+				{Min: FromUint(9223372036854775808), Max: FromUint(9223372036854775809)},
+				{Min: FromUint(18446744073709551614), Max: FromUint(18446744073709551615)},
+			},
+		},
+	}, {
+		desc: "uint64 range beyond its max",
+		leafNode: `
+			leaf test-leaf {
+				type uint64 {
+					range "0..18446744073709551616";
+				}
+			}
+		} // end module`,
+		wantErrSubstr: "value out of range",
+	}, {
+		desc: "length with max through a typedef",
+		leafNode: `
+			typedef alpha {
+				type string {
+					length "8..max";
+				}
+			}
+			leaf test-leaf {
+				type alpha {
+					length "min..20";
+				}
+			}
+		} // end module`,
+		wantType: &testRangeTypeStruct{
+			Name:   "alpha",
+			Length: YangRange{R(8, 20)},
+		},
 	}, {
 		desc: "inherited uint32 range violation",
 		leafNode: `
@@ -1718,3 +1936,293 @@ func filterRanges(ytype *YangType) *testRangeTypeStruct {
 	}
 	return filteredType
 }
+
+func TestEnumAndIdentityDescriptions(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module test {
+			prefix "t";
+			namespace "urn:t";
+
+			identity base-id;
+			identity first-id {
+				base base-id;
+				description "The first identity.";
+			}
+			identity second-id {
+				base base-id;
+			}
+
+			leaf enum-leaf {
+				type enumeration {
+					enum up {
+						description "The interface is up.";
+					}
+					enum down {
+						description "The interface is down.";
+					}
+					enum unknown;
+				}
+			}
+			leaf bits-leaf {
+				type bits {
+					bit flag {
+						description "A flag.";
+					}
+					bit other;
+				}
+			}
+			leaf identity-leaf {
+				type identityref {
+					base base-id;
+				}
+			}
+		}`, "test"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	e := ToEntry(ms.Modules["test"])
+
+	enum := e.Dir["enum-leaf"].Type.Enum
+	if diff := cmp.Diff(map[string]string{
+		"up":   "The interface is up.",
+		"down": "The interface is down.",
+	}, enum.DescriptionMap()); diff != "" {
+		t.Errorf("enum DescriptionMap (-want, +got):\n%s", diff)
+	}
+	if got, want := enum.Description("up"), "The interface is up."; got != want {
+		t.Errorf("enum Description(up): got %q, want %q", got, want)
+	}
+	if got := enum.Description("unknown"); got != "" {
+		t.Errorf("enum Description(unknown): got %q, want empty string", got)
+	}
+
+	bits := e.Dir["bits-leaf"].Type.Bit
+	if diff := cmp.Diff(map[string]string{"flag": "A flag."}, bits.DescriptionMap()); diff != "" {
+		t.Errorf("bits DescriptionMap (-want, +got):\n%s", diff)
+	}
+
+	base := e.Dir["identity-leaf"].Type.IdentityBase
+	if diff := cmp.Diff(map[string]string{"test:first-id": "The first identity."}, base.ValueDescriptions()); diff != "" {
+		t.Errorf("identity ValueDescriptions (-want, +got):\n%s", diff)
+	}
+}
+
+func TestEmptyRestrictedTypes(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inType           string
+		wantErrSubstring string
+	}{{
+		desc:   "enumeration with enums",
+		inType: "type enumeration { enum a; }",
+	}, {
+		desc:             "empty enumeration",
+		inType:           "type enumeration;",
+		wantErrSubstring: "test.yang:6:5: enumeration type has no enum statements",
+	}, {
+		desc:             "empty bits",
+		inType:           "type bits { }",
+		wantErrSubstring: "test.yang:6:5: bits type has no bit statements",
+	}, {
+		desc:             "empty union",
+		inType:           "type union;",
+		wantErrSubstring: "test.yang:6:5: union type has no member types",
+	}, {
+		desc:   "typedef of an enumeration",
+		inType: "type e;",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module test {
+  prefix "t";
+  namespace "urn:t";
+  typedef e { type enumeration { enum x; } }
+  leaf l {
+    `+tt.inType+`
+  }
+}`, "test.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Process: %s", diff)
+			}
+		})
+	}
+}
+
+func TestBitPositions(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inBits           string
+		wantPositions    map[string]int64
+		wantMax          int64
+		wantErrSubstring string
+	}{{
+		desc:          "implicit positions",
+		inBits:        "bit a; bit b; bit c;",
+		wantPositions: map[string]int64{"a": 0, "b": 1, "c": 2},
+		wantMax:       2,
+	}, {
+		desc:          "sparse explicit positions",
+		inBits:        "bit a { position 3; } bit b { position 40; } bit c; bit d { position 7; } bit e;",
+		wantPositions: map[string]int64{"a": 3, "b": 40, "c": 41, "d": 7, "e": 42},
+		wantMax:       42,
+	}, {
+		desc:          "implicit position after a lower explicit position",
+		inBits:        "bit a { position 1; } bit b { position 0; } bit c;",
+		wantPositions: map[string]int64{"a": 1, "b": 0, "c": 2},
+		wantMax:       2,
+	}, {
+		desc:          "maximum position",
+		inBits:        "bit a; bit b { position 4294967295; }",
+		wantPositions: map[string]int64{"a": 0, "b": 4294967295},
+		wantMax:       4294967295,
+	}, {
+		desc:             "duplicate position",
+		inBits:           "bit a { position 5; } bit b { position 5; }",
+		wantErrSubstring: "test.yang:5:39: fields b and a conflict on value 5",
+	}, {
+		desc:             "negative position",
+		inBits:           "bit a { position -1; }",
+		wantErrSubstring: "value -1 for a too small (minimum is 0)",
+	}, {
+		desc:             "position too large",
+		inBits:           "bit a { position 4294967296; }",
+		wantErrSubstring: "value 4294967296 for a too large (maximum is 4294967295)",
+	}, {
+		desc:             "implicit position too large",
+		inBits:           "bit a { position 4294967295; } bit b;",
+		wantErrSubstring: "value 4294967296 for b too large (maximum is 4294967295)",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module test {
+  prefix "t";
+  namespace "urn:t";
+  leaf l {
+    type bits { `+tt.inBits+` }
+  }
+}`, "test.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Fatalf("Process: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			bits := ToEntry(ms.Modules["test"]).Dir["l"].Type.Bit
+			if diff := cmp.Diff(tt.wantPositions, bits.NameMap()); diff != "" {
+				t.Errorf("bit positions (-want, +got):\n%s", diff)
+			}
+			if got, ok := bits.MaxValue(); !ok || got != tt.wantMax {
+				t.Errorf("MaxValue: got %d, %v, want %d, true", got, ok, tt.wantMax)
+			}
+		})
+	}
+
+	if _, ok := NewBitfield().MaxValue(); ok {
+		t.Errorf("MaxValue of an empty bitfield: got ok, want !ok")
+	}
+}
+
+func TestSubmoduleTypedefs(t *testing.T) {
+	tests := []struct {
+		desc             string
+		module           string
+		submodules       []string
+		wantErrSubstring string
+	}{{
+		desc:   "module uses a submodule typedef",
+		module: `include s1; leaf a { type t1; } leaf b { type p:t1; }`,
+		submodules: []string{
+			`typedef t1 { type string; }`,
+		},
+	}, {
+		desc:   "module uses a typedef of a submodule included by a submodule",
+		module: `include s1; leaf a { type t2; }`,
+		submodules: []string{
+			`include s2;`,
+			`typedef t2 { type string; }`,
+		},
+	}, {
+		desc:   "submodule uses a module typedef",
+		module: `include s1; typedef t { type string; }`,
+		submodules: []string{
+			`leaf a { type t; } leaf b { type p:t; }`,
+		},
+	}, {
+		desc:   "submodule uses a sibling submodule typedef",
+		module: `include s1; include s2;`,
+		submodules: []string{
+			`typedef t1 { type t2; } leaf a { type t1; }`,
+			`typedef t2 { type string; }`,
+		},
+	}, {
+		desc:   "module uses a submodule grouping with a sibling submodule typedef",
+		module: `include s1; include s2; uses g;`,
+		submodules: []string{
+			`grouping g { leaf a { type t2; } }`,
+			`typedef t2 { type string; }`,
+		},
+	}, {
+		desc:   "submodule uses a module grouping",
+		module: `include s1; grouping g { leaf a { type string; } }`,
+		submodules: []string{
+			`container c { uses g; }`,
+		},
+	}, {
+		desc:   "unknown type in a submodule",
+		module: `include s1;`,
+		submodules: []string{
+			`leaf a { type missing; }`,
+		},
+		wantErrSubstring: "s1.yang:1:72: unknown type: p:missing",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`module p { yang-version 1.1; prefix "p"; namespace "urn:p"; `+tt.module+` }`, "p.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			for i, sub := range tt.submodules {
+				name := fmt.Sprintf("s%d", i+1)
+				if err := ms.Parse(`submodule `+name+` { yang-version 1.1; belongs-to p { prefix "p"; } `+sub+` }`, name+".yang"); err != nil {
+					t.Fatalf("cannot parse submodule %s: %v", name, err)
+				}
+			}
+			var err error
+			if errs := ms.Process(); len(errs) > 0 {
+				if len(errs) > 1 {
+					t.Errorf("got %d errors, want at most 1: %v", len(errs), errs)
+				}
+				err = errs[0]
+			}
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Process: %s", diff)
+			}
+		})
+	}
+}
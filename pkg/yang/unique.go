@@ -0,0 +1,102 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the checking of the unique statements of lists, as
+// described in RFC7950 section 7.8.3.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// uniqueErrors returns an error for each node named by a unique statement of
+// a list in the schema trees of the modules in ms that is not a leaf
+// descendant of the list, and for each unique statement naming both
+// configuration and state leaves.  The errors in a list that is instantiated
+// by several uses of a grouping are reported once.
+func (ms *Modules) uniqueErrors() []error {
+	var errs []error
+	for _, name := range ms.moduleNames() {
+		m := ms.Modules[name]
+		var ie instanceErrors
+		addUniqueErrors(&ie, ToEntry(m))
+		mErrs := ie.errors()
+		ms.noteErrors(m, mErrs...)
+		errs = append(errs, mErrs...)
+	}
+	return errs
+}
+
+// addUniqueErrors adds the errors in the unique statements of e and its
+// descendants to ie.
+func addUniqueErrors(ie *instanceErrors, e *Entry) {
+	if e == nil {
+		return
+	}
+	if e.IsList() {
+		for _, u := range e.Extra["unique"] {
+			if v, ok := u.(*Value); ok {
+				e.addUniqueErrors(ie, v)
+			}
+		}
+	}
+	if e.RPC != nil {
+		addUniqueErrors(ie, e.RPC.Input)
+		addUniqueErrors(ie, e.RPC.Output)
+	}
+	for _, c := range e.Dir {
+		addUniqueErrors(ie, c)
+	}
+}
+
+// addUniqueErrors adds the errors in the unique statement u of the list e to
+// ie.
+func (e *Entry) addUniqueErrors(ie *instanceErrors, u *Value) {
+	var config, state []string
+	for _, id := range strings.Fields(u.Name) {
+		leaf, err := e.uniqueLeaf(id)
+		if err != nil {
+			ie.add(u, id, "%s: unique %q of list %s: %v", Source(u), u.Name, e.Path(), err)
+			continue
+		}
+		if leaf.ReadOnly() {
+			state = append(state, id)
+		} else {
+			config = append(config, id)
+		}
+	}
+	if len(config) > 0 && len(state) > 0 {
+		ie.add(u, "", "%s: unique %q of list %s: config leaves %s and state leaves %s cannot be combined", Source(u), u.Name, e.Path(), strings.Join(config, ", "), strings.Join(state, ", "))
+	}
+}
+
+// uniqueLeaf returns the leaf named by the descendant schema node identifier
+// id relative to the list e.  Choice and case nodes may be omitted from id.
+func (e *Entry) uniqueLeaf(id string) (*Entry, error) {
+	cur := e
+	for _, part := range strings.Split(id, "/") {
+		_, name := getPrefix(part)
+		next := cur.schemaChild(name)
+		if next == nil || next.RPC != nil || next.Kind == NotificationEntry {
+			return nil, fmt.Errorf("%s not found in %s", part, cur.Path())
+		}
+		cur = next
+	}
+	if !cur.IsLeaf() {
+		return nil, fmt.Errorf("%s is not a leaf", cur.Path())
+	}
+	return cur, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestUniqueErrors(t *testing.T) {
+	tests := []struct {
+		desc              string
+		inUnique          string
+		wantErrSubstrings []string
+	}{{
+		desc:     "direct leaves",
+		inUnique: "ip port",
+	}, {
+		desc:     "leaf in a container, prefixed",
+		inUnique: "d:ip d:endpoint/d:host",
+	}, {
+		desc:     "leaf in a choice",
+		inUnique: "ip tcp-port",
+	}, {
+		desc:     "state leaves",
+		inUnique: "state/counter",
+	}, {
+		desc:              "typo",
+		inUnique:          "ip prot",
+		wantErrSubstrings: []string{`dev.yang:25:7: unique "ip prot" of list /dev/servers/server: prot not found in /dev/servers/server`},
+	}, {
+		desc:              "not a leaf",
+		inUnique:          "endpoint",
+		wantErrSubstrings: []string{"/dev/servers/server/endpoint is not a leaf"},
+	}, {
+		desc:              "leaf-list",
+		inUnique:          "tags",
+		wantErrSubstrings: []string{"/dev/servers/server/tags is not a leaf"},
+	}, {
+		desc:              "config and state",
+		inUnique:          "ip state/counter",
+		wantErrSubstrings: []string{"config leaves ip and state leaves state/counter cannot be combined"},
+	}, {
+		desc:     "two typos",
+		inUnique: "ipp prot",
+		wantErrSubstrings: []string{
+			"ipp not found in /dev/servers/server",
+			"prot not found in /dev/servers/server",
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(`
+module dev {
+  prefix "d";
+  namespace "urn:d";
+
+  container servers {
+    list server {
+      key "name";
+      leaf name { type string; }
+      leaf ip { type string; }
+      leaf port { type uint16; }
+      leaf-list tags { type string; }
+      container endpoint {
+        leaf host { type string; }
+      }
+      choice transport {
+        case tcp {
+          leaf tcp-port { type uint16; }
+        }
+      }
+      container state {
+        config false;
+        leaf counter { type uint64; }
+      }
+      unique "`+tt.inUnique+`";
+    }
+  }
+}`, "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			errs := ms.Process()
+			if len(errs) != len(tt.wantErrSubstrings) {
+				t.Fatalf("got %d errors, want %d: %v", len(errs), len(tt.wantErrSubstrings), errs)
+			}
+			for i, err := range errs {
+				if diff := errdiff.Substring(err, tt.wantErrSubstrings[i]); diff != "" {
+					t.Errorf("Process: %s", diff)
+				}
+			}
+		})
+	}
+}
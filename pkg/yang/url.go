@@ -0,0 +1,128 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements searching for .yang files in http:// and https://
+// entries of Path.
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultURLTimeout is the time allowed to fetch a module file from a URL
+// when Options.URLTimeout is not set.
+const DefaultURLTimeout = 30 * time.Second
+
+// errURLNotFound is returned by fetchURL when the server has no such file.
+var errURLNotFound = errors.New("not found")
+
+// isURL reports whether the Path entry p is an http:// or https:// URL.
+func isURL(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// splitPath splits the colon separated list of directories and URLs in path.
+// The colon following the scheme of a URL does not separate entries.
+func splitPath(path string) []string {
+	var paths []string
+	parts := strings.Split(path, ":")
+	for i := 0; i < len(parts); i++ {
+		p := parts[i]
+		if (p == "http" || p == "https") && i+1 < len(parts) && strings.HasPrefix(parts[i+1], "//") {
+			i++
+			p += ":" + parts[i]
+			// A port number, which directly follows the host, is also
+			// preceded by a colon.
+			if i+1 < len(parts) && !strings.Contains(strings.TrimPrefix(parts[i], "//"), "/") && startsWithDigit(parts[i+1]) {
+				i++
+				p += ":" + parts[i]
+			}
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// startsWithDigit reports whether s starts with a decimal digit.
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// fetchURL returns the URL and contents of the file name found at the base
+// URL dir.  If ms.ParseOptions.URLCacheDir is set then the file is read from
+// the cache if present, and added to it otherwise.  errURLNotFound is
+// returned if the server reports that there is no such file.
+//
+// Unlike directories, a URL cannot be scanned for name@revision-date.yang
+// files, so only exact file names are found.
+func (ms *Modules) fetchURL(dir, name string) (string, string, error) {
+	u := strings.TrimSuffix(dir, "/") + "/" + name
+
+	var cache string
+	if cd := ms.ParseOptions.URLCacheDir; cd != "" {
+		pu, err := url.Parse(u)
+		if err != nil {
+			return "", "", err
+		}
+		// A name or URL containing .. must not escape the cache.
+		root := filepath.Clean(cd)
+		cache = filepath.Clean(filepath.Join(root, pu.Scheme, pu.Host, filepath.FromSlash(pu.Path)))
+		if rel, err := filepath.Rel(root, cache); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", "", fmt.Errorf("%s: cache file %s is outside the URL cache %s", u, cache, root)
+		}
+		if data, err := ioutil.ReadFile(cache); err == nil {
+			return u, string(data), nil
+		}
+	}
+
+	timeout := ms.ParseOptions.URLTimeout
+	if timeout == 0 {
+		timeout = DefaultURLTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(u)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", "", errURLNotFound
+	case resp.StatusCode != http.StatusOK:
+		return "", "", fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %v", u, err)
+	}
+
+	if cache != "" {
+		if err := os.MkdirAll(filepath.Dir(cache), 0755); err != nil {
+			return "", "", err
+		}
+		if err := ioutil.WriteFile(cache, data, 0644); err != nil {
+			return "", "", err
+		}
+	}
+	return u, string(data), nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"a:b", []string{"a", "b"}},
+		{"http://example.com/yang", []string{"http://example.com/yang"}},
+		{"a:https://example.com:8443/yang:b", []string{"a", "https://example.com:8443/yang", "b"}},
+		{"http://example.com/yang:8dir", []string{"http://example.com/yang", "8dir"}},
+		{"http:b", []string{"http", "b"}},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, splitPath(tt.in)); diff != "" {
+			t.Errorf("splitPath(%q) (-want, +got):\n%s", tt.in, diff)
+		}
+	}
+}
+
+func TestReadURL(t *testing.T) {
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		switch r.URL.Path {
+		case "/models/top.yang":
+			w.Write([]byte(`module top {
+				prefix "t";
+				namespace "urn:t";
+				import dep { prefix d; }
+				leaf l { type d:name; }
+			}`))
+		case "/models/dep.yang":
+			w.Write([]byte(`module dep {
+				prefix "d";
+				namespace "urn:d";
+				typedef name { type string; }
+			}`))
+		case "/models/slow.yang":
+			time.Sleep(200 * time.Millisecond)
+		case "/models/broken.yang":
+			http.Error(w, "broken", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cache, err := ioutil.TempDir("", "goyang-url-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cache)
+
+	ms := NewModules()
+	ms.ParseOptions.URLCacheDir = cache
+	ms.AddPath(srv.URL + "/models/")
+	if _, errs := ms.GetModule("top"); len(errs) != 0 {
+		t.Fatalf("GetModule(top): %v", errs)
+	}
+	if diff := cmp.Diff([]string{"/models/top.yang", "/models/dep.yang"}, fetched); diff != "" {
+		t.Errorf("fetched files (-want, +got):\n%s", diff)
+	}
+	if got, want := ms.Modules["dep"].Source.Location(), srv.URL+"/models/dep.yang"; !strings.HasPrefix(got, want) {
+		t.Errorf("dep location: got %q, want prefix %q", got, want)
+	}
+
+	// A second set of modules is satisfied from the cache.
+	fetched = nil
+	ms2 := NewModules()
+	ms2.ParseOptions.URLCacheDir = cache
+	ms2.AddPath(srv.URL + "/models")
+	if _, errs := ms2.GetModule("top"); len(errs) != 0 {
+		t.Fatalf("GetModule(top) from cache: %v", errs)
+	}
+	if len(fetched) != 0 {
+		t.Errorf("fetched %v, want all files from the cache", fetched)
+	}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if _, err := os.Stat(filepath.Join(cache, "http", host, "models", "dep.yang")); err != nil {
+		t.Errorf("dep.yang not cached: %v", err)
+	}
+
+	tests := []struct {
+		desc             string
+		name             string
+		timeout          time.Duration
+		wantErrSubstring string
+	}{{
+		desc:             "not found",
+		name:             "missing",
+		wantErrSubstring: "no such file: missing.yang",
+	}, {
+		desc:             "server error",
+		name:             "broken",
+		wantErrSubstring: "500 Internal Server Error",
+	}, {
+		desc:             "timeout",
+		name:             "slow",
+		timeout:          50 * time.Millisecond,
+		wantErrSubstring: "no such file: slow.yang",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			ms.ParseOptions.URLTimeout = tt.timeout
+			ms.AddPath(srv.URL + "/models")
+			err := ms.Read(tt.name)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("Read(%s): %s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestFetchURLCacheEscape(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`module escape { prefix "e"; namespace "urn:e"; }`))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "goyang-url-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ms := NewModules()
+	ms.ParseOptions.URLCacheDir = filepath.Join(dir, "cache")
+	_, _, err = ms.fetchURL(srv.URL+"/models", "../../../../escape.yang")
+	if diff := errdiff.Substring(err, "is outside the URL cache"); diff != "" {
+		t.Errorf("fetchURL: %s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape.yang")); !os.IsNotExist(err) {
+		t.Errorf("escape.yang written outside the cache: %v", err)
+	}
+
+	// A .. that stays within the cache is allowed.
+	if _, _, err := ms.fetchURL(srv.URL+"/models", "../other/escape.yang"); err != nil {
+		t.Errorf("fetchURL within the cache: %v", err)
+	}
+}
@@ -0,0 +1,435 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the validation of instance data against an Entry tree.
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks that data conforms to the schema of the children of e, which
+// is normally a module, container or list entry.  data is a tree of instance
+// data encoded as JSON per RFC7951 and decoded by encoding/json, i.e., objects
+// are map[string]interface{} and arrays are []interface{}.  Member names may
+// be module qualified (e.g., "module:name") or not.
+//
+// Validate checks the structure of data, that the values of leaves and
+// leaf-lists are valid for their types, that mandatory nodes are present, that
+// the number of elements of lists and leaf-lists is within their min-elements
+// and max-elements, and that at most one case of each choice, and exactly one
+// case of each mandatory choice, is present.  Must and when statements, and
+// patterns, are not evaluated.
+//
+// All errors found are returned, each prefixed with the path in data at which
+// it was found.
+func (e *Entry) Validate(data map[string]interface{}) []error {
+	v := &validator{}
+	v.object(e, "", data)
+	return errorSort(v.errs)
+}
+
+// A validator collects the errors found while validating instance data.
+type validator struct {
+	errs []error
+}
+
+// errorf records an error found at path in the instance data.
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s: "+format, append([]interface{}{path}, args...)...))
+}
+
+// object validates data as the JSON object holding the children of e.
+func (v *validator) object(e *Entry, path string, data map[string]interface{}) {
+	members := dataMembers(e)
+	present := map[*Entry]bool{}
+
+	var names []string
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := members[name]
+		if c == nil {
+			v.errorf(path, "unknown member %q", name)
+			continue
+		}
+		if present[c] {
+			v.errorf(path, "member %q specified more than once", name)
+			continue
+		}
+		present[c] = true
+		v.value(c, path+"/"+c.Name, data[name])
+	}
+	v.children(e, path, present)
+}
+
+// children checks the constraints that the children of e, which are present
+// in the data if they are in present, place on their parent's data.
+func (v *validator) children(e *Entry, path string, present map[*Entry]bool) {
+	keys := map[string]bool{}
+	if e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			keys[k] = true
+		}
+	}
+	for _, c := range sortedDir(e) {
+		switch {
+		case c.IsChoice():
+			v.choice(c, path, present)
+		case c.IsCase():
+			v.children(c, path, present)
+		case present[c]:
+		case keys[c.Name]:
+			v.errorf(path, "missing key %q", c.Name)
+		case c.IsLeaf() && c.Mandatory == TSTrue:
+			v.errorf(path, "missing mandatory leaf %q", c.Name)
+		case (c.IsList() || c.IsLeafList()) && c.ListAttr != nil && c.ListAttr.MinElements > 0:
+			v.errorf(path, "%q has 0 elements, it must have at least %d", c.Name, c.ListAttr.MinElements)
+		}
+	}
+}
+
+// choice checks that at most one case of the choice e has data present, and
+// that exactly one does if e is mandatory.  The constraints of the children
+// of the case with data are then checked.
+func (v *validator) choice(e *Entry, path string, present map[*Entry]bool) {
+	var cases []*Entry
+	for _, c := range sortedDir(e) {
+		if c.IsCase() && !hasPresent(c, present) {
+			continue
+		}
+		if !c.IsCase() && !present[c] {
+			// A shorthand case that has not been wrapped in a case.
+			continue
+		}
+		cases = append(cases, c)
+	}
+
+	switch len(cases) {
+	case 0:
+		if e.Mandatory == TSTrue {
+			v.errorf(path, "mandatory choice %q has no case present", e.Name)
+		}
+	case 1:
+		if cases[0].IsCase() {
+			v.children(cases[0], path, present)
+		}
+	default:
+		var names []string
+		for _, c := range cases {
+			names = append(names, c.Name)
+		}
+		v.errorf(path, "choice %q has more than one case present: %s", e.Name, strings.Join(names, ", "))
+	}
+}
+
+// hasPresent reports whether any data node beneath the choice or case e is in
+// present.
+func hasPresent(e *Entry, present map[*Entry]bool) bool {
+	for _, c := range e.Dir {
+		if present[c] || ((c.IsChoice() || c.IsCase()) && hasPresent(c, present)) {
+			return true
+		}
+	}
+	return false
+}
+
+// value validates data as the value of the data node e.
+func (v *validator) value(e *Entry, path string, data interface{}) {
+	switch {
+	case e.Kind == AnyDataEntry || e.Kind == AnyXMLEntry:
+		// Any value is allowed.
+	case e.IsList():
+		items, ok := data.([]interface{})
+		if !ok {
+			v.errorf(path, "list value must be an array, got %T", data)
+			return
+		}
+		for i, item := range items {
+			ipath := fmt.Sprintf("%s[%d]", path, i)
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				v.errorf(ipath, "list entry must be an object, got %T", item)
+				continue
+			}
+			v.object(e, ipath, m)
+		}
+		v.elements(e, path, len(items))
+	case e.IsLeafList():
+		items, ok := data.([]interface{})
+		if !ok {
+			v.errorf(path, "leaf-list value must be an array, got %T", data)
+			return
+		}
+		for i, item := range items {
+			if err := e.checkValue(e.Type, item); err != nil {
+				v.errorf(fmt.Sprintf("%s[%d]", path, i), "%v", err)
+			}
+		}
+		v.elements(e, path, len(items))
+	case e.IsLeaf():
+		if err := e.checkValue(e.Type, data); err != nil {
+			v.errorf(path, "%v", err)
+		}
+	default:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			v.errorf(path, "%s value must be an object, got %T", e.Kind, data)
+			return
+		}
+		v.object(e, path, m)
+	}
+}
+
+// elements checks that n is within the min-elements and max-elements of the
+// list or leaf-list e.
+func (v *validator) elements(e *Entry, path string, n int) {
+	if e.ListAttr == nil {
+		return
+	}
+	if uint64(n) < e.ListAttr.MinElements {
+		v.errorf(path, "%q has %d elements, it must have at least %d", e.Name, n, e.ListAttr.MinElements)
+	}
+	if uint64(n) > e.ListAttr.MaxElements {
+		v.errorf(path, "%q has %d elements, it must have at most %d", e.Name, n, e.ListAttr.MaxElements)
+	}
+}
+
+// dataMembers returns a map of the JSON member names, both module qualified
+// and not, of the data children of e to the children they name.
+func dataMembers(e *Entry) map[string]*Entry {
+	m := map[string]*Entry{}
+	var add func(*Entry)
+	add = func(d *Entry) {
+		for _, c := range d.Dir {
+			switch {
+			case c.IsChoice() || c.IsCase():
+				add(c)
+			case c.Kind == NotificationEntry || c.RPC != nil || c.Node != nil && c.Node.Kind() == "action":
+				// Not part of the data tree.
+			default:
+				m[c.Name] = c
+				if mod, err := c.InstantiatingModule(); err == nil {
+					m[mod+":"+c.Name] = c
+				}
+			}
+		}
+	}
+	add(e)
+	return m
+}
+
+// sortedDir returns the children of e sorted by name.
+func sortedDir(e *Entry) []*Entry {
+	var names []string
+	for name := range e.Dir {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	children := make([]*Entry, len(names))
+	for i, name := range names {
+		children[i] = e.Dir[name]
+	}
+	return children
+}
+
+// checkValue returns an error if data, decoded from RFC7951 JSON, is not a
+// valid value of type t for the leaf or leaf-list e.
+func (e *Entry) checkValue(t *YangType, data interface{}) error {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind {
+	case Yint8, Yint16, Yint32, Yuint8, Yuint16, Yuint32:
+		// RFC7951 section 6.1 encodes these as JSON numbers.
+		s, ok := jsonNumber(data)
+		if !ok {
+			return fmt.Errorf("%s value must be a number, got %T", t.Kind, data)
+		}
+		n, err := ParseInt(s)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid %s: %v", s, t.Kind, err)
+		}
+		return checkInRange(t, n, s)
+	case Yint64, Yuint64:
+		// RFC7951 section 6.1 encodes these as strings.
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("%s value must be a string, got %T", t.Kind, data)
+		}
+		n, err := ParseInt(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s: %v", s, t.Kind, err)
+		}
+		return checkInRange(t, n, s)
+	case Ydecimal64:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("decimal64 value must be a string, got %T", data)
+		}
+		return t.ValidateDecimal64(s)
+	case Ystring:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("string value must be a string, got %T", data)
+		}
+		return checkLength(t, utf8.RuneCountInString(s), s)
+	case Ybinary:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("binary value must be a string, got %T", data)
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return fmt.Errorf("%q is not valid base64: %v", s, err)
+		}
+		return checkLength(t, len(b), s)
+	case Ybool:
+		if _, ok := data.(bool); !ok {
+			return fmt.Errorf("boolean value must be true or false, got %T", data)
+		}
+	case Yempty:
+		// RFC7951 section 6.9 encodes the empty value as [null].
+		if a, ok := data.([]interface{}); !ok || len(a) != 1 || a[0] != nil {
+			return fmt.Errorf("empty value must be [null], got %v", data)
+		}
+	case Yenum:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("enumeration value must be a string, got %T", data)
+		}
+		if t.Enum == nil || !t.Enum.IsDefined(s) {
+			return fmt.Errorf("%q is not a valid enumeration value", s)
+		}
+	case Ybits:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("bits value must be a string, got %T", data)
+		}
+		for _, b := range strings.Fields(s) {
+			if t.Bit == nil || !t.Bit.IsDefined(b) {
+				return fmt.Errorf("%q is not a valid bit", b)
+			}
+		}
+	case Yidentityref:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("identityref value must be a string, got %T", data)
+		}
+		return t.ValidateIdentityref(s, nil)
+	case Yunion:
+		for _, ut := range t.Type {
+			if e.checkValue(ut, data) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v does not match any type of the union", data)
+	case Yleafref:
+		// The value must be valid for the type of the node referenced.
+		if target, err := e.resolveLeafref(t.Path); err == nil {
+			return target.checkValue(target.Type, data)
+		}
+	case YinstanceIdentifier:
+		s, ok := data.(string)
+		if !ok {
+			return fmt.Errorf("instance-identifier value must be a string, got %T", data)
+		}
+		return t.ValidateInstanceIdentifier(s)
+	}
+	return nil
+}
+
+// jsonNumber returns the decimal representation of data, which must be a
+// number as decoded by encoding/json or a Go integer.
+func jsonNumber(data interface{}) (string, bool) {
+	switch n := data.(type) {
+	case float64:
+		if n != math.Trunc(n) {
+			return strconv.FormatFloat(n, 'f', -1, 64), true
+		}
+		return strconv.FormatFloat(n, 'f', 0, 64), true
+	case json.Number:
+		return n.String(), true
+	case int:
+		return strconv.Itoa(n), true
+	case int8:
+		return strconv.FormatInt(int64(n), 10), true
+	case int16:
+		return strconv.FormatInt(int64(n), 10), true
+	case int32:
+		return strconv.FormatInt(int64(n), 10), true
+	case int64:
+		return strconv.FormatInt(n, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(n), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(n), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(n), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(n), 10), true
+	case uint64:
+		return strconv.FormatUint(n, 10), true
+	}
+	return "", false
+}
+
+// ValidateDecimal64 returns an error if t is not a decimal64 type or value is
+// not a valid value of it.  A valid value has no more digits after its
+// decimal point than the fraction-digits of t, and is within the range of t,
+// if any.  Each is checked regardless of the other, so a value with too many
+// fraction digits is rejected even if it is within the range, and one
+// outside the range is rejected even if it has a valid number of fraction
+// digits.
+func (t *YangType) ValidateDecimal64(value string) error {
+	if t.Kind != Ydecimal64 {
+		return fmt.Errorf("type %s is not a decimal64", t.Name)
+	}
+	n, err := ParseDecimal(value, uint8(t.FractionDigits))
+	if err != nil {
+		return fmt.Errorf("%q is not a valid decimal64: %v", value, err)
+	}
+	return checkInRange(t, n, value)
+}
+
+// checkInRange returns an error if n, whose text representation is s, is not
+// within the range of t.
+func checkInRange(t *YangType, n Number, s string) error {
+	if len(t.Range) > 0 && !t.Range.Contains(YangRange{{n, n}}) {
+		return fmt.Errorf("%s is outside the range %v", s, t.Range)
+	}
+	return nil
+}
+
+// checkLength returns an error if l, the length of value s, is not within the
+// length of t.
+func checkLength(t *YangType, l int, s string) error {
+	if len(t.Length) > 0 && !t.Length.Contains(YangRange{{FromInt(int64(l)), FromInt(int64(l))}}) {
+		return fmt.Errorf("length of %q is %d, outside the length %v", s, l, t.Length)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+const validateTestModule = `
+module val {
+	prefix "v";
+	namespace "urn:v";
+
+	identity base-id;
+	identity derived-id { base base-id; }
+
+	typedef code {
+		type uint8;
+		default 0;
+	}
+
+	container top {
+		choice transport {
+			mandatory true;
+			case tcp {
+				leaf tcp-port { type uint16 { range "1..65535"; } }
+			}
+			case udp {
+				leaf udp-port { type uint16; }
+				leaf udp-checksum { type boolean; }
+			}
+		}
+		choice optional {
+			leaf alpha { type string; mandatory true; }
+			leaf beta { type string; }
+		}
+	}
+
+	container types {
+		leaf i8 { type int8 { range "-10..10"; } }
+		leaf i64 { type int64; }
+		leaf dec { type decimal64 { fraction-digits 2; } }
+		leaf str { type string { length "1..4"; } }
+		leaf color { type enumeration { enum red; enum blue; } }
+		leaf flags { type bits { bit a; bit b; } }
+		leaf present { type empty; }
+		leaf id { type identityref { base base-id; } }
+		leaf either { type union { type int8; type enumeration { enum none; } } }
+		leaf ref { type leafref { path "../i8"; } }
+		leaf bin { type binary; }
+		leaf iid { type instance-identifier; }
+	}
+
+	container codes {
+		leaf-list required { type code; min-elements 1; }
+		leaf-list optional { type code; }
+	}
+
+	list item {
+		key "name";
+		min-elements 1;
+		max-elements 2;
+		leaf name { type string; }
+		leaf value { type uint32; mandatory true; }
+		leaf-list tag { type string; max-elements 1; }
+	}
+}
+`
+
+func TestValidate(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(validateTestModule, "val.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["val"])
+
+	// validItem is an item list that satisfies its constraints.
+	const validItem = `"item": [{"name": "a", "value": 1}]`
+
+	tests := []struct {
+		desc     string
+		inData   string
+		wantErrs []string
+	}{{
+		desc:   "valid data",
+		inData: `{"val:top": {"tcp-port": 80}, ` + validItem + `}`,
+	}, {
+		desc:   "valid data in the second case of a choice",
+		inData: `{"top": {"udp-port": 53, "udp-checksum": true, "beta": "b"}, ` + validItem + `}`,
+	}, {
+		desc:     "mandatory choice with no case",
+		inData:   `{"top": {}, ` + validItem + `}`,
+		wantErrs: []string{`/top: mandatory choice "transport" has no case present`},
+	}, {
+		desc:     "mandatory choice with two cases",
+		inData:   `{"top": {"tcp-port": 80, "udp-port": 53}, ` + validItem + `}`,
+		wantErrs: []string{`/top: choice "transport" has more than one case present: tcp, udp`},
+	}, {
+		desc:     "non-mandatory choice with two cases",
+		inData:   `{"top": {"tcp-port": 80, "alpha": "a", "beta": "b"}, ` + validItem + `}`,
+		wantErrs: []string{`/top: choice "optional" has more than one case present: alpha, beta`},
+	}, {
+		desc:   "mandatory leaf in a case that is not selected",
+		inData: `{"top": {"tcp-port": 80, "beta": "b"}, ` + validItem + `}`,
+	}, {
+		desc: "list constraints",
+		inData: `{"item": [
+			{"name": "a"},
+			{"value": 2, "tag": ["x", "y"]},
+			{"name": "c", "value": 3}
+		], "top": {"tcp-port": 1}}`,
+		wantErrs: []string{
+			`/item: "item" has 3 elements, it must have at most 2`,
+			`/item[0]: missing mandatory leaf "value"`,
+			`/item[1]: missing key "name"`,
+			`/item[1]/tag: "tag" has 2 elements, it must have at most 1`,
+		},
+	}, {
+		desc:     "missing list",
+		inData:   `{"top": {"tcp-port": 1}}`,
+		wantErrs: []string{`/: "item" has 0 elements, it must have at least 1`},
+	}, {
+		desc:   "leaf-lists with min-elements and type defaults",
+		inData: `{"top": {"tcp-port": 1}, ` + validItem + `, "codes": {"required": [1]}}`,
+	}, {
+		desc:     "type default does not satisfy min-elements",
+		inData:   `{"top": {"tcp-port": 1}, ` + validItem + `, "codes": {}}`,
+		wantErrs: []string{`/codes: "required" has 0 elements, it must have at least 1`},
+	}, {
+		desc: "valid types",
+		inData: `{"top": {"tcp-port": 1}, ` + validItem + `, "types": {
+			"i8": -10,
+			"i64": "-9223372036854775808",
+			"dec": "3.14",
+			"str": "four",
+			"color": "blue",
+			"flags": "a b",
+			"present": [null],
+			"id": "val:derived-id",
+			"either": "none",
+			"ref": 4,
+			"bin": "AQID",
+			"iid": "/val:item[name='a']/value"
+		}}`,
+	}, {
+		desc: "invalid types",
+		inData: `{"top": {"tcp-port": 0}, ` + validItem + `, "unknown": 1, "types": {
+			"i8": 11,
+			"i64": 1,
+			"dec": "3.141",
+			"str": "",
+			"color": "green",
+			"flags": "a c",
+			"present": null,
+			"id": "base-id",
+			"either": "some",
+			"ref": "x",
+			"bin": "!",
+			"iid": "val:item"
+		}}`,
+		wantErrs: []string{
+			`/: unknown member "unknown"`,
+			`/top/tcp-port: 0 is outside the range 1..65535`,
+			`/types/bin: "!" is not valid base64: illegal base64 data at input byte 0`,
+			`/types/color: "green" is not a valid enumeration value`,
+			`/types/dec: "3.141" is not a valid decimal64: 3141 has too much precision, expect <= 2 fractional digits`,
+			`/types/either: some does not match any type of the union`,
+			`/types/flags: "c" is not a valid bit`,
+			`/types/i64: int64 value must be a string, got float64`,
+			`/types/i8: 11 is outside the range -10..10`,
+			`/types/id: "base-id" is not a valid identity: it is the base val:base-id, not derived from it`,
+			`/types/iid: instance-identifier "val:item": offset 0: expected "/"`,
+			`/types/present: empty value must be [null], got <nil>`,
+			`/types/ref: int8 value must be a number, got string`,
+			`/types/str: length of "" is 0, outside the length 1..4`,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.inData), &data); err != nil {
+				t.Fatalf("cannot unmarshal test data: %v", err)
+			}
+			var got []string
+			for _, err := range mod.Validate(data) {
+				got = append(got, err.Error())
+			}
+			if diff := cmp.Diff(tt.wantErrs, got); diff != "" {
+				t.Errorf("Validate (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateDecimal64(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dec {
+			prefix "d";
+			namespace "urn:d";
+
+			leaf ranged {
+				type decimal64 {
+					fraction-digits 2;
+					range "1..2";
+				}
+			}
+			leaf unranged { type decimal64 { fraction-digits 2; } }
+			leaf str { type string; }
+		}`, "dec.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	mod := ToEntry(ms.Modules["dec"])
+
+	tests := []struct {
+		desc             string
+		inLeaf           string
+		inValue          string
+		wantErrSubstring string
+	}{{
+		desc:    "valid value",
+		inLeaf:  "ranged",
+		inValue: "1.25",
+	}, {
+		desc:    "fewer fraction digits",
+		inLeaf:  "ranged",
+		inValue: "2",
+	}, {
+		desc:             "too many fraction digits within the range",
+		inLeaf:           "ranged",
+		inValue:          "1.234",
+		wantErrSubstring: `"1.234" is not a valid decimal64: 1234 has too much precision`,
+	}, {
+		desc:             "valid fraction digits outside the range",
+		inLeaf:           "ranged",
+		inValue:          "2.5",
+		wantErrSubstring: "2.5 is outside the range 1.00..2.00",
+	}, {
+		desc:             "too many fraction digits outside the range",
+		inLeaf:           "ranged",
+		inValue:          "2.501",
+		wantErrSubstring: "too much precision",
+	}, {
+		desc:             "too many fraction digits without a range",
+		inLeaf:           "unranged",
+		inValue:          "1.234",
+		wantErrSubstring: "too much precision",
+	}, {
+		desc:    "no range",
+		inLeaf:  "unranged",
+		inValue: "-1000.5",
+	}, {
+		desc:             "not a number",
+		inLeaf:           "ranged",
+		inValue:          "one",
+		wantErrSubstring: `"one" is not a valid decimal64`,
+	}, {
+		desc:             "not a decimal64",
+		inLeaf:           "str",
+		inValue:          "1.5",
+		wantErrSubstring: "type string is not a decimal64",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := mod.Dir[tt.inLeaf].Type.ValidateDecimal64(tt.inValue)
+			if diff := errdiff.Substring(err, tt.wantErrSubstring); diff != "" {
+				t.Errorf("ValidateDecimal64(%q): %s", tt.inValue, diff)
+			}
+		})
+	}
+}
+
+func TestChoiceMandatory(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(validateTestModule, "val.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process module: %v", errs)
+	}
+	top := ToEntry(ms.Modules["val"]).Dir["top"]
+	for name, want := range map[string]TriState{
+		"transport": TSTrue,
+		"optional":  TSUnset,
+	} {
+		if got := top.Dir[name].Mandatory; got != want {
+			t.Errorf("choice %s: got Mandatory %v, want %v", name, got, want)
+		}
+	}
+}
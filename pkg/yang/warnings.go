@@ -0,0 +1,184 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements checks for constructs that are legal YANG but are
+// likely to be mistakes in the model.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warnings returns the problems found in the schema trees of the modules in
+// ms that do not prevent the modules from being used, but which probably
+// indicate a broken model.  The warnings are sorted.  Warnings must be called
+// after a successful call to Process.
+func (ms *Modules) Warnings() []error {
+	var errs []error
+	for _, name := range ms.moduleNames() {
+		errs = appendWarnings(errs, ToEntry(ms.Modules[name]))
+	}
+	return errorSort(errs)
+}
+
+// moduleNames returns the sorted names of the modules in ms, excluding the
+// revision qualified names that modules are also stored under and the
+// modules that Process found errors in.
+func (ms *Modules) moduleNames() []string {
+	var names []string
+	for name, m := range ms.Modules {
+		if name == m.Name && !ms.hasFailed(m) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appendWarnings appends the warnings for e and its descendants to errs,
+// returning the result.
+func appendWarnings(errs []error, e *Entry) []error {
+	if e == nil {
+		return errs
+	}
+	if e.IsList() {
+		errs = append(errs, e.keyWarnings()...)
+	}
+	if err := e.augmentWarning(); err != nil {
+		errs = append(errs, err)
+	}
+	if e.Type != nil {
+		for _, t := range leafrefTypes(e.Type) {
+			errs = append(errs, e.leafrefListWarnings(t.Path)...)
+		}
+	}
+	if e.RPC != nil {
+		errs = appendWarnings(errs, e.RPC.Input)
+		errs = appendWarnings(errs, e.RPC.Output)
+	}
+	for _, c := range e.Dir {
+		errs = appendWarnings(errs, c)
+	}
+	return errs
+}
+
+// keyWarnings returns a warning for each key leaf of the list e that is
+// conditional on a when statement.  An instance of the list cannot exist
+// without its keys, so a key that may be absent makes the list unusable
+// whenever the condition is false.
+func (e *Entry) keyWarnings() []error {
+	var errs []error
+	for _, k := range strings.Fields(e.Key) {
+		ke := e.Dir[k]
+		if ke == nil {
+			continue
+		}
+		if ws := ke.WhenConditions(); len(ws) > 0 {
+			errs = append(errs, fmt.Errorf("%s: key %q of list %s is conditional on when %q and may be absent", Source(ke.Node), k, e.Path(), ws[0].Expr))
+		}
+	}
+	return errs
+}
+
+// augmentWarning returns a warning if e is a mandatory node representing
+// configuration that was added to a node of another module by an augment
+// without a when statement, and nil otherwise.  The existing configuration of
+// the other module does not have e, so RFC7950 section 7.17 requires such an
+// augment to be conditional.
+func (e *Entry) augmentWarning() error {
+	a := e.augmentedBy
+	if a == nil || e.Parent == nil || len(a.Extra["when"]) > 0 || !e.isDataNode() || e.ReadOnly() || !e.isMandatoryNode() {
+		return nil
+	}
+	ns, pns := e.Namespace(), e.Parent.Namespace()
+	if ns == nil || pns == nil || ns.Name == pns.Name {
+		return nil
+	}
+	return fmt.Errorf("%s: augment %s adds mandatory %s %s to another module without a when statement", Source(a.Node), a.Name, compareKind(e), e.Path())
+}
+
+// leafrefListWarnings returns a warning for each list that the leafref path,
+// evaluated with e as the context node, descends into without a predicate
+// selecting the list entry.  Such a path refers to the node in every entry of
+// the list, which is rarely intended.  Referring to a key of the last list in
+// the path, e.g., /interfaces/interface/name, is the usual way of referring
+// to an entry of the list and so is not reported.  Process does not check
+// that leafref paths can be resolved, so a path that cannot be is reported
+// as a warning instead.
+func (e *Entry) leafrefListWarnings(path string) []error {
+	target, err := e.resolveLeafref(path)
+	if err != nil {
+		return []error{err}
+	}
+	steps := splitPathSteps(path)
+	cur := e
+	if steps[0] == "" {
+		steps = steps[1:]
+		prefix, _ := getPrefix(strings.TrimSpace(stripPredicates(steps[0])))
+		cur = ToEntry(module(FindModuleByPrefix(e.Node, prefix)))
+	}
+	var errs []error
+	for i, step := range steps {
+		_, name := getPrefix(strings.TrimSpace(stripPredicates(step)))
+		switch name {
+		case ".":
+			continue
+		case "..":
+			for cur = cur.Parent; cur.IsChoice() || cur.IsCase(); cur = cur.Parent {
+			}
+			continue
+		}
+		cur = cur.dataChild(name)
+		if !cur.IsList() || strings.Contains(step, "[") {
+			continue
+		}
+		if i == len(steps)-2 && target.Parent != nil && cur.isKey(target.Name) {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: leafref path %q of %s descends into list %s without a predicate selecting an entry", Source(e.Node), path, e.Path(), cur.Path()))
+	}
+	return errs
+}
+
+// isKey reports whether name is a key of the list e.
+func (e *Entry) isKey(name string) bool {
+	for _, k := range strings.Fields(e.Key) {
+		if k == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPathSteps splits path at each "/" that is not within a predicate.
+// Each step retains its predicates.
+func splitPathSteps(path string) []string {
+	var steps []string
+	depth, start := 0, 0
+	for i, r := range path {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case r == '/' && depth == 0:
+			steps = append(steps, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(steps, path[start:])
+}
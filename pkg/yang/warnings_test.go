@@ -0,0 +1,319 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+func TestKeyWarnings(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inModule         string
+		wantErrSubstring []string
+	}{{
+		desc: "unconditional keys",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf enabled { type boolean; }
+				list l {
+					key "a b";
+					when "../enabled = 'true'";
+					leaf a { type string; }
+					leaf b { type string; }
+					leaf c {
+						when "../a = 'x'";
+						type string;
+					}
+				}
+			}`,
+	}, {
+		desc: "key with a when statement",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				list l {
+					key "a b";
+					leaf a { type string; }
+					leaf b {
+						when "../a = 'x'";
+						type string;
+					}
+				}
+				container c {
+					list inner {
+						key "k";
+						leaf k {
+							when "../../c";
+							type string;
+						}
+					}
+				}
+			}`,
+		wantErrSubstring: []string{
+			`key "b" of list /dev/l is conditional on when "../a = 'x'"`,
+			`key "k" of list /dev/c/inner is conditional on when "../../c"`,
+		},
+	}, {
+		desc: "key under a when in an rpc",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				rpc r {
+					input {
+						list l {
+							key "a";
+							leaf a {
+								when "true()";
+								type string;
+							}
+						}
+					}
+				}
+			}`,
+		wantErrSubstring: []string{
+			`key "a" of list /dev/r/input/l is conditional`,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(tt.inModule, "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			if errs := ms.Process(); len(errs) != 0 {
+				t.Fatalf("cannot process modules: %v", errs)
+			}
+			warnings := ms.Warnings()
+			if len(warnings) != len(tt.wantErrSubstring) {
+				t.Fatalf("got %d warnings (%v), want %d", len(warnings), warnings, len(tt.wantErrSubstring))
+			}
+			for i, w := range warnings {
+				if diff := errdiff.Substring(w, tt.wantErrSubstring[i]); diff != "" {
+					t.Errorf("warning %d: %s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLeafrefListWarnings(t *testing.T) {
+	tests := []struct {
+		desc             string
+		inModule         string
+		wantErrSubstring []string
+	}{{
+		desc: "leafrefs selecting list entries",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				container interfaces {
+					list interface {
+						key "name";
+						leaf name { type string; }
+						leaf mtu { type uint16; }
+						list subinterface {
+							key "index";
+							leaf index { type uint32; }
+						}
+					}
+				}
+				leaf ifname {
+					type leafref { path "/d:interfaces/d:interface/d:name"; }
+				}
+				leaf ifmtu {
+					type leafref {
+						path "/interfaces/interface[name = current()/../ifname]/mtu";
+					}
+				}
+				leaf subif {
+					type leafref {
+						path "/interfaces/interface[name = current()/../ifname]/subinterface/index";
+					}
+				}
+				container local {
+					leaf up {
+						type leafref { path "../../ifname"; }
+					}
+				}
+			}`,
+	}, {
+		desc: "leafrefs through lists without predicates",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				container interfaces {
+					list interface {
+						key "name";
+						leaf name { type string; }
+						leaf mtu { type uint16; }
+						list subinterface {
+							key "index";
+							leaf index { type uint32; }
+						}
+					}
+				}
+				leaf mtu {
+					type leafref { path "/interfaces/interface/mtu"; }
+				}
+				leaf subif {
+					type union {
+						type string;
+						type leafref { path "../interfaces/interface/subinterface/index"; }
+					}
+				}
+			}`,
+		wantErrSubstring: []string{
+			`leafref path "/interfaces/interface/mtu" of /dev/mtu descends into list /dev/interfaces/interface without a predicate`,
+			`leafref path "../interfaces/interface/subinterface/index" of /dev/subif descends into list /dev/interfaces/interface without a predicate`,
+		},
+	}, {
+		desc: "unresolvable leafref",
+		inModule: `
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+
+				leaf name { type string; }
+				leaf ref {
+					type leafref { path "../nmae"; }
+				}
+			}`,
+		wantErrSubstring: []string{
+			`dev.yang:7:5: leafref path "../nmae": nmae not found in /dev`,
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(tt.inModule, "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			if errs := ms.Process(); len(errs) != 0 {
+				t.Fatalf("cannot process modules: %v", errs)
+			}
+			warnings := ms.Warnings()
+			if len(warnings) != len(tt.wantErrSubstring) {
+				t.Fatalf("got %d warnings (%v), want %d", len(warnings), warnings, len(tt.wantErrSubstring))
+			}
+			for i, w := range warnings {
+				if diff := errdiff.Substring(w, tt.wantErrSubstring[i]); diff != "" {
+					t.Errorf("warning %d: %s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAugmentWarnings(t *testing.T) {
+	const base = `
+		module base {
+			prefix "b";
+			namespace "urn:b";
+
+			container c { }
+			container s { config false; }
+		}`
+	tests := []struct {
+		desc             string
+		inModule         string
+		wantErrSubstring []string
+	}{{
+		desc: "optional nodes",
+		inModule: `
+			augment /b:c {
+				leaf a { type string; }
+				list l { key k; leaf k { type string; } }
+				container p { presence "p"; leaf m { type string; mandatory true; } }
+			}`,
+	}, {
+		desc: "mandatory nodes",
+		inModule: `
+			augment /b:c {
+				leaf a { type string; mandatory true; }
+				leaf-list ll { type string; min-elements 1; }
+				container np { leaf m { type string; mandatory true; } }
+			}`,
+		wantErrSubstring: []string{
+			"dev.yang:7:4: augment /b:c adds mandatory container /base/c/np to another module",
+			"dev.yang:7:4: augment /b:c adds mandatory leaf /base/c/a to another module without a when statement",
+			"dev.yang:7:4: augment /b:c adds mandatory leaf-list /base/c/ll to another module",
+		},
+	}, {
+		desc: "conditional augment",
+		inModule: `
+			augment /b:c {
+				when "false()";
+				leaf a { type string; mandatory true; }
+			}`,
+	}, {
+		desc: "state data",
+		inModule: `
+			augment /b:s {
+				leaf a { type string; mandatory true; }
+			}`,
+	}, {
+		desc: "augment of the module's own node",
+		inModule: `
+			container own { }
+			augment /own {
+				leaf a { type string; mandatory true; }
+			}`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ms := NewModules()
+			if err := ms.Parse(base, "base.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			if err := ms.Parse(`
+			module dev {
+				prefix "d";
+				namespace "urn:d";
+				import base { prefix b; }
+			`+tt.inModule+` }`, "dev.yang"); err != nil {
+				t.Fatalf("cannot parse module: %v", err)
+			}
+			if errs := ms.Process(); len(errs) != 0 {
+				t.Fatalf("cannot process modules: %v", errs)
+			}
+			warnings := ms.Warnings()
+			if len(warnings) != len(tt.wantErrSubstring) {
+				t.Fatalf("got %d warnings (%v), want %d", len(warnings), warnings, len(tt.wantErrSubstring))
+			}
+			for i, w := range warnings {
+				if diff := errdiff.Substring(w, tt.wantErrSubstring[i]); diff != "" {
+					t.Errorf("warning %d: %s", i, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,492 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+// This file implements the encoding and decoding of instance data as XML, as
+// used by NETCONF and described in RFC7950 sections 7 and 9.  The data tree
+// is the one used by Validate and EncodeJSON, so data decoded from XML may be
+// encoded as JSON and vice versa.
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DecodeXML decodes the XML instance data of the children of e read from r,
+// which is normally the content of a NETCONF <data> or <config> element.  The
+// elements may also be enclosed in a single element that does not name a
+// child of e, such as the <data> or <config> element itself, which is
+// ignored.  Elements are matched to the children of e by their name and
+// namespace.
+//
+// The data is returned in the form used by EncodeJSON and Validate, with
+// member names qualified as returned by JSONName and the values of leaves and
+// leaf-lists typed according to their schema and encoded as in RFC7951.  The
+// prefixes of identityref and instance-identifier values are replaced by the
+// names of the modules whose namespaces they are bound to.  The content of
+// anydata and anyxml nodes is returned as an object of the names of its
+// elements to their text, or content if they have child elements.  An error,
+// prefixed with the path in the data at which it was found, is returned for
+// the first unknown element or invalid value.
+func (e *Entry) DecodeXML(r io.Reader) (map[string]interface{}, error) {
+	root, err := readXML(r)
+	if err != nil {
+		return nil, err
+	}
+	nodes := root.children
+	if len(nodes) == 1 && xmlMembers(e)[nodes[0].name] == nil {
+		nodes = nodes[0].children
+	}
+	return decodeXMLObject(e, "", nodes)
+}
+
+// EncodeXML writes data, the instance data of the children of e in the form
+// accepted by EncodeJSON, to w as XML.  The values of data are converted and
+// checked as they are by EncodeJSON.  Each element declares its namespace if
+// it differs from that of its parent, and the elements of a container or list
+// entry are written in the order they are defined in the schema, with the
+// keys of a list entry first.  The prefixes of identityref and
+// instance-identifier values are the names of the modules they refer to.
+func (e *Entry) EncodeXML(w io.Writer, data map[string]interface{}) error {
+	m, err := encodeObject(e, "", data)
+	if err != nil {
+		return err
+	}
+	enc := &xmlEncoder{w: w, ms: e.Modules()}
+	enc.object(e, "", m)
+	return enc.err
+}
+
+// An xmlNode is an element read by readXML.
+type xmlNode struct {
+	name     xml.Name          // the element name, Space is its namespace
+	ns       map[string]string // the namespace bindings in scope, by prefix
+	text     string            // the character data of the element
+	children []*xmlNode
+}
+
+// readXML returns a node holding the top level elements read from r.
+func readXML(r io.Reader) (*xmlNode, error) {
+	d := xml.NewDecoder(r)
+	root := &xmlNode{ns: map[string]string{}}
+	stack := []*xmlNode{root}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name, ns: map[string]string{}}
+			for p, ns := range top.ns {
+				n.ns[p] = ns
+			}
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "xmlns":
+					n.ns[a.Name.Local] = a.Value
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					n.ns[""] = a.Value
+				}
+			}
+			top.children = append(top.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			top.text += string(t)
+		}
+	}
+	return root, nil
+}
+
+// xmlMembers returns a map of the element names of the data children of e to
+// the children they name.
+func xmlMembers(e *Entry) map[xml.Name]*Entry {
+	m := map[xml.Name]*Entry{}
+	for _, c := range dataMembers(e) {
+		m[xml.Name{Space: c.Namespace().Name, Local: c.Name}] = c
+	}
+	return m
+}
+
+// decodeXMLObject returns the data held by nodes, the elements of the children
+// of e.
+func decodeXMLObject(e *Entry, path string, nodes []*xmlNode) (map[string]interface{}, error) {
+	members := xmlMembers(e)
+	m := map[string]interface{}{}
+	for _, n := range nodes {
+		c := members[n.name]
+		if c == nil {
+			return nil, encodeError(path, "unknown element %s in namespace %q", n.name.Local, n.name.Space)
+		}
+		cpath := path + "/" + c.Name
+		name := c.JSONName()
+		switch {
+		case c.IsList():
+			items, _ := m[name].([]interface{})
+			v, err := decodeXMLObject(c, fmt.Sprintf("%s[%d]", cpath, len(items)), n.children)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = append(items, v)
+			continue
+		case c.IsLeafList():
+			items, _ := m[name].([]interface{})
+			v, err := c.decodeXMLValue(c.Type, n)
+			if err != nil {
+				return nil, encodeError(fmt.Sprintf("%s[%d]", cpath, len(items)), "%v", err)
+			}
+			m[name] = append(items, v)
+			continue
+		}
+		if _, ok := m[name]; ok {
+			return nil, encodeError(path, "element %s specified more than once", c.Name)
+		}
+		switch {
+		case c.Kind == AnyDataEntry || c.Kind == AnyXMLEntry:
+			m[name] = xmlAny(n)
+		case c.IsLeaf():
+			v, err := c.decodeXMLValue(c.Type, n)
+			if err != nil {
+				return nil, encodeError(cpath, "%v", err)
+			}
+			m[name] = v
+		default:
+			v, err := decodeXMLObject(c, cpath, n.children)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = v
+		}
+	}
+	return m, nil
+}
+
+// xmlAny returns the content of the anydata or anyxml element n as an object
+// of the names of its child elements to their values, or as its text if it
+// has no child elements.  Repeated elements are returned as an array.
+func xmlAny(n *xmlNode) interface{} {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	m := map[string]interface{}{}
+	for _, c := range n.children {
+		v := xmlAny(c)
+		switch prev := m[c.name.Local].(type) {
+		case nil:
+			m[c.name.Local] = v
+		case []interface{}:
+			m[c.name.Local] = append(prev, v)
+		default:
+			m[c.name.Local] = []interface{}{prev, v}
+		}
+	}
+	return m
+}
+
+// decodeXMLValue returns the value of type t for the leaf or leaf-list e held
+// by the element n, encoded as in RFC7951 and checked to be valid.
+func (e *Entry) decodeXMLValue(t *YangType, n *xmlNode) (interface{}, error) {
+	if t == nil {
+		return n.text, nil
+	}
+	text := strings.TrimSpace(n.text)
+	var v interface{} = text
+	switch t.Kind {
+	case Yunion:
+		for _, ut := range t.Type {
+			if v, err := e.decodeXMLValue(ut, n); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%q does not match any type of the union", text)
+	case Yleafref:
+		// The value is a value of the node referenced.
+		if target, err := e.resolveLeafref(t.Path); err == nil {
+			return target.decodeXMLValue(target.Type, n)
+		}
+		return text, nil
+	case Yint8, Yint16, Yint32, Yuint8, Yuint16, Yuint32:
+		v = json.Number(text)
+	case Ystring:
+		v = n.text
+	case Ybool:
+		switch text {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		}
+	case Yempty:
+		if text != "" || len(n.children) > 0 {
+			return nil, fmt.Errorf("empty value must have no content, got %q", text)
+		}
+		v = []interface{}{nil}
+	case Ybits:
+		v = strings.Join(strings.Fields(text), " ")
+	case Yidentityref, YinstanceIdentifier:
+		// The prefixes are bound to namespaces by the element, and are
+		// replaced by the names of the modules with those namespaces.
+		s, err := mapPrefixes(text, func(prefix string) (string, error) {
+			ns, ok := n.ns[prefix]
+			if !ok {
+				return "", fmt.Errorf("%q: prefix %s is not bound to a namespace", text, prefix)
+			}
+			m, err := e.Modules().FindModuleByNamespace(ns)
+			if err != nil {
+				return "", err
+			}
+			return m.Name, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind == Yidentityref && !strings.Contains(s, ":") {
+			// An unprefixed identity is in the default namespace.
+			if m, err := e.Modules().FindModuleByNamespace(n.ns[""]); err == nil {
+				s = m.Name + ":" + s
+			}
+		}
+		v = s
+	}
+	if err := e.checkValue(t, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// mapPrefixes returns s, an identityref or instance-identifier value, with
+// each prefix p replaced by f(p).  Quoted strings in s, i.e., the values of
+// predicates, are not changed.
+func mapPrefixes(s string, f func(string) (string, error)) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String(), nil
+			}
+			b.WriteString(s[i : i+end+2])
+			i += end + 2
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			if j < len(s) && s[j] == ':' {
+				p, err := f(s[i:j])
+				if err != nil {
+					return "", err
+				}
+				b.WriteString(p)
+			} else {
+				b.WriteString(s[i:j])
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// isIdentStart reports whether c may start a YANG identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isIdentChar reports whether c may appear in a YANG identifier.
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || ('0' <= c && c <= '9') || c == '-' || c == '.'
+}
+
+// An xmlEncoder writes instance data as XML, recording the first error.
+type xmlEncoder struct {
+	w   io.Writer
+	ms  *Modules
+	err error
+}
+
+func (x *xmlEncoder) printf(format string, args ...interface{}) {
+	if x.err == nil {
+		_, x.err = fmt.Fprintf(x.w, format, args...)
+	}
+}
+
+// xmlEscape returns s escaped for use as XML character data or an attribute.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// object writes the elements of m, the data of the children of e encoded by
+// encodeObject, whose element is in the namespace ns.
+func (x *xmlEncoder) object(e *Entry, ns string, m map[string]interface{}) {
+	for _, c := range xmlChildren(e) {
+		v, ok := m[c.JSONName()]
+		if !ok {
+			continue
+		}
+		var items []interface{}
+		if c.IsList() || c.IsLeafList() {
+			items, _ = v.([]interface{})
+		} else {
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			x.element(c, ns, item)
+		}
+	}
+}
+
+// element writes the element of the data node c holding v.  ns is the
+// namespace of the parent element.
+func (x *xmlEncoder) element(c *Entry, ns string, v interface{}) {
+	attrs := ""
+	cns := c.Namespace().Name
+	if cns != ns {
+		attrs = fmt.Sprintf(` xmlns="%s"`, xmlEscape(cns))
+	}
+	switch {
+	case c.Kind == AnyDataEntry || c.Kind == AnyXMLEntry:
+		x.printf("<%s%s>", c.Name, attrs)
+		x.anyData(v)
+	case c.IsLeaf() || c.IsLeafList():
+		text, prefixes := c.xmlText(c.Type, v)
+		for _, p := range prefixes {
+			if m := x.ms.Modules[p]; m != nil && m.Namespace != nil {
+				attrs += fmt.Sprintf(` xmlns:%s="%s"`, p, xmlEscape(m.Namespace.Name))
+			}
+		}
+		if text == "" {
+			x.printf("<%s%s/>", c.Name, attrs)
+			return
+		}
+		x.printf("<%s%s>%s", c.Name, attrs, xmlEscape(text))
+	default:
+		x.printf("<%s%s>", c.Name, attrs)
+		m, _ := v.(map[string]interface{})
+		x.object(c, cns, m)
+	}
+	x.printf("</%s>", c.Name)
+}
+
+// anyData writes v, the value of an anydata or anyxml node, as XML.  Objects are
+// written as elements named by their members, and arrays as repeated
+// elements.
+func (x *xmlEncoder) anyData(v interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		if v != nil {
+			x.printf("%s", xmlEscape(fmt.Sprint(v)))
+		}
+		return
+	}
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items, ok := m[name].([]interface{})
+		if !ok {
+			items = []interface{}{m[name]}
+		}
+		for _, item := range items {
+			x.printf("<%s>", name)
+			x.anyData(item)
+			x.printf("</%s>", name)
+		}
+	}
+}
+
+// xmlChildren returns the data children of e, with the children of choices
+// and cases in their place, in the order they are defined in the schema.  The
+// keys of a list come first, in the order of its key statement.
+func xmlChildren(e *Entry) []*Entry {
+	var children []*Entry
+	keys := map[string]bool{}
+	if e.IsList() {
+		for _, k := range strings.Fields(e.Key) {
+			if kc := e.Dir[k]; kc != nil {
+				keys[k] = true
+				children = append(children, kc)
+			}
+		}
+	}
+	var add func(e *Entry)
+	add = func(e *Entry) {
+		for _, c := range declaredChildren(e) {
+			switch {
+			case c.IsChoice() || c.IsCase():
+				add(c)
+			case c.Kind == NotificationEntry || c.RPC != nil || c.Kind == InputEntry || c.Kind == OutputEntry:
+			case !keys[c.Name]:
+				children = append(children, c)
+			}
+		}
+	}
+	add(e)
+	return children
+}
+
+// xmlText returns the text of v, a value of type t for the leaf or leaf-list
+// e encoded by EncodeJSON, and the module names used as prefixes in it, which
+// must be bound to their namespaces.
+func (e *Entry) xmlText(t *YangType, v interface{}) (string, []string) {
+	if t != nil {
+		switch t.Kind {
+		case Yunion:
+			for _, ut := range t.Type {
+				if e.checkValue(ut, v) == nil {
+					return e.xmlText(ut, v)
+				}
+			}
+		case Yleafref:
+			if target, err := e.resolveLeafref(t.Path); err == nil {
+				return target.xmlText(target.Type, v)
+			}
+		case Yempty:
+			return "", nil
+		case Yidentityref, YinstanceIdentifier:
+			s, _ := v.(string)
+			seen := map[string]bool{}
+			var prefixes []string
+			mapPrefixes(s, func(p string) (string, error) {
+				if !seen[p] {
+					seen[p] = true
+					prefixes = append(prefixes, p)
+				}
+				return p, nil
+			})
+			return s, prefixes
+		}
+	}
+	return fmt.Sprint(v), nil
+}
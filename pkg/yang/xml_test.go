@@ -0,0 +1,211 @@
+// Copyright 2021 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yang
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/errdiff"
+)
+
+// xmlTestModules returns the val module of validateTestModule, augmented by
+// a second module.
+func xmlTestModules(t *testing.T) *Entry {
+	t.Helper()
+	ms := NewModules()
+	if err := ms.Parse(validateTestModule, "val.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if err := ms.Parse(`
+		module ext {
+			prefix "e";
+			namespace "urn:e";
+			import val { prefix v; }
+			identity ext-id { base v:base-id; }
+			augment /v:types { leaf extra { type uint64; } }
+		}`, "ext.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	return ToEntry(ms.Modules["val"])
+}
+
+func TestEncodeXML(t *testing.T) {
+	mod := xmlTestModules(t)
+	data := map[string]interface{}{
+		"top": map[string]interface{}{"udp-port": 53, "udp-checksum": true},
+		"types": map[string]interface{}{
+			"str":     "a<b",
+			"i64":     int64(-5),
+			"present": nil,
+			"id":      "ext-id",
+			"either":  "none",
+			"iid":     "/val:top/val:udp-port",
+			"extra":   uint64(7),
+		},
+		"item": []interface{}{
+			map[string]interface{}{"value": 2, "name": "x", "tag": []string{"t1"}},
+			map[string]interface{}{"name": "y", "value": 3},
+		},
+	}
+	var b bytes.Buffer
+	if err := mod.EncodeXML(&b, data); err != nil {
+		t.Fatalf("EncodeXML: %v", err)
+	}
+	want := `<top xmlns="urn:v"><udp-port>53</udp-port><udp-checksum>true</udp-checksum></top>` +
+		`<types xmlns="urn:v"><extra xmlns="urn:e">7</extra><i64>-5</i64><str>a&lt;b</str><present/>` +
+		`<id xmlns:ext="urn:e">ext:ext-id</id><either>none</either>` +
+		`<iid xmlns:val="urn:v">/val:top/val:udp-port</iid></types>` +
+		`<item xmlns="urn:v"><name>x</name><value>2</value><tag>t1</tag></item>` +
+		`<item xmlns="urn:v"><name>y</name><value>3</value></item>`
+	if got := b.String(); got != want {
+		t.Errorf("EncodeXML: got\n%s\nwant\n%s", got, want)
+	}
+
+	// Decoding the XML must return the data EncodeJSON encodes.
+	got, err := mod.DecodeXML(&b)
+	if err != nil {
+		t.Fatalf("DecodeXML: %v", err)
+	}
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := mod.EncodeJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("DecodeXML of EncodeXML: got\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+
+	if err := mod.EncodeXML(&b, map[string]interface{}{"types": map[string]interface{}{"i8": 11}}); err == nil {
+		t.Errorf("EncodeXML of an invalid value: got no error")
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	mod := xmlTestModules(t)
+	tests := []struct {
+		desc    string
+		in      string
+		want    string
+		wantErr string
+	}{{
+		desc: "NETCONF data element with other prefixes",
+		in: `<data xmlns="urn:ietf:params:xml:ns:netconf:base:1.0">
+			<x:types xmlns:x="urn:v" xmlns:y="urn:e">
+				<x:i8> -3 </x:i8>
+				<x:str> ab </x:str>
+				<x:id>y:ext-id</x:id>
+				<x:flags> b  a </x:flags>
+				<x:iid>/x:item[x:name='a:b']</x:iid>
+				<y:extra>18446744073709551615</y:extra>
+				<x:ref>4</x:ref>
+			</x:types>
+		</data>`,
+		want: `{"val:types":{"ext:extra":"18446744073709551615","flags":"b a","i8":-3,"id":"ext:ext-id","iid":"/val:item[val:name='a:b']","ref":4,"str":" ab "}}`,
+	}, {
+		desc: "unprefixed identity in the default namespace",
+		in:   `<types xmlns="urn:v"><id>derived-id</id><either>5</either><present></present></types>`,
+		want: `{"val:types":{"either":5,"id":"val:derived-id","present":[null]}}`,
+	}, {
+		desc: "lists and leaf-lists",
+		in: `<item xmlns="urn:v"><name>a</name><value>1</value><tag>p</tag><tag>q</tag></item>
+			<item xmlns="urn:v"><name>b</name><value>2</value></item>`,
+		want: `{"val:item":[{"name":"a","tag":["p","q"],"value":1},{"name":"b","value":2}]}`,
+	}, {
+		desc:    "element in the wrong namespace",
+		in:      `<types xmlns="urn:v"><extra>1</extra></types>`,
+		wantErr: `/types: unknown element extra in namespace "urn:v"`,
+	}, {
+		desc:    "leaf specified twice",
+		in:      `<types xmlns="urn:v"><i8>1</i8><i8>2</i8></types>`,
+		wantErr: `/types: element i8 specified more than once`,
+	}, {
+		desc:    "invalid value",
+		in:      `<item xmlns="urn:v"><name>a</name><value>x</value></item>`,
+		wantErr: `/item[0]/value: x is not a valid uint32`,
+	}, {
+		desc:    "unbound prefix",
+		in:      `<types xmlns="urn:v"><id>z:derived-id</id></types>`,
+		wantErr: `/types/id: "z:derived-id": prefix z is not bound to a namespace`,
+	}, {
+		desc:    "content in an empty leaf",
+		in:      `<types xmlns="urn:v"><present>x</present></types>`,
+		wantErr: `/types/present: empty value must have no content, got "x"`,
+	}, {
+		desc:    "malformed XML",
+		in:      `<types xmlns="urn:v"><i8>1</types>`,
+		wantErr: `element <i8> closed by </types>`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := mod.DecodeXML(strings.NewReader(tt.in))
+			if diff := errdiff.Substring(err, tt.wantErr); diff != "" {
+				t.Fatalf("DecodeXML: %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("DecodeXML: got\n%s\nwant\n%s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeXMLEmpty(t *testing.T) {
+	mod := xmlTestModules(t)
+	for _, tt := range []struct {
+		desc string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, `<types xmlns="urn:v"><present/></types>`},
+		{"true", true, `<types xmlns="urn:v"><present/></types>`},
+		{"RFC7951 value", []interface{}{nil}, `<types xmlns="urn:v"><present/></types>`},
+		{"false", false, `<types xmlns="urn:v"></types>`},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := mod.EncodeXML(&b, map[string]interface{}{"types": map[string]interface{}{"present": tt.in}}); err != nil {
+				t.Fatalf("EncodeXML: %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("EncodeXML: got %s, want %s", got, tt.want)
+			}
+			got, err := mod.DecodeXML(&b)
+			if err != nil {
+				t.Fatalf("DecodeXML: %v", err)
+			}
+			types, _ := got["val:types"].(map[string]interface{})
+			_, present := types["present"]
+			if wantPresent := tt.in != false; present != wantPresent {
+				t.Errorf("DecodeXML: got present %v, want %v", present, wantPresent)
+			}
+		})
+	}
+}
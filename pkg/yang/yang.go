@@ -159,6 +159,40 @@ func (s *Module) Current() string {
 	return rev
 }
 
+// A RevisionInfo summarizes a revision statement of a module.
+type RevisionInfo struct {
+	Date        string // the revision date, YYYY-MM-DD
+	Description string // the description, "" if none
+	Reference   string // the reference, "" if none
+}
+
+// Revisions returns a summary of each revision statement of this module in
+// the order they appear in the source, which is by convention most recent
+// first.
+func (s *Module) Revisions() []*RevisionInfo {
+	var revs []*RevisionInfo
+	for _, r := range s.Revision {
+		revs = append(revs, &RevisionInfo{
+			Date:        r.Name,
+			Description: r.Description.asString(),
+			Reference:   r.Reference.asString(),
+		})
+	}
+	return revs
+}
+
+// RevisionBefore returns the most recent revision of this module that is
+// older than date, or nil if there is no such revision.
+func (s *Module) RevisionBefore(date string) *Revision {
+	var rev *Revision
+	for _, r := range s.Revision {
+		if r.Name < date && (rev == nil || r.Name > rev.Name) {
+			rev = r
+		}
+	}
+	return rev
+}
+
 // FullName returns the full name of the module including the most recent
 // revision, if any.
 func (s *Module) FullName() string {
@@ -179,6 +213,37 @@ func (s *Module) GetPrefix() string {
 	return pfx.Name
 }
 
+// PrefixMap returns the modules that the prefixes usable within s refer to:
+// the prefix of s itself and those of the modules it imports.  The prefix of
+// a submodule refers to the module it belongs to, or to the submodule if
+// that module has not been read.  Imports of modules that have not been read
+// are omitted.  PrefixMap should be called after Process, which reads the
+// imported modules.
+func (s *Module) PrefixMap() map[string]*Module {
+	m := map[string]*Module{}
+	if pfx := s.GetPrefix(); pfx != "" {
+		m[pfx] = s
+		if s.Kind() == "submodule" && s.Modules != nil {
+			if bm := s.Modules.Modules[s.BelongsTo.Name]; bm != nil {
+				m[pfx] = bm
+			}
+		}
+	}
+	for _, i := range s.Import {
+		if i.Prefix == nil {
+			continue
+		}
+		if im := i.Module; im != nil {
+			m[i.Prefix.Name] = im
+		} else if s.Modules != nil {
+			if im := s.Modules.FindModule(i); im != nil {
+				m[i.Prefix.Name] = im
+			}
+		}
+	}
+	return m
+}
+
 // getPrefix returns the local prefix of the module used to refer to itself.
 func (s *Module) getPrefix() *Value {
 	switch {
@@ -224,6 +289,8 @@ type Include struct {
 	Extensions []*Statement `yang:"Ext" json:",omitempty"`
 
 	RevisionDate *Value `yang:"revision-date"`
+	Reference    *Value `yang:"reference,nomerge" json:",omitempty"`
+	Description  *Value `yang:"description,nomerge" json:",omitempty"`
 
 	// Module is the included module.  The types and groupings are
 	// available to the importer with the defined prefix.
@@ -837,6 +904,19 @@ func (s *Identity) modulePrefixedName() string {
 	return fmt.Sprintf("%s:%s", module(s).Name, s.Name)
 }
 
+// ValueDescriptions returns a map of the module-qualified names (i.e.,
+// "module:identity") of the identities derived from s to their descriptions.
+// Identities without a description are not included.
+func (s *Identity) ValueDescriptions() map[string]string {
+	m := map[string]string{}
+	for _, v := range s.Values {
+		if v.Description != nil {
+			m[v.modulePrefixedName()] = v.Description.Name
+		}
+	}
+	return m
+}
+
 // IsDefined behaves the same as the implementation for Enum - it returns
 // true if an identity with the name is defined within the Values of the
 // identity
@@ -944,6 +1024,10 @@ func (s *Deviation) Statement() *Statement { return s.Source }
 func (s *Deviation) Exts() []*Statement    { return s.Extensions }
 
 // A Deviate is defined in: http://tools.ietf.org/html/rfc6020#section-7.18.3.2
+//
+// Default is a slice, rather than the *Value of earlier versions of this
+// package, as a deviate of a leaf-list may have several default statements
+// (RFC7950 section 7.20.3.2).
 type Deviate struct {
 	Name       string       `yang:"Name,nomerge"`
 	Source     *Statement   `yang:"Statement,nomerge"`
@@ -951,7 +1035,7 @@ type Deviate struct {
 	Extensions []*Statement `yang:"Ext"`
 
 	Config      *Value   `yang:"config"`
-	Default     *Value   `yang:"default"`
+	Default     []*Value `yang:"default"`
 	Mandatory   *Value   `yang:"mandatory"`
 	MaxElements *Value   `yang:"max-elements"`
 	MinElements *Value   `yang:"min-elements"`
@@ -1046,6 +1130,7 @@ func (s *Length) Statement() *Statement { return s.Source }
 func (s *Length) Exts() []*Statement    { return s.Extensions }
 
 // A Pattern is defined in: http://tools.ietf.org/html/rfc6020#section-9.4.6
+// and, with the modifier statement, in https://tools.ietf.org/html/rfc7950#section-9.4.6
 type Pattern struct {
 	Name       string       `yang:"Name,nomerge"`
 	Source     *Statement   `yang:"Statement,nomerge"`
@@ -1055,6 +1140,7 @@ type Pattern struct {
 	Description  *Value `yang:"description"`
 	ErrorAppTag  *Value `yang:"error-app-tag"`
 	ErrorMessage *Value `yang:"error-message"`
+	Modifier     *Value `yang:"modifier"`
 	Reference    *Value `yang:"reference"`
 }
 
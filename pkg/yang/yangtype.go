@@ -16,6 +16,7 @@ package yang
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -177,6 +178,7 @@ func init() {
 // is analogous to reflect.Kind.
 type TypeKind uint
 
+// String returns the YANG name of k, e.g., "int32" for Yint32.
 func (k TypeKind) String() string {
 	if s := TypeKindToName[k]; s != "" {
 		return s
@@ -184,6 +186,17 @@ func (k TypeKind) String() string {
 	return fmt.Sprintf("unknown-type-%d", k)
 }
 
+// ParseTypeKind returns the TypeKind of the YANG built-in type named s, e.g.,
+// Ydecimal64 for "decimal64".  It is the inverse of TypeKind.String.  false is
+// returned if s is not the name of a built-in type.
+func ParseTypeKind(s string) (TypeKind, bool) {
+	k, ok := TypeKindFromName[s]
+	if !ok || k == Ynone {
+		return Ynone, false
+	}
+	return k, true
+}
+
 const (
 	// Ynone represents the invalid (unset) type.
 	Ynone = TypeKind(iota)
@@ -233,23 +246,23 @@ const (
 // all fields in YangType are used for all types.
 type YangType struct {
 	Name             string
-	Kind             TypeKind    // Ynone if not a base type
-	Base             *Type       `json:"-"`          // Base type for non-builtin types
-	IdentityBase     *Identity   `json:",omitempty"` // Base statement for a type using identityref
-	Root             *YangType   `json:"-"`          // root of this type that is the same
-	Bit              *EnumType   `json:",omitempty"` // bit position, "status" is lost
-	Enum             *EnumType   `json:",omitempty"` // enum name to value, "status" is lost
-	Units            string      `json:",omitempty"` // units to be used for this type
-	Default          string      `json:",omitempty"` // default value, if any
-	HasDefault       bool        `json:",omitempty"` // whether the type has a default.
-	FractionDigits   int         `json:",omitempty"` // decimal64 fixed point precision
-	Length           YangRange   `json:",omitempty"` // this should be processed by section 12
-	OptionalInstance bool        `json:",omitempty"` // !require-instances which defaults to true
-	Path             string      `json:",omitempty"` // the path in a leafref
-	Pattern          []string    `json:",omitempty"` // limiting XSD-TYPES expressions on strings
-	POSIXPattern     []string    `json:",omitempty"` // limiting POSIX ERE on strings (specified by openconfig-extensions:posix-pattern)
-	Range            YangRange   `json:",omitempty"` // range for integers
-	Type             []*YangType `json:",omitempty"` // for unions
+	Kind             TypeKind       // Ynone if not a base type
+	Base             *Type          `json:"-"`          // Base type for non-builtin types
+	IdentityBase     *Identity      `json:",omitempty"` // Base statement for a type using identityref
+	Root             *YangType      `json:"-"`          // root of this type that is the same
+	Bit              *EnumType      `json:",omitempty"` // bit position, "status" is lost
+	Enum             *EnumType      `json:",omitempty"` // enum name to value, "status" is lost
+	Units            string         `json:",omitempty"` // units to be used for this type
+	Default          string         `json:",omitempty"` // default value, if any
+	HasDefault       bool           `json:",omitempty"` // whether the type has a default.
+	FractionDigits   int            `json:",omitempty"` // decimal64 fixed point precision
+	Length           YangRange      `json:",omitempty"` // this should be processed by section 12
+	OptionalInstance bool           `json:",omitempty"` // !require-instances which defaults to true
+	Path             string         `json:",omitempty"` // the path in a leafref
+	Pattern          []*YangPattern `json:",omitempty"` // limiting XSD-TYPES expressions on strings
+	POSIXPattern     []string       `json:",omitempty"` // limiting POSIX ERE on strings (specified by openconfig-extensions:posix-pattern)
+	Range            YangRange      `json:",omitempty"` // range for integers
+	Type             []*YangType    `json:",omitempty"` // for unions
 }
 
 // Equal returns true if y and t describe the same type.
@@ -271,7 +284,7 @@ func (y *YangType) Equal(t *YangType) bool {
 		!y.Length.Equal(t.Length),
 		y.OptionalInstance != t.OptionalInstance,
 		y.Path != t.Path,
-		!ssEqual(y.Pattern, t.Pattern),
+		!psEqual(y.Pattern, t.Pattern),
 		!ssEqual(y.POSIXPattern, t.POSIXPattern),
 		len(y.Range) != len(t.Range),
 		!y.Range.Equal(t.Range),
@@ -286,6 +299,48 @@ func (y *YangType) Equal(t *YangType) bool {
 	return true
 }
 
+// RootKind returns the built-in kind at the bottom of the typedef chain of y,
+// e.g., Yint32 for a typedef of a typedef of int32.  Unions return Yunion
+// rather than the kinds of their members.  Ynone is returned if y is nil or
+// has not been resolved to a built-in type.
+func (y *YangType) RootKind() TypeKind {
+	for t := y; t != nil; {
+		if t.Kind != Ynone {
+			return t.Kind
+		}
+		if t.Base == nil {
+			break
+		}
+		t = t.Base.YangType
+	}
+	return Ynone
+}
+
+// DisplayName returns the name by which y is best shown to a user.  If y is
+// a typedef, or is the type of a node that uses a typedef without
+// restricting it, the name of the typedef is returned.  If the node adds
+// restrictions of its own, such as a range or pattern, the built-in name of
+// y is returned with a note of the typedef that it restricts, e.g.,
+// "uint32 (restriction of percent)".  Otherwise the built-in name of y is
+// returned.  An empty string is returned if y is nil.
+func (y *YangType) DisplayName() string {
+	if y == nil {
+		return ""
+	}
+	if y.Base != nil {
+		if td, ok := y.Base.Parent.(*Typedef); ok && td.YangType != nil {
+			if y.Equal(td.YangType) {
+				return td.Name
+			}
+			return fmt.Sprintf("%s (restriction of %s)", y.Kind, td.Name)
+		}
+	}
+	if y.Kind == Ynone {
+		return y.Name
+	}
+	return y.Kind.String()
+}
+
 // typedef returns a Typedef created from y for insertion into the BaseTypedefs
 // map.
 func (y *YangType) typedef() *Typedef {
@@ -301,6 +356,44 @@ func (y *YangType) typedef() *Typedef {
 	}
 }
 
+// A YangPattern is a pattern statement restricting the values of a string
+// type, as described in RFC7950 section 9.4.5.
+type YangPattern struct {
+	Expr         string // the XSD-TYPES regular expression
+	InvertMatch  bool   `json:",omitempty"` // values must not match Expr
+	ErrorMessage string `json:",omitempty"` // the error-message to report if a value does not conform, if any
+	ErrorAppTag  string `json:",omitempty"` // the error-app-tag to report if a value does not conform, if any
+	Description  string `json:",omitempty"` // the description of the pattern, if any
+}
+
+// Matches reports whether s conforms to p, i.e., whether s matches Expr, or
+// does not if InvertMatch is set.  Expr is implicitly anchored at both ends
+// and is compiled as a Go regular expression, whose syntax covers most, but
+// not all, XSD-TYPES expressions.  An error is returned if Expr cannot be
+// compiled.
+func (p *YangPattern) Matches(s string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + p.Expr + ")$")
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s) != p.InvertMatch, nil
+}
+
+// psEqual returns true if the two pattern slices restrict values in the same
+// way.  The descriptions are not compared.
+func psEqual(p1, p2 []*YangPattern) bool {
+	if len(p1) != len(p2) {
+		return false
+	}
+	for x, p := range p1 {
+		q := p2[x]
+		if p.Expr != q.Expr || p.InvertMatch != q.InvertMatch || p.ErrorMessage != q.ErrorMessage || p.ErrorAppTag != q.ErrorAppTag {
+			return false
+		}
+	}
+	return true
+}
+
 // ssEqual returns true if the two slices are equivalent.
 func ssEqual(s1, s2 []string) bool {
 	if len(s1) != len(s2) {
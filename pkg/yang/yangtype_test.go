@@ -134,3 +134,212 @@ func TestYangTypeEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeKindString(t *testing.T) {
+	tests := []struct {
+		in   TypeKind
+		want string
+	}{
+		{Yint8, "int8"},
+		{Yint16, "int16"},
+		{Yint32, "int32"},
+		{Yint64, "int64"},
+		{Yuint8, "uint8"},
+		{Yuint16, "uint16"},
+		{Yuint32, "uint32"},
+		{Yuint64, "uint64"},
+		{Ybinary, "binary"},
+		{Ybits, "bits"},
+		{Ybool, "boolean"},
+		{Ydecimal64, "decimal64"},
+		{Yempty, "empty"},
+		{Yenum, "enumeration"},
+		{Yidentityref, "identityref"},
+		{YinstanceIdentifier, "instance-identifier"},
+		{Yleafref, "leafref"},
+		{Ystring, "string"},
+		{Yunion, "union"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.in.String(); got != tt.want {
+				t.Errorf("String() got %q, want %q", got, tt.want)
+			}
+			got, ok := ParseTypeKind(tt.want)
+			if !ok {
+				t.Fatalf("ParseTypeKind(%q) returned not ok", tt.want)
+			}
+			if got != tt.in {
+				t.Errorf("ParseTypeKind(%q) got %v, want %v", tt.want, got, tt.in)
+			}
+		})
+	}
+
+	if len(tests) != len(TypeKindToName)-1 {
+		t.Errorf("test does not cover all built-in types, got %d, want %d", len(tests), len(TypeKindToName)-1)
+	}
+
+	for _, s := range []string{"", "none", "int128", "Int32"} {
+		if k, ok := ParseTypeKind(s); ok {
+			t.Errorf("ParseTypeKind(%q) got (%v, true), want (Ynone, false)", s, k)
+		}
+	}
+	if got, want := TypeKind(1000).String(), "unknown-type-1000"; got != want {
+		t.Errorf("String() of unknown type got %q, want %q", got, want)
+	}
+}
+
+func TestYangTypeRootKind(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			typedef base-int { type int32 { range "0..100"; } }
+			typedef percent { type base-int { range "0..10"; } }
+			typedef either {
+				type union {
+					type percent;
+					type string;
+				}
+			}
+
+			leaf builtin { type string; }
+			leaf one { type base-int; }
+			leaf two { type percent; }
+			leaf union { type either; }
+			leaf ref { type leafref { path "../two"; } }
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	dev := ToEntry(ms.Modules["dev"])
+
+	tests := []struct {
+		leaf string
+		want TypeKind
+	}{
+		{"builtin", Ystring},
+		{"one", Yint32},
+		{"two", Yint32},
+		{"union", Yunion},
+		{"ref", Yleafref},
+	}
+	for _, tt := range tests {
+		if got := dev.Dir[tt.leaf].Type.RootKind(); got != tt.want {
+			t.Errorf("%s: RootKind() got %v, want %v", tt.leaf, got, tt.want)
+		}
+	}
+
+	unresolved := &YangType{Name: "unknown", Base: &Type{Name: "unknown"}}
+	if got := unresolved.RootKind(); got != Ynone {
+		t.Errorf("unresolved type: RootKind() got %v, want %v", got, Ynone)
+	}
+	var nilType *YangType
+	if got := nilType.RootKind(); got != Ynone {
+		t.Errorf("nil type: RootKind() got %v, want %v", got, Ynone)
+	}
+}
+
+func TestYangTypeDisplayName(t *testing.T) {
+	ms := NewModules()
+	if err := ms.Parse(`
+		module dev {
+			prefix "d";
+			namespace "urn:d";
+
+			typedef percent { type int32 { range "0..100"; } }
+			typedef small { type percent { range "0..10"; } }
+			typedef name { type string; }
+
+			leaf builtin { type string; }
+			leaf restricted-builtin { type string { length "1..5"; } }
+			leaf plain { type percent; }
+			leaf prefixed { type d:name; }
+			leaf derived { type small; }
+			leaf restricted { type percent { range "10..20"; } }
+			leaf patterned { type name { pattern "[a-z]*"; } }
+			leaf enum { type enumeration { enum a; } }
+			leaf union { type union { type percent; type string; } }
+		}`, "dev.yang"); err != nil {
+		t.Fatalf("cannot parse module: %v", err)
+	}
+	if errs := ms.Process(); len(errs) != 0 {
+		t.Fatalf("cannot process modules: %v", errs)
+	}
+	dev := ToEntry(ms.Modules["dev"])
+
+	for _, tt := range []struct {
+		leaf string
+		want string
+	}{
+		{"builtin", "string"},
+		{"restricted-builtin", "string"},
+		{"plain", "percent"},
+		{"prefixed", "name"},
+		{"derived", "small"},
+		{"restricted", "int32 (restriction of percent)"},
+		{"patterned", "string (restriction of name)"},
+		{"enum", "enumeration"},
+		{"union", "union"},
+	} {
+		if got := dev.Dir[tt.leaf].Type.DisplayName(); got != tt.want {
+			t.Errorf("%s: DisplayName() got %q, want %q", tt.leaf, got, tt.want)
+		}
+	}
+	if got := dev.Dir["union"].Type.Type[0].DisplayName(); got != "percent" {
+		t.Errorf("union member: DisplayName() got %q, want %q", got, "percent")
+	}
+	var nilType *YangType
+	if got := nilType.DisplayName(); got != "" {
+		t.Errorf("nil type: DisplayName() got %q, want \"\"", got)
+	}
+}
+
+func TestYangPatternMatches(t *testing.T) {
+	tests := []struct {
+		desc    string
+		in      *YangPattern
+		s       string
+		want    bool
+		wantErr bool
+	}{{
+		desc: "match",
+		in:   &YangPattern{Expr: "[a-z]+"},
+		s:    "abc",
+		want: true,
+	}, {
+		desc: "anchored",
+		in:   &YangPattern{Expr: "[a-z]+"},
+		s:    "abc1",
+	}, {
+		desc: "inverted match",
+		in:   &YangPattern{Expr: "x.*", InvertMatch: true},
+		s:    "xyz",
+	}, {
+		desc: "inverted mismatch",
+		in:   &YangPattern{Expr: "x.*", InvertMatch: true},
+		s:    "abc",
+		want: true,
+	}, {
+		desc:    "bad expression",
+		in:      &YangPattern{Expr: "[a-"},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.in.Matches(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Matches(%q): got error %v, want error %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Matches(%q): got %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
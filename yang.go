@@ -28,187 +28,35 @@
 //
 // If DIR is specified, it is considered a comma separated list of paths
 // to append to the search directory.  If DIR appears as DIR/... then
-// DIR and all direct and indirect subdirectories are checked.
+// DIR and all direct and indirect subdirectories are checked.  A DIR that is
+// an http:// or https:// URL is searched by fetching files from below it; see
+// the --url-cache and --url-timeout flags.
 //
 // FORMAT, which defaults to "tree", specifies the format of output to produce.
-// Use "goyang --help" for a list of available formats.
+// Use "goyang --help" for a list of available formats.  The "none" format
+// produces no output and is useful for validating the input: all errors are
+// displayed and the exit status is non-zero if any were found.  With
+// --continue-on-error the modules without errors are displayed even if
+// other modules have errors, and the exit status is still non-zero.
 //
 // FORMAT OPTIONS are flags that apply to a specific format.  They must follow
 // --format.
 //
+// Formats are registered with the github.com/openconfig/goyang/pkg/format
+// package.  The command itself is implemented by the
+// github.com/openconfig/goyang/pkg/goyang package, so a program that imports
+// the packages registering additional formats and calls goyang.Run has those
+// formats available as well as the built-in ones.
+//
 // THIS PROGRAM IS STILL JUST A DEVELOPMENT TOOL.
 package main
 
 import (
-	"fmt"
-	"io"
-	"io/ioutil"
 	"os"
-	"runtime/trace"
-	"sort"
-	"strings"
 
-	"github.com/openconfig/goyang/pkg/indent"
-	"github.com/openconfig/goyang/pkg/yang"
-	"github.com/pborman/getopt"
+	"github.com/openconfig/goyang/pkg/goyang"
 )
 
-// Each format must register a formatter with register.  The function f will
-// be called once with the set of yang Entry trees generated.
-type formatter struct {
-	name  string
-	f     func(io.Writer, []*yang.Entry)
-	help  string
-	flags *getopt.Set
-}
-
-var formatters = map[string]*formatter{}
-
-func register(f *formatter) {
-	formatters[f.name] = f
-}
-
-// exitIfError writes errs to standard error and exits with an exit status of 1.
-// If errs is empty then exitIfError does nothing and simply returns.
-func exitIfError(errs []error) {
-	if len(errs) > 0 {
-		for _, err := range errs {
-			fmt.Fprintln(os.Stderr, err)
-		}
-		stop(1)
-	}
-}
-
-var stop = os.Exit
-
 func main() {
-	var format string
-	formats := make([]string, 0, len(formatters))
-	for k := range formatters {
-		formats = append(formats, k)
-	}
-	sort.Strings(formats)
-
-	var traceP string
-	var help bool
-	var paths []string
-	var ignoreSubmoduleCircularDependencies bool
-	getopt.ListVarLong(&paths, "path", 'p', "comma separated list of directories to add to search path", "DIR[,DIR...]")
-	getopt.StringVarLong(&format, "format", 'f', "format to display: "+strings.Join(formats, ", "), "FORMAT")
-	getopt.StringVarLong(&traceP, "trace", 't', "write trace into to TRACEFILE", "TRACEFILE")
-	getopt.BoolVarLong(&help, "help", 'h', "display help")
-	getopt.BoolVarLong(&ignoreSubmoduleCircularDependencies, "ignore-circdep", 'g', "ignore circular dependencies between submodules")
-	getopt.SetParameters("[FORMAT OPTIONS] [SOURCE] [...]")
-
-	if err := getopt.Getopt(func(o getopt.Option) bool {
-		if o.Name() == "--format" {
-			f, ok := formatters[format]
-			if !ok {
-				fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", format, strings.Join(formats, ", "))
-				stop(1)
-			}
-			if f.flags != nil {
-				f.flags.VisitAll(func(o getopt.Option) {
-					getopt.AddOption(o)
-				})
-			}
-		}
-		return true
-	}); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		getopt.PrintUsage(os.Stderr)
-		os.Exit(1)
-	}
-
-	if traceP != "" {
-		fp, err := os.Create(traceP)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-		trace.Start(fp)
-		stop = func(c int) { trace.Stop(); os.Exit(c) }
-		defer func() { trace.Stop() }()
-	}
-
-	if help {
-		getopt.CommandLine.PrintUsage(os.Stderr)
-		fmt.Fprintf(os.Stderr, `
-SOURCE may be a module name or a .yang file.
-
-Formats:
-`)
-		for _, fn := range formats {
-			f := formatters[fn]
-			fmt.Fprintf(os.Stderr, "    %s - %s\n", f.name, f.help)
-			if f.flags != nil {
-				f.flags.PrintOptions(indent.NewWriter(os.Stderr, "   "))
-			}
-			fmt.Fprintln(os.Stderr)
-		}
-		stop(0)
-	}
-
-	ms := yang.NewModules()
-	ms.ParseOptions.IgnoreSubmoduleCircularDependencies = ignoreSubmoduleCircularDependencies
-
-	for _, path := range paths {
-		expanded, err := yang.PathsWithModules(path)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			continue
-		}
-		ms.AddPath(expanded...)
-	}
-
-	if format == "" {
-		format = "tree"
-	}
-	if _, ok := formatters[format]; !ok {
-		fmt.Fprintf(os.Stderr, "%s: invalid format.  Choices are %s\n", format, strings.Join(formats, ", "))
-		stop(1)
-
-	}
-
-	files := getopt.Args()
-
-	if len(files) == 0 {
-		data, err := ioutil.ReadAll(os.Stdin)
-		if err == nil {
-			err = ms.Parse(string(data), "<STDIN>")
-		}
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			stop(1)
-		}
-	}
-
-	for _, name := range files {
-		if err := ms.Read(name); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			continue
-		}
-	}
-
-	// Process the read files, exiting if any errors were found.
-	exitIfError(ms.Process())
-
-	// Keep track of the top level modules we read in.
-	// Those are the only modules we want to print below.
-	mods := map[string]*yang.Module{}
-	var names []string
-
-	for _, m := range ms.Modules {
-		if mods[m.Name] == nil {
-			mods[m.Name] = m
-			names = append(names, m.Name)
-		}
-	}
-	sort.Strings(names)
-	entries := make([]*yang.Entry, len(names))
-	for x, n := range names {
-		entries[x] = yang.ToEntry(mods[n])
-	}
-
-	formatters[format].f(os.Stdout, entries)
+	goyang.Run(os.Args)
 }